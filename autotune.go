@@ -0,0 +1,148 @@
+package refyne
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultConcurrency is returned by Client.Concurrency when auto-tuning is
+// off or the account's limits are unknown.
+const DefaultConcurrency = 4
+
+// maxAutoConcurrency caps Client.Concurrency on plans without limits.
+const maxAutoConcurrency = 32
+
+// minTuneScale is the lowest fraction of the plan's limits auto-tuning
+// backs off to.
+const minTuneScale = 1.0 / 16
+
+// WithAutoTune paces requests to the account's rate limit and sizes
+// Client.Concurrency from its plan limits, read with GetLimits on first
+// use. Both are halved whenever the API responds with 429 Too Many
+// Requests and recover gradually as requests succeed, so new accounts need
+// no manual tuning.
+func WithAutoTune(enabled bool) ClientOption {
+	return func(c *Client) {
+		c.tuner = nil
+		if enabled {
+			c.tuner = &autoTuner{scale: 1, limiter: newRateLimiter(0, 1)}
+		}
+	}
+}
+
+// Concurrency returns how many calls to run in parallel, e.g. the number
+// of workers for a batch of extractions. With WithAutoTune it follows the
+// plan's burst and rate limits and adapts to rate limiting; otherwise it
+// returns DefaultConcurrency.
+func (c *Client) Concurrency(ctx context.Context) int {
+	if c.tuner == nil {
+		return DefaultConcurrency
+	}
+	c.tuner.load(ctx, c)
+	return c.tuner.concurrency()
+}
+
+// autoTuner adapts the request rate and concurrency to the account's
+// limits with a rate limiter whose rate is scaled down on 429s.
+type autoTuner struct {
+	limiter *rateLimiter
+
+	mu      sync.Mutex
+	limits  *AccountLimits
+	loading bool
+	retryAt time.Time
+	// scale is the fraction of the plan's limits currently used.
+	scale float64
+}
+
+// load fetches the account limits unless they are known, being fetched by
+// another call, or failed to load within the last minute.
+func (t *autoTuner) load(ctx context.Context, c *Client) {
+	t.mu.Lock()
+	if t.limits != nil || t.loading || time.Now().Before(t.retryAt) {
+		t.mu.Unlock()
+		return
+	}
+	t.loading = true
+	t.mu.Unlock()
+
+	limits, err := c.GetLimits(limitsContext(ctx))
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.loading = false
+	if err != nil {
+		t.retryAt = time.Now().Add(time.Minute)
+		c.logger.Warn("Failed to load account limits for auto-tuning", map[string]any{"error": err.Error()})
+		return
+	}
+	t.limits = limits
+	t.limiter.setRate(t.rate(), int(t.limits.BurstRequests))
+	t.limiter.fill()
+}
+
+// limitsContext returns a context for loading limits or capabilities in
+// the middle of another call. It keeps ctx's deadline and cancellation but
+// not the call's headers, request ID or options, and exempts the load from
+// tuning.
+func limitsContext(ctx context.Context) context.Context {
+	ctx = context.WithValue(ctx, headersContextKey, http.Header(nil))
+	ctx = ContextWithRequestID(ctx, "")
+	return context.WithValue(ctx, requestOptionsContextKey, requestOptions{untuned: true})
+}
+
+// wait blocks until the rate limit allows another request.
+func (t *autoTuner) wait(ctx context.Context, c *Client) error {
+	if requestOptionsFromContext(ctx).untuned {
+		return nil
+	}
+	t.load(ctx, c)
+	return t.limiter.wait(ctx, c.sleepWithContext)
+}
+
+// observe adapts the scale to a response: halving it on rate limiting and
+// recovering slowly on success.
+func (t *autoTuner) observe(resp *http.Response) {
+	t.limiter.observe(resp.Header)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		t.scale = math.Max(minTuneScale, t.scale/2)
+		t.limiter.drain()
+	case resp.StatusCode < 400:
+		t.scale = math.Min(1, t.scale+0.02)
+	default:
+		return
+	}
+	if t.limits != nil {
+		t.limiter.setRate(t.rate(), int(t.limits.BurstRequests))
+	}
+}
+
+// rate returns the current requests per second, or zero for no limit.
+func (t *autoTuner) rate() float64 {
+	if t.limits == nil || t.limits.RequestsPerMinute <= 0 {
+		return 0
+	}
+	return float64(t.limits.RequestsPerMinute) / 60 * t.scale
+}
+
+func (t *autoTuner) concurrency() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.limits == nil {
+		return DefaultConcurrency
+	}
+	limit := float64(maxAutoConcurrency)
+	switch {
+	case t.limits.BurstRequests > 0:
+		limit = float64(t.limits.BurstRequests)
+	case t.limits.RequestsPerMinute > 0:
+		limit = math.Ceil(float64(t.limits.RequestsPerMinute) / 60)
+	}
+	return max(1, int(math.Min(limit, maxAutoConcurrency)*t.scale))
+}
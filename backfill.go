@@ -0,0 +1,396 @@
+package refyne
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backfillListPageSize is the number of jobs fetched per page when a
+// backfill selects jobs by site.
+const backfillListPageSize = 100
+
+// BackfillInput selects previously crawled pages to re-extract and the
+// schema to extract them with. Pages are selected from URLs, from JobIDs
+// and, if Site is set, from the site's jobs created between Since and
+// Until; a page selected more than once is extracted once.
+type BackfillInput struct {
+	// URLs are page URLs to re-extract. They are not tied to a job's
+	// stored content, so they are fetched again.
+	URLs []string
+	// JobIDs are earlier jobs whose completed pages are re-extracted from
+	// the content stored when they were crawled, which requires the jobs
+	// to have been started with CrawlInput.IncludeContent. Pages without
+	// stored content fail.
+	JobIDs []string
+	// Site selects the completed jobs whose URL is on this host or one of
+	// its subdomains, e.g. "shop.example". A zero Since or Until leaves
+	// that end of the date range open.
+	Site  string
+	Since time.Time
+	Until time.Time
+
+	Schema    any
+	LLMConfig *LLMConfigInput
+	// Version labels the records produced, e.g. the schema version, so
+	// they can be stored as a new version of the dataset.
+	Version string
+
+	// Concurrency is the number of extractions run in parallel. Zero uses
+	// Client.Concurrency.
+	Concurrency int
+	// BudgetUsd stops starting extractions once the cost so far, plus the
+	// average cost so far reserved for each extraction in flight and the
+	// next one, would take the total over it. Until the first cost is
+	// known extractions run one at a time. Zero means no budget.
+	BudgetUsd float64
+
+	// OnRecord is called with each record as it is extracted, one at a
+	// time. If it returns an error the backfill stops with that error.
+	OnRecord func(BackfillRecord) error
+
+	// State makes the backfill resumable. The selected pages and the
+	// progress through them are saved under StateKey as the backfill runs,
+	// so running it again with the same key after a crash, an error or the
+	// budget running out carries on with the remaining pages instead of
+	// starting over. A page finished just before a crash may be extracted
+	// again. The state is deleted once every page is done.
+	State    StateStore
+	StateKey string
+}
+
+// BackfillRecord is the re-extraction of one page.
+type BackfillRecord struct {
+	Version string
+	URL     string
+	// SourceJobID is the job the page was selected from, if any.
+	SourceJobID string
+	// Output is the extraction, or nil if it failed with Err.
+	Output *ExtractOutput
+	Err    error
+}
+
+// BackfillResult summarizes a backfill.
+type BackfillResult struct {
+	Version string
+	// Pages is the number of pages selected, of which Extracted were
+	// extracted, Failed could not be extracted and Skipped were not
+	// attempted because the backfill stopped early. A resumed backfill
+	// counts the pages and usage of earlier runs too.
+	Pages     int
+	Extracted int
+	Failed    int
+	Skipped   int
+	// Resumed is the number of pages done by earlier runs.
+	Resumed int
+	// BudgetExceeded reports whether the backfill stopped at BudgetUsd.
+	BudgetExceeded bool
+	Usage          UsageTotals
+}
+
+// backfillPage is a page selected for a backfill.
+type backfillPage struct {
+	URL   string `json:"url"`
+	JobID string `json:"job_id,omitempty"`
+	// PageID identifies the page's stored content within JobID.
+	PageID string `json:"page_id,omitempty"`
+}
+
+// backfillState is the progress of a backfill saved to BackfillInput.State.
+type backfillState struct {
+	Pages []backfillPage `json:"pages"`
+	// Done holds the URLs of the pages already extracted or failed.
+	Done      map[string]bool `json:"done"`
+	Extracted int             `json:"extracted"`
+	Failed    int             `json:"failed"`
+	Usage     UsageTotals     `json:"usage"`
+}
+
+// Backfill re-extracts previously crawled pages with a new schema, the
+// usual way to rebuild a dataset after its schema changes. Pages selected
+// from jobs are extracted from the content stored when they were crawled,
+// so the dataset is rebuilt from the same pages rather than their current
+// versions. Pages that fail to extract are recorded and counted but do
+// not stop the backfill; the result is returned along with any error that
+// does.
+func (c *Client) Backfill(ctx context.Context, input BackfillInput, reqOpts ...RequestOption) (*BackfillResult, error) {
+	state, err := c.backfillState(ctx, input, reqOpts)
+	if err != nil {
+		return nil, err
+	}
+	var pages []backfillPage
+	for _, page := range state.Pages {
+		if _, done := state.Done[page.URL]; !done {
+			pages = append(pages, page)
+		}
+	}
+	result := &BackfillResult{
+		Version:   input.Version,
+		Pages:     len(state.Pages),
+		Extracted: state.Extracted,
+		Failed:    state.Failed,
+		Resumed:   len(state.Done),
+	}
+	workers := input.Concurrency
+	if workers <= 0 {
+		workers = c.Concurrency(ctx)
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	var (
+		mu       sync.Mutex
+		usage    UsageAccumulator
+		next     int
+		inFlight int
+	)
+	// changed is signalled whenever an extraction finishes, so workers
+	// waiting for the budget check can retry it.
+	changed := sync.NewCond(&mu)
+	usage.add("", state.Usage)
+	take := func() (backfillPage, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		for {
+			if next == len(pages) || ctx.Err() != nil || result.BudgetExceeded {
+				return backfillPage{}, false
+			}
+			if input.BudgetUsd > 0 && !backfillAffordable(input.BudgetUsd, usage.Total().CostUsd, result.Extracted, inFlight) {
+				if inFlight > 0 {
+					// Wait for the extractions in flight to settle the cost.
+					changed.Wait()
+					continue
+				}
+				result.BudgetExceeded = true
+				return backfillPage{}, false
+			}
+			inFlight++
+			next++
+			return pages[next-1], true
+		}
+	}
+	// finish records the outcome of an extraction taken with take, or
+	// releases it if record is nil because the backfill was stopped.
+	finish := func(record *BackfillRecord) {
+		mu.Lock()
+		defer mu.Unlock()
+		inFlight--
+		changed.Broadcast()
+		if record == nil {
+			return
+		}
+		if record.Err != nil {
+			result.Failed++
+		} else {
+			result.Extracted++
+			usage.AddExtract(record.Output)
+		}
+		if input.State != nil {
+			state.Done[record.URL] = true
+			state.Extracted, state.Failed, state.Usage = result.Extracted, result.Failed, usage.Total()
+			if err := saveState(input.State, input.StateKey, state); err != nil {
+				cancel(fmt.Errorf("backfill: save state: %w", err))
+				return
+			}
+		}
+		if input.OnRecord != nil && ctx.Err() == nil {
+			if err := input.OnRecord(*record); err != nil {
+				cancel(err)
+			}
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < min(workers, len(pages)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				page, ok := take()
+				if !ok {
+					return
+				}
+				out, err := c.backfillExtract(ctx, input, page, reqOpts)
+				if err != nil && ctx.Err() != nil {
+					finish(nil)
+					return
+				}
+				if err != nil {
+					c.logger.Warn("Backfill extraction failed", map[string]any{"url": page.URL, "error": err.Error()})
+				}
+				finish(&BackfillRecord{Version: input.Version, URL: page.URL, SourceJobID: page.JobID, Output: out, Err: err})
+			}
+		}()
+	}
+	wg.Wait()
+
+	result.Usage = usage.Total()
+	result.Skipped = result.Pages - result.Extracted - result.Failed
+	if err := context.Cause(ctx); err != nil {
+		return result, err
+	}
+	if input.State != nil && result.Skipped == 0 {
+		if err := input.State.Delete(input.StateKey); err != nil {
+			return result, fmt.Errorf("backfill: delete state: %w", err)
+		}
+	}
+	return result, nil
+}
+
+// backfillAffordable reports whether another extraction can start within
+// budget, given the cost spent so far on extracted pages and the number
+// of extractions in flight. Each extraction in flight and the next one
+// are reserved the average cost so far; with no cost known yet, one
+// extraction runs at a time.
+func backfillAffordable(budget, spent float64, extracted, inFlight int) bool {
+	if extracted == 0 {
+		return inFlight == 0
+	}
+	average := spent / float64(extracted)
+	return spent+average*float64(inFlight+1) <= budget
+}
+
+// backfillContentExtract is an extract request for content stored by an
+// earlier crawl rather than fetched from the URL.
+type backfillContentExtract struct {
+	ExtractInput
+	Content       string        `json:"content"`
+	ContentFormat ContentFormat `json:"content_format,omitempty"`
+}
+
+// backfillExtract extracts page with input's schema, from its stored
+// content if it was selected from a job.
+func (c *Client) backfillExtract(ctx context.Context, input BackfillInput, page backfillPage, reqOpts []RequestOption) (*ExtractOutput, error) {
+	extract := ExtractInput{URL: page.URL, Schema: input.Schema, LLMConfig: input.LLMConfig}
+	if page.PageID == "" {
+		return c.Extract(ctx, extract, reqOpts...)
+	}
+
+	content, err := c.Jobs.GetPageContent(ctx, page.JobID, page.PageID, "", reqOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("stored content of %s: %w", page.URL, err)
+	}
+	ctx = c.withIdempotencyKey(withRequestOptions(ctx, reqOpts))
+	var result ExtractOutput
+	body := backfillContentExtract{ExtractInput: extract, Content: content.Content, ContentFormat: content.Format}
+	if err := c.request(ctx, http.MethodPost, "/api/v1/extract", body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// backfillState returns the saved state of a resumed backfill, or a new
+// state with the pages selected by input, saved if input.State is set.
+func (c *Client) backfillState(ctx context.Context, input BackfillInput, reqOpts []RequestOption) (*backfillState, error) {
+	state := &backfillState{Done: map[string]bool{}}
+	if input.State != nil {
+		if input.StateKey == "" {
+			return nil, errors.New("backfill: State requires a StateKey")
+		}
+		resumed, err := loadState(input.State, input.StateKey, state)
+		if err != nil {
+			return nil, fmt.Errorf("backfill: %w", err)
+		}
+		if resumed {
+			if state.Done == nil {
+				state.Done = map[string]bool{}
+			}
+			return state, nil
+		}
+	}
+
+	pages, err := c.backfillPages(ctx, input, reqOpts)
+	if err != nil {
+		return nil, err
+	}
+	state.Pages = pages
+	if input.State != nil {
+		if err := saveState(input.State, input.StateKey, state); err != nil {
+			return nil, fmt.Errorf("backfill: save state: %w", err)
+		}
+	}
+	return state, nil
+}
+
+// backfillPages returns the pages selected by input, without duplicates.
+func (c *Client) backfillPages(ctx context.Context, input BackfillInput, reqOpts []RequestOption) ([]backfillPage, error) {
+	seen := map[string]bool{}
+	var pages []backfillPage
+	add := func(page backfillPage) {
+		if !seen[page.URL] {
+			seen[page.URL] = true
+			pages = append(pages, page)
+		}
+	}
+
+	jobIDs := append([]string(nil), input.JobIDs...)
+	if input.Site != "" {
+		ids, err := c.siteJobs(ctx, input, reqOpts)
+		if err != nil {
+			return nil, err
+		}
+		jobIDs = append(jobIDs, ids...)
+	}
+	for _, id := range jobIDs {
+		crawlMap, err := c.Jobs.GetCrawlMap(ctx, id, reqOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("backfill: pages of job %s: %w", id, err)
+		}
+		for _, entry := range crawlMap.Entries {
+			if entry.Status == "completed" {
+				add(backfillPage{URL: entry.Url, JobID: id, PageID: entry.Id})
+			}
+		}
+	}
+	// Pages of jobs are added first, so a URL also selected from a job is
+	// extracted from its stored content.
+	for _, u := range input.URLs {
+		add(backfillPage{URL: u})
+	}
+	return pages, nil
+}
+
+// siteJobs returns the IDs of the completed jobs on input.Site created
+// within input's date range.
+func (c *Client) siteJobs(ctx context.Context, input BackfillInput, reqOpts []RequestOption) ([]string, error) {
+	site := strings.ToLower(input.Site)
+	var ids []string
+	for offset := 0; ; offset += backfillListPageSize {
+		out, err := c.Jobs.List(ctx, &ListOptions{Limit: backfillListPageSize, Offset: offset}, reqOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("backfill: list jobs: %w", err)
+		}
+		if out.Jobs == nil {
+			return ids, nil
+		}
+		for _, job := range *out.Jobs {
+			if job.Status != "completed" || !onSite(job.Url, site) {
+				continue
+			}
+			created, err := time.Parse(time.RFC3339, job.CreatedAt)
+			if err != nil || (!input.Since.IsZero() && created.Before(input.Since)) || (!input.Until.IsZero() && !created.Before(input.Until)) {
+				continue
+			}
+			ids = append(ids, job.Id)
+		}
+		if len(*out.Jobs) < backfillListPageSize {
+			return ids, nil
+		}
+	}
+}
+
+// onSite reports whether rawURL is on host site or one of its subdomains.
+func onSite(rawURL, site string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	return host == site || strings.HasSuffix(host, "."+site)
+}
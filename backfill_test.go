@@ -0,0 +1,224 @@
+package refyne
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// backfillServer serves a backfill's API calls, recording each extracted
+// URL, suffixed with " (stored)" when it was extracted from stored content.
+func backfillServer(t *testing.T, extracted *[]string) *httptest.Server {
+	var mu sync.Mutex
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/jobs":
+			_ = json.NewEncoder(w).Encode(map[string]any{"jobs": []map[string]any{
+				{"id": "old", "status": "completed", "url": "https://shop.example/", "created_at": "2026-01-01T00:00:00Z"},
+				{"id": "job-1", "status": "completed", "url": "https://www.shop.example/", "created_at": "2026-03-01T00:00:00Z"},
+				{"id": "failed", "status": "failed", "url": "https://shop.example/", "created_at": "2026-03-02T00:00:00Z"},
+				{"id": "other", "status": "completed", "url": "https://other.example/", "created_at": "2026-03-03T00:00:00Z"},
+			}})
+		case "/api/v1/jobs/job-1/crawl-map":
+			_ = json.NewEncoder(w).Encode(map[string]any{"entries": []map[string]any{
+				{"id": "p-a", "url": "https://shop.example/a", "status": "completed"},
+				{"id": "p-b", "url": "https://shop.example/b", "status": "completed"},
+				{"id": "p-broken", "url": "https://shop.example/broken", "status": "failed"},
+			}})
+		case "/api/v1/jobs/job-1/pages/p-a/content", "/api/v1/jobs/job-1/pages/p-b/content":
+			_ = json.NewEncoder(w).Encode(map[string]any{"format": "html", "content": "<h1>stored</h1>"})
+		case "/api/v1/extract":
+			var input backfillContentExtract
+			_ = json.NewDecoder(r.Body).Decode(&input)
+			name := input.URL
+			if input.Content != "" {
+				if input.Content != "<h1>stored</h1>" || input.ContentFormat != ContentHTML {
+					t.Errorf("unexpected stored content %q (%s)", input.Content, input.ContentFormat)
+				}
+				name += " (stored)"
+			}
+			mu.Lock()
+			*extracted = append(*extracted, name)
+			mu.Unlock()
+			if input.URL == "https://shop.example/b" {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				_, _ = w.Write([]byte(`{"error":"page unreachable"}`))
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"url":   input.URL,
+				"data":  map[string]any{"title": "x"},
+				"usage": map[string]any{"input_tokens": 100, "output_tokens": 10, "cost_usd": 0.01},
+			})
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestBackfill(t *testing.T) {
+	var extracted []string
+	server := backfillServer(t, &extracted)
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL), WithMaxRetries(0), WithCacheEnabled(false))
+	var records []BackfillRecord
+	result, err := client.Backfill(context.Background(), BackfillInput{
+		URLs:    []string{"https://shop.example/a", "https://shop.example/c"},
+		Site:    "shop.example",
+		Since:   time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+		Schema:  map[string]any{"title": "string"},
+		Version: "v2",
+		OnRecord: func(r BackfillRecord) error {
+			records = append(records, r)
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sort.Strings(extracted)
+	want := []string{"https://shop.example/a (stored)", "https://shop.example/b (stored)", "https://shop.example/c"}
+	if len(extracted) != len(want) || extracted[0] != want[0] || extracted[1] != want[1] || extracted[2] != want[2] {
+		t.Errorf("expected %v extracted once each, got %v", want, extracted)
+	}
+	if result.Pages != 3 || result.Extracted != 2 || result.Failed != 1 || result.Skipped != 0 {
+		t.Errorf("unexpected counts: %+v", result)
+	}
+	if result.Usage.Requests != 2 || FormatUSD(result.Usage.CostUsd) != "$0.02" {
+		t.Errorf("unexpected usage: %+v", result.Usage)
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(records))
+	}
+	for _, r := range records {
+		if r.Version != "v2" {
+			t.Errorf("expected version v2, got %q", r.Version)
+		}
+		if r.URL == "https://shop.example/b" && (r.Err == nil || r.SourceJobID != "job-1") {
+			t.Errorf("expected a failed record from job-1, got %+v", r)
+		}
+	}
+}
+
+func TestBackfillBudget(t *testing.T) {
+	var extracted []string
+	server := backfillServer(t, &extracted)
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL), WithMaxRetries(0), WithCacheEnabled(false))
+	urls := []string{"https://shop.example/1", "https://shop.example/2", "https://shop.example/3", "https://shop.example/4"}
+	result, err := client.Backfill(context.Background(), BackfillInput{URLs: urls, Concurrency: 1, BudgetUsd: 0.025})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.BudgetExceeded || result.Extracted != 2 || result.Skipped != 2 {
+		t.Errorf("expected to stop after 2 pages, got %+v", result)
+	}
+}
+
+func TestBackfillBudgetReservesInFlight(t *testing.T) {
+	var (
+		mu                sync.Mutex
+		inFlight, maxSeen int
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		maxSeen = max(maxSeen, inFlight)
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"data":  map[string]any{"title": "x"},
+			"usage": map[string]any{"cost_usd": 0.01},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL), WithMaxRetries(0), WithCacheEnabled(false))
+	var urls []string
+	for i := 0; i < 20; i++ {
+		urls = append(urls, "https://shop.example/"+string(rune('a'+i)))
+	}
+	result, err := client.Backfill(context.Background(), BackfillInput{URLs: urls, Concurrency: 8, BudgetUsd: 0.035})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.BudgetExceeded || result.Extracted != 3 || result.Usage.CostUsd > 0.035 {
+		t.Errorf("expected to stop within budget after 3 pages, got %+v", result)
+	}
+	if maxSeen > 2 {
+		t.Errorf("expected in-flight extractions to be bounded by the budget, got %d at once", maxSeen)
+	}
+}
+
+func TestBackfillRecordError(t *testing.T) {
+	var extracted []string
+	server := backfillServer(t, &extracted)
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL), WithMaxRetries(0), WithCacheEnabled(false))
+	stop := errors.New("disk full")
+	result, err := client.Backfill(context.Background(), BackfillInput{
+		URLs:        []string{"https://shop.example/1", "https://shop.example/2"},
+		Concurrency: 1,
+		OnRecord:    func(BackfillRecord) error { return stop },
+	})
+	if !errors.Is(err, stop) {
+		t.Fatalf("expected the OnRecord error, got %v", err)
+	}
+	if result.Extracted != 1 || result.Skipped != 1 {
+		t.Errorf("expected to stop after 1 page, got %+v", result)
+	}
+}
+
+func TestBackfillResume(t *testing.T) {
+	var extracted []string
+	server := backfillServer(t, &extracted)
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL), WithMaxRetries(0), WithCacheEnabled(false))
+	store := NewMemoryStateStore()
+	input := BackfillInput{
+		URLs:        []string{"https://shop.example/1", "https://shop.example/2", "https://shop.example/3"},
+		Concurrency: 1,
+		State:       store,
+		StateKey:    "products-v2",
+	}
+
+	// The first run stops after one page, as if the worker crashed.
+	crash := errors.New("crash")
+	first := input
+	first.OnRecord = func(BackfillRecord) error { return crash }
+	if _, err := client.Backfill(context.Background(), first); !errors.Is(err, crash) {
+		t.Fatalf("expected the OnRecord error, got %v", err)
+	}
+
+	result, err := client.Backfill(context.Background(), input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(extracted) != 3 {
+		t.Errorf("expected each page to be extracted once, got %v", extracted)
+	}
+	if result.Pages != 3 || result.Resumed != 1 || result.Extracted != 3 || result.Skipped != 0 {
+		t.Errorf("unexpected counts: %+v", result)
+	}
+	if result.Usage.Requests != 3 {
+		t.Errorf("expected usage to include the first run, got %+v", result.Usage)
+	}
+	if _, saved, _ := store.Get("products-v2"); saved {
+		t.Error("expected the state to be deleted once every page was done")
+	}
+}
@@ -0,0 +1,652 @@
+package refyne
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCacheMaxEntries is the number of entries kept by the default cache.
+const DefaultCacheMaxEntries = 1000
+
+// CacheEntry is a cached API response.
+type CacheEntry struct {
+	// Status is the HTTP status of the cached response. Zero means 200.
+	Status    int
+	Body      []byte
+	Header    http.Header
+	StoredAt  time.Time
+	ExpiresAt time.Time
+	// StaleUntil is the end of the stale-while-revalidate window. Between
+	// ExpiresAt and StaleUntil the entry may be served while it is refreshed
+	// in the background.
+	StaleUntil time.Time
+	// StaleIfErrorUntil is the end of the stale-if-error window, until
+	// which the entry may be served if the API fails with a server or
+	// network error.
+	StaleIfErrorUntil time.Time
+	// RequestHeader holds the values, in the request the response was
+	// stored for, of the headers named by the response's Vary header. The
+	// entry is only served for requests with the same values.
+	RequestHeader http.Header
+}
+
+// Fresh reports whether the entry can be served without contacting the API.
+func (e *CacheEntry) Fresh(now time.Time) bool {
+	return now.Before(e.ExpiresAt)
+}
+
+// Servable reports whether the entry is fresh or within its stale window.
+func (e *CacheEntry) Servable(now time.Time) bool {
+	return e.Fresh(now) || now.Before(e.StaleUntil)
+}
+
+// Usable reports whether the entry may still be served, fresh, stale or in
+// place of an error. Caches may drop entries that are not.
+func (e *CacheEntry) Usable(now time.Time) bool {
+	return e.Servable(now) || now.Before(e.StaleIfErrorUntil)
+}
+
+// Cache stores API responses keyed by request.
+type Cache interface {
+	Get(key string) (*CacheEntry, bool)
+	Set(key string, entry *CacheEntry)
+	Delete(key string)
+}
+
+// CacheStats describe how effective a cache is.
+type CacheStats struct {
+	Hits   int64
+	Misses int64
+	// Evictions counts entries dropped to make room for others; expired
+	// entries are not counted.
+	Evictions int64
+	Entries   int
+	// SizeBytes is the approximate size of the cached entries.
+	SizeBytes int64
+}
+
+// StatsCache is a Cache that reports statistics. MemoryCache and DiskCache
+// implement it.
+type StatsCache interface {
+	Cache
+	Stats() CacheStats
+}
+
+// CacheStats returns the statistics of the client's cache, or false if it
+// has no cache or its cache does not report them.
+func (c *Client) CacheStats() (CacheStats, bool) {
+	cache, ok := c.cache.(StatsCache)
+	if !ok {
+		return CacheStats{}, false
+	}
+	return cache.Stats(), true
+}
+
+// cacheEntrySize approximates the memory used by entry.
+func cacheEntrySize(key string, entry *CacheEntry) int64 {
+	size := len(key) + len(entry.Body) + headerSize(entry.Header) + headerSize(entry.RequestHeader)
+	return int64(size)
+}
+
+func headerSize(h http.Header) int {
+	size := 0
+	for name, values := range h {
+		size += len(name)
+		for _, v := range values {
+			size += len(v)
+		}
+	}
+	return size
+}
+
+// MemoryCache is an in-memory Cache bounded by entry count and, if
+// SetMaxBytes is called, by size. When full, the least recently used
+// entries are evicted. Expired entries are dropped when looked up, or
+// periodically once StartSweeper is called.
+type MemoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	maxBytes   int64
+	entries    map[string]*list.Element
+	// lru holds the entries' *memoryCacheItem, most recently used first.
+	lru     *list.List
+	stats   CacheStats
+	onEvict func(key string)
+	sweep   chan struct{}
+}
+
+// memoryCacheItem is an entry in a MemoryCache's LRU list.
+type memoryCacheItem struct {
+	key   string
+	entry *CacheEntry
+	size  int64
+}
+
+// NewMemoryCache creates a MemoryCache holding at most maxEntries entries.
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	if maxEntries <= 0 {
+		maxEntries = DefaultCacheMaxEntries
+	}
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+// Get returns the entry for key if it is still usable.
+func (m *MemoryCache) Get(key string) (*CacheEntry, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	elem, ok := m.entries[key]
+	if !ok {
+		m.stats.Misses++
+		return nil, false
+	}
+	item := elem.Value.(*memoryCacheItem)
+	if !item.entry.Usable(time.Now()) {
+		m.remove(key)
+		m.stats.Misses++
+		return nil, false
+	}
+	m.lru.MoveToFront(elem)
+	m.stats.Hits++
+	return item.entry, true
+}
+
+// Set stores entry under key, evicting the least recently used entries if
+// the cache is full. An entry larger than the size limit is not stored.
+func (m *MemoryCache) Set(key string, entry *CacheEntry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	size := cacheEntrySize(key, entry)
+	if m.maxBytes > 0 && size > m.maxBytes {
+		m.remove(key)
+		return
+	}
+	if elem, ok := m.entries[key]; ok {
+		item := elem.Value.(*memoryCacheItem)
+		m.stats.SizeBytes += size - item.size
+		item.entry, item.size = entry, size
+		m.lru.MoveToFront(elem)
+	} else {
+		m.entries[key] = m.lru.PushFront(&memoryCacheItem{key: key, entry: entry, size: size})
+		m.stats.SizeBytes += size
+	}
+	m.evictOverflow()
+}
+
+// Delete removes the entry for key.
+func (m *MemoryCache) Delete(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.remove(key)
+}
+
+// SetMaxBytes bounds the approximate size of the cached entries, evicting
+// the least recently used entries if they already exceed it. Zero removes
+// the bound.
+func (m *MemoryCache) SetMaxBytes(maxBytes int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.maxBytes = max(maxBytes, 0)
+	m.evictOverflow()
+}
+
+// Sweep removes the entries that can no longer be served and returns how
+// many it removed. They are not counted as evictions.
+func (m *MemoryCache) Sweep() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	removed := 0
+	for elem := m.lru.Back(); elem != nil; {
+		prev := elem.Prev()
+		if item := elem.Value.(*memoryCacheItem); !item.entry.Usable(now) {
+			m.remove(item.key)
+			removed++
+		}
+		elem = prev
+	}
+	return removed
+}
+
+// StartSweeper calls Sweep every interval in the background until
+// StopSweeper is called, so expired entries that are never looked up again
+// do not hold memory. It does nothing if the sweeper is already running.
+func (m *MemoryCache) StartSweeper(interval time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.sweep != nil || interval <= 0 {
+		return
+	}
+	m.sweep = make(chan struct{})
+	go func(stop chan struct{}) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				m.Sweep()
+			}
+		}
+	}(m.sweep)
+}
+
+// StopSweeper stops the sweeper started by StartSweeper.
+func (m *MemoryCache) StopSweeper() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.sweep != nil {
+		close(m.sweep)
+		m.sweep = nil
+	}
+}
+
+// Stats returns the cache's statistics.
+func (m *MemoryCache) Stats() CacheStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stats := m.stats
+	stats.Entries = len(m.entries)
+	return stats
+}
+
+// OnEvict sets a function called with the key of each entry evicted to
+// make room, e.g. to count evictions in the application's metrics. It is
+// called with the cache locked and must not use the cache.
+func (m *MemoryCache) OnEvict(fn func(key string)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onEvict = fn
+}
+
+// evictOverflow evicts the least recently used entries until the cache
+// fits its limits. The caller must hold m.mu.
+func (m *MemoryCache) evictOverflow() {
+	for m.lru.Len() > 0 && (len(m.entries) > m.maxEntries || (m.maxBytes > 0 && m.stats.SizeBytes > m.maxBytes)) {
+		key := m.lru.Back().Value.(*memoryCacheItem).key
+		m.remove(key)
+		m.stats.Evictions++
+		if m.onEvict != nil {
+			m.onEvict(key)
+		}
+	}
+}
+
+// remove deletes key from the cache. The caller must hold m.mu.
+func (m *MemoryCache) remove(key string) {
+	elem, ok := m.entries[key]
+	if !ok {
+		return
+	}
+	m.stats.SizeBytes -= elem.Value.(*memoryCacheItem).size
+	m.lru.Remove(elem)
+	delete(m.entries, key)
+}
+
+// CacheDecision describes what the client did with the cache for a request.
+type CacheDecision string
+
+// Cache decisions reported to the logger and MetricsRecorder.
+const (
+	CacheHit        CacheDecision = "hit"
+	CacheMiss       CacheDecision = "miss"
+	CacheStale      CacheDecision = "stale"
+	CacheRevalidate CacheDecision = "revalidate"
+	CacheStore      CacheDecision = "store"
+	// CacheStaleIfError is a stale entry served because the API failed.
+	CacheStaleIfError CacheDecision = "stale_if_error"
+)
+
+// CacheEvent is a single cache decision.
+type CacheEvent struct {
+	Decision CacheDecision
+	Key      string
+	// TTL is the remaining freshness lifetime for hits and stores, and the
+	// remaining stale window for stale responses.
+	TTL time.Duration
+}
+
+// cacheControl holds the Cache-Control directives the client understands.
+type cacheControl struct {
+	noStore              bool
+	noCache              bool
+	maxAge               time.Duration
+	staleWhileRevalidate time.Duration
+	staleIfError         time.Duration
+}
+
+func parseCacheControl(header string) cacheControl {
+	var cc cacheControl
+	for _, directive := range strings.Split(header, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch strings.ToLower(name) {
+		case "no-store":
+			cc.noStore = true
+		case "no-cache":
+			cc.noCache = true
+		case "max-age":
+			cc.maxAge = parseDirectiveSeconds(value)
+		case "stale-while-revalidate":
+			cc.staleWhileRevalidate = parseDirectiveSeconds(value)
+		case "stale-if-error":
+			cc.staleIfError = parseDirectiveSeconds(value)
+		}
+	}
+	return cc
+}
+
+func parseDirectiveSeconds(value string) time.Duration {
+	seconds, err := strconv.Atoi(strings.Trim(value, `"`))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// CacheKeyFunc builds the cache key for a request. body is the JSON request
+// body (empty when there is none) and authHash identifies the credentials
+// the request is made with. Returning an empty string bypasses the cache.
+type CacheKeyFunc func(method, url, body, authHash string) string
+
+// DefaultCacheKey caches GET requests by method, URL and credentials.
+func DefaultCacheKey(method, url, body, authHash string) string {
+	if method != http.MethodGet {
+		return ""
+	}
+	return method + " " + url + " " + authHash
+}
+
+// cacheKey identifies a request in the cache, or returns "" if the request
+// must not be cached. The API key the request is sent with, from the
+// CredentialProvider if one is set, is hashed so that responses are never
+// shared between credentials, even by clients sharing a cache. If the key
+// cannot be fetched the request bypasses the cache. Requests carrying
+// context headers, such as a tenant ID, are cached under a key that also
+// identifies the headers.
+func (c *Client) cacheKey(ctx context.Context, method, url string, body []byte) string {
+	keyFunc := c.cacheKeyFunc
+	if keyFunc == nil {
+		keyFunc = DefaultCacheKey
+	}
+	token, err := c.token(ctx)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(token))
+	key := keyFunc(method, url, string(body), hex.EncodeToString(sum[:8]))
+	if headers := contextHeadersKey(ctx); key != "" && headers != "" {
+		key += cacheKeyHeadersSep + headers
+	}
+	return key
+}
+
+// cacheKeyHeadersSep separates a cache key from the context headers it was
+// made with.
+const cacheKeyHeadersSep = " headers:"
+
+// cacheVariants tracks, for each cache key made without context headers,
+// the keys of the responses cached for the same request with context
+// headers, so that invalidating a path drops every header variant of it.
+// It is shared by derived clients, like the cache.
+type cacheVariants struct {
+	mu   sync.Mutex
+	keys map[string]map[string]time.Time
+}
+
+func newCacheVariants() *cacheVariants {
+	return &cacheVariants{keys: map[string]map[string]time.Time{}}
+}
+
+// add records key, which is servable until until, if it was made with
+// context headers. Variants that are no longer servable are forgotten.
+func (v *cacheVariants) add(key string, until time.Time) {
+	i := strings.LastIndex(key, cacheKeyHeadersSep)
+	if i < 0 {
+		return
+	}
+	base := key[:i]
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	variants := v.keys[base]
+	if variants == nil {
+		variants = map[string]time.Time{}
+		v.keys[base] = variants
+	}
+	now := time.Now()
+	for k, u := range variants {
+		if !now.Before(u) {
+			delete(variants, k)
+		}
+	}
+	if until.After(variants[key]) {
+		variants[key] = until
+	}
+}
+
+// take returns and forgets the header variants recorded for the request
+// identified by key, with or without its context headers.
+func (v *cacheVariants) take(key string) []string {
+	if i := strings.LastIndex(key, cacheKeyHeadersSep); i >= 0 {
+		key = key[:i]
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	keys := []string{key}
+	for k := range v.keys[key] {
+		keys = append(keys, k)
+	}
+	delete(v.keys, key)
+	return keys
+}
+
+// deleteCached removes the cached GET response for path, for every set of
+// context headers it was cached with.
+func (c *Client) deleteCached(ctx context.Context, path string) {
+	key := c.cacheKey(ctx, http.MethodGet, c.snapshot().baseURL+c.apiPath(ctx, path), nil)
+	if key == "" {
+		return
+	}
+	for _, k := range c.cacheVariants.take(key) {
+		c.cache.Delete(k)
+	}
+}
+
+// cachedRequest serves a request from the cache when possible and stores
+// cacheable responses.
+func (c *Client) cachedRequest(ctx context.Context, key, method, path string, body any) (*response, error) {
+	now := time.Now()
+
+	// WithoutCache skips the lookup but still stores the fresh response.
+	skip := requestOptionsFromContext(ctx).skipCache
+	reqHeader := c.cacheRequestHeader(ctx)
+	entry, ok := c.cache.Get(key)
+	if ok && !varyMatches(entry, reqHeader) {
+		ok = false
+	}
+	if ok && !skip {
+		if entry.Fresh(now) || c.offline {
+			c.recordCacheDecision(CacheHit, key, entry.ExpiresAt.Sub(now))
+			if entry.Status >= 400 {
+				return nil, c.parseError(entry.Status, entry.Body)
+			}
+			return &response{status: http.StatusOK, header: entry.Header, body: entry.Body, cached: true}, nil
+		}
+		if entry.Servable(now) {
+			c.recordCacheDecision(CacheStale, key, entry.StaleUntil.Sub(now))
+			c.revalidate(ctx, key, method, path, body)
+			return &response{status: http.StatusOK, header: entry.Header, body: entry.Body, cached: true}, nil
+		}
+	}
+
+	c.recordCacheDecision(CacheMiss, key, 0)
+	resp, err := c.requestWithRetry(ctx, method, path, body, nil, 1)
+	if err != nil {
+		var notFound *NotFoundError
+		if errors.As(err, &notFound) {
+			c.storeNotFound(key, notFound)
+		}
+		if ok && !skip && entry.Status < 400 && time.Now().Before(entry.StaleIfErrorUntil) && isOutage(err) {
+			c.logger.Warn("Serving stale cached response after API error", map[string]any{
+				"key":   key,
+				"age":   time.Since(entry.StoredAt),
+				"error": err.Error(),
+			})
+			c.recordCacheDecision(CacheStaleIfError, key, time.Until(entry.StaleIfErrorUntil))
+			return &response{status: http.StatusOK, header: entry.Header, body: entry.Body, cached: true}, nil
+		}
+		return nil, err
+	}
+	c.storeResponse(key, resp, reqHeader)
+	return resp, nil
+}
+
+// isOutage reports whether err is a server error, a network error or an
+// open circuit breaker, the failures stale-if-error covers.
+func isOutage(err error) bool {
+	var netErr *NetworkError
+	var circuitErr *CircuitOpenError
+	var apiErr *APIError
+	return errors.As(err, &netErr) || errors.As(err, &circuitErr) || (errors.As(err, &apiErr) && apiErr.Status >= 500)
+}
+
+// storeNotFound caches a 404 for the negative cache TTL, if one is configured.
+func (c *Client) storeNotFound(key string, err *NotFoundError) {
+	if c.notFoundTTL <= 0 {
+		return
+	}
+	body, _ := json.Marshal(map[string]string{"error": err.Message})
+	now := time.Now()
+	c.cache.Set(key, &CacheEntry{
+		Status:     http.StatusNotFound,
+		Body:       body,
+		StoredAt:   now,
+		ExpiresAt:  now.Add(c.notFoundTTL),
+		StaleUntil: now.Add(c.notFoundTTL),
+	})
+	c.cacheVariants.add(key, now.Add(c.notFoundTTL))
+	c.recordCacheDecision(CacheStore, key, c.notFoundTTL)
+}
+
+// InvalidateCache removes the cached GET response for path, including a
+// cached 404, whatever context headers, such as a tenant ID, it was cached
+// with. Call it after re-creating a resource that was previously looked up
+// and not found, so the next lookup reaches the API.
+func (c *Client) InvalidateCache(path string) {
+	if c.cache == nil {
+		return
+	}
+	c.deleteCached(context.Background(), path)
+}
+
+// invalidatedResources are the collections whose cached responses are
+// dropped after a successful write to them.
+var invalidatedResources = []string{"/api/v1/schemas", "/api/v1/sites", "/api/v1/keys", "/api/v1/llm/keys"}
+
+// invalidateAfterWrite drops the cached GET responses that a successful
+// write to path makes stale: the collection's list and, for writes to an
+// item or its sub-resources, the item, so that e.g. Schemas.List right
+// after Schemas.Create includes the new schema. The responses are dropped
+// whatever context headers they were cached with, as a write's own
+// headers, such as If-Match, say nothing about which cached reads it
+// affects. Filtered lists, cached under their query string, are left to
+// expire.
+func (c *Client) invalidateAfterWrite(ctx context.Context, method, path string) {
+	if method == http.MethodGet || method == http.MethodHead || !c.cacheEnabled || c.cache == nil {
+		return
+	}
+	path, _, _ = strings.Cut(path, "?")
+	for _, root := range invalidatedResources {
+		rest, ok := strings.CutPrefix(path, root)
+		if !ok || (rest != "" && rest[0] != '/') {
+			continue
+		}
+		paths := []string{root}
+		if id, _, _ := strings.Cut(strings.TrimPrefix(rest, "/"), "/"); id != "" {
+			paths = append(paths, root+"/"+id)
+		}
+		for _, p := range paths {
+			c.deleteCached(ctx, p)
+		}
+		return
+	}
+}
+
+// revalidate refreshes key in the background. Only one refresh per key runs
+// at a time.
+func (c *Client) revalidate(ctx context.Context, key, method, path string, body any) {
+	if _, running := c.revalidating.LoadOrStore(key, struct{}{}); running {
+		return
+	}
+	c.recordCacheDecision(CacheRevalidate, key, 0)
+
+	ctx = context.WithoutCancel(ctx)
+	reqHeader := c.cacheRequestHeader(ctx)
+	go func() {
+		defer c.revalidating.Delete(key)
+		resp, err := c.requestWithRetry(ctx, method, path, body, nil, 1)
+		if err != nil {
+			c.logger.Warn("Cache revalidation failed", map[string]any{
+				"key":   key,
+				"error": err.Error(),
+			})
+			return
+		}
+		c.storeResponse(key, resp, reqHeader)
+	}()
+}
+
+// storeResponse caches resp if its Cache-Control and Vary headers allow it.
+// reqHeader is the request's headers, from cacheRequestHeader.
+func (c *Client) storeResponse(key string, resp *response, reqHeader http.Header) {
+	cc := parseCacheControl(resp.header.Get("Cache-Control"))
+	if cc.noStore || cc.noCache || cc.maxAge <= 0 {
+		return
+	}
+	vary, all := varyNames(resp.header)
+	if all {
+		return
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(cc.maxAge)
+	entry := &CacheEntry{
+		Body:              resp.body,
+		Header:            resp.header,
+		StoredAt:          now,
+		ExpiresAt:         expiresAt,
+		StaleUntil:        expiresAt.Add(cc.staleWhileRevalidate),
+		StaleIfErrorUntil: expiresAt.Add(cc.staleIfError),
+		RequestHeader:     varyRequestHeader(vary, reqHeader),
+	}
+	c.cache.Set(key, entry)
+	until := entry.StaleUntil
+	if entry.StaleIfErrorUntil.After(until) {
+		until = entry.StaleIfErrorUntil
+	}
+	c.cacheVariants.add(key, until)
+	c.recordCacheDecision(CacheStore, key, cc.maxAge)
+}
+
+// recordCacheDecision logs a cache decision and reports it to the metrics recorder.
+func (c *Client) recordCacheDecision(decision CacheDecision, key string, ttl time.Duration) {
+	c.logger.Debug("Cache "+string(decision), map[string]any{
+		"key": key,
+		"ttl": ttl,
+	})
+	c.metrics.RecordCacheEvent(CacheEvent{Decision: decision, Key: key, TTL: ttl})
+}
@@ -0,0 +1,648 @@
+package refyne
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingMetrics struct {
+	NoopMetricsRecorder
+	mu     sync.Mutex
+	events []CacheEvent
+}
+
+func (r *recordingMetrics) RecordCacheEvent(event CacheEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *recordingMetrics) decisions() []CacheDecision {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var decisions []CacheDecision
+	for _, e := range r.events {
+		decisions = append(decisions, e.Decision)
+	}
+	return decisions
+}
+
+func TestCacheHitAndMiss(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "max-age=60")
+		_ = json.NewEncoder(w).Encode(map[string]any{"status": "ok"})
+	}))
+	defer server.Close()
+
+	metrics := &recordingMetrics{}
+	client := NewClient("test-key", WithBaseURL(server.URL), WithMetricsRecorder(metrics))
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Health(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if requests != 1 {
+		t.Errorf("expected 1 request to reach the server, got %d", requests)
+	}
+
+	got := metrics.decisions()
+	want := []CacheDecision{CacheMiss, CacheStore, CacheHit}
+	if len(got) != len(want) {
+		t.Fatalf("expected decisions %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("decision %d: expected %s, got %s", i, want[i], got[i])
+		}
+	}
+	if metrics.events[1].TTL != 60*time.Second {
+		t.Errorf("expected store TTL 60s, got %v", metrics.events[1].TTL)
+	}
+}
+
+func TestCacheDisabled(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Cache-Control", "max-age=60")
+		_ = json.NewEncoder(w).Encode(map[string]any{"status": "ok"})
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL), WithCacheEnabled(false))
+	for i := 0; i < 2; i++ {
+		if _, err := client.Health(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if requests != 2 {
+		t.Errorf("expected 2 requests with caching disabled, got %d", requests)
+	}
+}
+
+func TestCacheStaleWhileRevalidate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		_ = json.NewEncoder(w).Encode(map[string]any{"status": "fresh"})
+	}))
+	defer server.Close()
+
+	metrics := &recordingMetrics{}
+	cache := NewMemoryCache(10)
+	client := NewClient("test-key", WithBaseURL(server.URL), WithCache(cache), WithMetricsRecorder(metrics))
+
+	now := time.Now()
+	key := client.cacheKey(context.Background(), http.MethodGet, server.URL+"/api/v1/health", nil)
+	cache.Set(key, &CacheEntry{
+		Body:       []byte(`{"status":"stale"}`),
+		StoredAt:   now.Add(-2 * time.Minute),
+		ExpiresAt:  now.Add(-time.Minute),
+		StaleUntil: now.Add(time.Minute),
+	})
+
+	result, err := client.Health(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Status != "stale" {
+		t.Errorf("expected stale response to be served, got %q", result.Status)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if entry, ok := cache.Get(key); ok && entry.Fresh(time.Now()) {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Errorf("expected entry to be revalidated, decisions: %v", metrics.decisions())
+}
+
+func TestCacheStaleIfError(t *testing.T) {
+	status := http.StatusServiceUnavailable
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"error":"down for maintenance"}`))
+	}))
+	defer server.Close()
+
+	metrics := &recordingMetrics{}
+	cache := NewMemoryCache(10)
+	client := NewClient("test-key", WithBaseURL(server.URL), WithCache(cache), WithMetricsRecorder(metrics), WithMaxRetries(0))
+
+	now := time.Now()
+	key := client.cacheKey(context.Background(), http.MethodGet, server.URL+"/api/v1/health", nil)
+	cache.Set(key, &CacheEntry{
+		Body:              []byte(`{"status":"stale"}`),
+		StoredAt:          now.Add(-2 * time.Minute),
+		ExpiresAt:         now.Add(-time.Minute),
+		StaleIfErrorUntil: now.Add(time.Minute),
+	})
+
+	result, err := client.Health(context.Background())
+	if err != nil {
+		t.Fatalf("expected the stale entry instead of an error, got %v", err)
+	}
+	if result.Status != "stale" {
+		t.Errorf("expected stale response to be served, got %q", result.Status)
+	}
+	if decisions := metrics.decisions(); decisions[len(decisions)-1] != CacheStaleIfError {
+		t.Errorf("expected a stale-if-error decision, got %v", decisions)
+	}
+
+	// Client errors are not masked.
+	status = http.StatusForbidden
+	if _, err := client.Health(context.Background()); err == nil {
+		t.Error("expected a 403 to be returned")
+	}
+
+	if cc := parseCacheControl("max-age=30, stale-if-error=600"); cc.staleIfError != 10*time.Minute {
+		t.Errorf("expected stale-if-error 10m, got %v", cc.staleIfError)
+	}
+}
+
+func TestCacheInvalidationOnWrite(t *testing.T) {
+	requests := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests[r.Method+" "+r.URL.Path]++
+		w.Header().Set("Cache-Control", "max-age=60")
+		switch r.URL.Path {
+		case "/api/v1/schemas":
+			_, _ = w.Write([]byte(`{"schemas":[]}`))
+		default:
+			_, _ = w.Write([]byte(`{"id":"s-1","name":"products"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL))
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if _, err := client.Schemas.List(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := client.Schemas.Get(ctx, "s-1"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if _, err := client.Schemas.Update(ctx, "s-1", CreateSchemaInput{Name: "products", SchemaYAML: "name: products\nfields:\n  - name: title\n    type: string\n"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := client.Schemas.List(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := client.Schemas.Get(ctx, "s-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if requests["GET /api/v1/schemas"] != 2 || requests["GET /api/v1/schemas/s-1"] != 2 {
+		t.Errorf("expected the list and item to be refetched after the update, got %v", requests)
+	}
+}
+
+func TestCacheInvalidationOnLLMKeyWrite(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		switch r.Method {
+		case http.MethodGet:
+			requests++
+			_, _ = w.Write([]byte(`{"keys":[]}`))
+		case http.MethodPut:
+			_, _ = w.Write([]byte(`{"id":"k-1","provider":"openai"}`))
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL))
+	ctx := context.Background()
+	list := func() {
+		t.Helper()
+		if _, err := client.LLM.ListKeys(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	list()
+	list()
+	if _, err := client.LLM.UpsertKey(ctx, UpsertKeyInput{Provider: "openai", APIKey: "sk-test"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	list()
+	if err := client.LLM.DeleteKey(ctx, "k-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	list()
+
+	if requests != 3 {
+		t.Errorf("expected the key list to be refetched after each write, got %d requests", requests)
+	}
+}
+
+func TestCacheInvalidationAcrossHeaders(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			requests++
+		}
+		w.Header().Set("Cache-Control", "max-age=60")
+		_, _ = w.Write([]byte(`{"id":"s-1","name":"products"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL))
+	ctx := ContextWithHeader(context.Background(), "X-Tenant", "acme")
+	get := func() {
+		t.Helper()
+		if _, err := client.Schemas.Get(ctx, "s-1"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// An update carrying If-Match drops the tenant's cached schema.
+	get()
+	input := CreateSchemaInput{Name: "products", SchemaYAML: "name: products\nfields:\n  - name: title\n    type: string\n", IfMatch: `"v1"`}
+	if _, err := client.Schemas.Update(ctx, "s-1", input, WithHeader("X-Trace", "t-1")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	get()
+	if requests != 2 {
+		t.Fatalf("expected the get after the update to reach the server, got %d requests", requests)
+	}
+
+	// So does InvalidateCache, which has no context headers.
+	client.InvalidateCache("/api/v1/schemas/s-1")
+	get()
+	if requests != 3 {
+		t.Errorf("expected the get after InvalidateCache to reach the server, got %d requests", requests)
+	}
+}
+
+func TestCacheVary(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Cache-Control", "max-age=60")
+		if r.URL.Path == "/api/v1/schemas/any" {
+			w.Header().Set("Vary", "*")
+		} else {
+			w.Header().Set("Vary", "User-Agent, Authorization")
+		}
+		_, _ = w.Write([]byte(`{"id":"s-1","name":"` + r.Header.Get("User-Agent") + `"}`))
+	}))
+	defer server.Close()
+
+	// Derived clients share the cache but send a different User-Agent.
+	client := NewClient("test-key", WithBaseURL(server.URL))
+	tool := client.With(WithUserAgentSuffix("tool/1"))
+	ctx := context.Background()
+	get := func(c *Client, id string) string {
+		t.Helper()
+		schema, err := c.Schemas.Get(ctx, id)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return schema.Name
+	}
+
+	if get(client, "s-1") != get(client, "s-1") || requests != 1 {
+		t.Fatalf("expected the second request to be served from the cache, got %d requests", requests)
+	}
+	if got := get(tool, "s-1"); !strings.Contains(got, "tool/1") || requests != 2 {
+		t.Errorf("expected a request with a different User-Agent to miss, got %q after %d requests", got, requests)
+	}
+	if got := get(tool, "s-1"); !strings.Contains(got, "tool/1") || requests != 2 {
+		t.Errorf("expected the new variant to be cached, got %q after %d requests", got, requests)
+	}
+
+	get(client, "any")
+	get(client, "any")
+	if requests != 4 {
+		t.Errorf("expected Vary: * responses not to be cached, got %d requests", requests)
+	}
+
+	entry, ok := client.cache.Get(client.cacheKey(ctx, http.MethodGet, server.URL+"/api/v1/schemas/s-1", nil))
+	if !ok || !strings.Contains(entry.RequestHeader.Get("User-Agent"), "tool/1") {
+		t.Fatalf("expected the entry to record the request's User-Agent, got %+v", entry)
+	}
+	if _, stored := entry.RequestHeader["Authorization"]; stored {
+		t.Error("expected Authorization not to be stored")
+	}
+}
+
+func TestCacheContextHeaders(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Cache-Control", "max-age=60")
+		_, _ = w.Write([]byte(`{"id":"s-1","name":"` + r.Header.Get("X-Tenant") + `"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL))
+	for _, tenant := range []string{"acme", "globex", "acme"} {
+		ctx := ContextWithHeader(context.Background(), "X-Tenant", tenant)
+		schema, err := client.Schemas.Get(ctx, "s-1", WithIdempotencyKey(tenant+"-call"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if schema.Name != tenant {
+			t.Errorf("expected the response for tenant %q, got %q", tenant, schema.Name)
+		}
+	}
+	if requests != 2 {
+		t.Errorf("expected one request per tenant, got %d", requests)
+	}
+}
+
+func TestMemoryCacheEviction(t *testing.T) {
+	cache := NewMemoryCache(2)
+	expires := time.Now().Add(time.Minute)
+
+	cache.Set("a", &CacheEntry{ExpiresAt: expires})
+	cache.Set("b", &CacheEntry{ExpiresAt: expires})
+	cache.Set("c", &CacheEntry{ExpiresAt: expires})
+
+	if _, ok := cache.Get("a"); ok {
+		t.Error("expected oldest entry to be evicted")
+	}
+	for _, key := range []string{"b", "c"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("expected entry %q to be cached", key)
+		}
+	}
+}
+
+func TestMemoryCacheLRU(t *testing.T) {
+	cache := NewMemoryCache(2)
+	expires := time.Now().Add(time.Minute)
+
+	cache.Set("a", &CacheEntry{ExpiresAt: expires})
+	cache.Set("b", &CacheEntry{ExpiresAt: expires})
+	cache.Get("a")
+	cache.Set("c", &CacheEntry{ExpiresAt: expires})
+
+	if _, ok := cache.Get("b"); ok {
+		t.Error("expected the least recently used entry to be evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("expected entry %q to be cached", key)
+		}
+	}
+}
+
+func TestMemoryCacheMaxBytes(t *testing.T) {
+	cache := NewMemoryCache(10)
+	expires := time.Now().Add(time.Minute)
+
+	cache.Set("a", &CacheEntry{Body: []byte("1234"), ExpiresAt: expires})
+	cache.Set("b", &CacheEntry{Body: []byte("1234"), ExpiresAt: expires})
+	cache.Set("c", &CacheEntry{Body: []byte("1234"), ExpiresAt: expires})
+	cache.SetMaxBytes(10)
+	if stats := cache.Stats(); stats.Entries != 2 || stats.SizeBytes != 10 || stats.Evictions != 1 {
+		t.Errorf("expected the oldest entry to be evicted to fit 10 bytes, got %+v", stats)
+	}
+
+	cache.Set("big", &CacheEntry{Body: []byte("1234567890"), ExpiresAt: expires})
+	if _, ok := cache.Get("big"); ok {
+		t.Error("expected an entry larger than the limit not to be stored")
+	}
+	if _, ok := cache.Get("c"); !ok {
+		t.Error("expected existing entries to be kept")
+	}
+}
+
+func TestMemoryCacheSweeper(t *testing.T) {
+	cache := NewMemoryCache(10)
+	cache.Set("expired", &CacheEntry{ExpiresAt: time.Now().Add(-time.Second)})
+	cache.Set("stale", &CacheEntry{ExpiresAt: time.Now().Add(-time.Second), StaleUntil: time.Now().Add(time.Minute)})
+	cache.Set("fresh", &CacheEntry{ExpiresAt: time.Now().Add(time.Minute)})
+
+	cache.StartSweeper(10 * time.Millisecond)
+	defer cache.StopSweeper()
+	deadline := time.Now().Add(time.Second)
+	for cache.Stats().Entries != 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if stats := cache.Stats(); stats.Entries != 2 || stats.Evictions != 0 {
+		t.Errorf("expected only the expired entry to be swept, got %+v", stats)
+	}
+}
+
+func TestCacheStats(t *testing.T) {
+	cache := NewMemoryCache(2)
+	var evicted []string
+	cache.OnEvict(func(key string) { evicted = append(evicted, key) })
+	client := NewClient("test-key", WithCache(cache))
+	expires := time.Now().Add(time.Minute)
+
+	cache.Set("a", &CacheEntry{Body: []byte("12345"), ExpiresAt: expires})
+	cache.Set("b", &CacheEntry{Body: []byte("123"), ExpiresAt: expires})
+	cache.Get("b")
+	cache.Get("missing")
+	cache.Set("c", &CacheEntry{Body: []byte("1"), ExpiresAt: expires})
+
+	stats, ok := client.CacheStats()
+	if !ok {
+		t.Fatal("expected the memory cache to report stats")
+	}
+	want := CacheStats{Hits: 1, Misses: 1, Evictions: 1, Entries: 2, SizeBytes: 6}
+	if stats != want {
+		t.Errorf("expected %+v, got %+v", want, stats)
+	}
+	if len(evicted) != 1 || evicted[0] != "a" {
+		t.Errorf("expected a to be reported evicted, got %v", evicted)
+	}
+
+	if _, ok := NewClient("test-key", WithCache(nil)).CacheStats(); ok {
+		t.Error("expected no stats without a cache")
+	}
+}
+
+func TestDiskCache(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewDiskCache(dir, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expires := time.Now().Add(time.Minute)
+	cache.Set("models", &CacheEntry{Body: []byte(`{"models":[]}`), Header: http.Header{"Etag": {`"v1"`}}, ExpiresAt: expires})
+	cache.Set("expired", &CacheEntry{Body: []byte(`{}`), ExpiresAt: time.Now().Add(-time.Second)})
+
+	// A new cache on the same directory, as in the next run of a CLI.
+	reopened, err := NewDiskCache(dir, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	entry, ok := reopened.Get("models")
+	if !ok || string(entry.Body) != `{"models":[]}` || entry.Header.Get("ETag") != `"v1"` || !entry.ExpiresAt.Equal(expires) {
+		t.Fatalf("expected the entry to persist, got %+v, %v", entry, ok)
+	}
+	if _, ok := reopened.Get("expired"); ok {
+		t.Error("expected an expired entry to miss")
+	}
+
+	// A corrupt file is a miss and is removed.
+	path := filepath.Join(dir, reopened.name("models"))
+	data, _ := os.ReadFile(path)
+	if err := os.WriteFile(path, append(data[:len(data)-3], 'x'), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := reopened.Get("models"); ok {
+		t.Error("expected a corrupt entry to miss")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected the corrupt entry to be removed, got %v", err)
+	}
+}
+
+func TestDiskCacheEviction(t *testing.T) {
+	dir := t.TempDir()
+	probe, _ := NewDiskCache(t.TempDir(), 0)
+	expires := time.Now().Add(time.Minute)
+	probe.Set("a", &CacheEntry{ExpiresAt: expires})
+
+	cache, err := NewDiskCache(dir, probe.size*2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cache.Set("a", &CacheEntry{ExpiresAt: expires})
+	cache.Set("b", &CacheEntry{ExpiresAt: expires})
+	cache.Get("a")
+	cache.Set("c", &CacheEntry{ExpiresAt: expires})
+
+	if _, ok := cache.Get("b"); ok {
+		t.Error("expected the least recently used entry to be evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("expected entry %q to be cached", key)
+		}
+	}
+	if stats := cache.Stats(); stats.Evictions != 1 || stats.Entries != 2 || stats.SizeBytes > probe.size*2 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestParseCacheControl(t *testing.T) {
+	cc := parseCacheControl("public, max-age=30, stale-while-revalidate=10")
+	if cc.maxAge != 30*time.Second {
+		t.Errorf("expected max-age 30s, got %v", cc.maxAge)
+	}
+	if cc.staleWhileRevalidate != 10*time.Second {
+		t.Errorf("expected stale-while-revalidate 10s, got %v", cc.staleWhileRevalidate)
+	}
+	if cc := parseCacheControl("no-store"); !cc.noStore {
+		t.Error("expected no-store to be parsed")
+	}
+}
+
+func TestCacheKeyFunc(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Cache-Control", "max-age=60")
+		_ = json.NewEncoder(w).Encode(map[string]any{"url": "https://example.com"})
+	}))
+	defer server.Close()
+
+	var keys []string
+	keyFunc := func(method, url, body, authHash string) string {
+		key := method + " " + url + " " + body + " " + authHash
+		keys = append(keys, key)
+		return key
+	}
+
+	client := NewClient("test-key", WithBaseURL(server.URL), WithCacheKeyFunc(keyFunc))
+	input := AnalyzeInput{URL: "https://example.com"}
+	for i := 0; i < 2; i++ {
+		if _, err := client.Analyze(context.Background(), input); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if requests != 1 {
+		t.Errorf("expected POST with a custom key to be cached, got %d requests", requests)
+	}
+	if len(keys) != 2 || !strings.Contains(keys[0], `"url":"https://example.com"`) {
+		t.Errorf("expected key func to receive the request body, got %v", keys)
+	}
+}
+
+func TestNotFoundCaching(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]any{"error": "job not found"})
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL), WithNotFoundCacheTTL(time.Minute))
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		_, err := client.Jobs.Get(ctx, "job-1")
+		if _, ok := err.(*NotFoundError); !ok {
+			t.Fatalf("expected NotFoundError, got %T: %v", err, err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("expected cached 404 to be served, got %d requests", requests)
+	}
+
+	client.InvalidateCache("/api/v1/jobs/job-1")
+	_, _ = client.Jobs.Get(ctx, "job-1")
+	if requests != 2 {
+		t.Errorf("expected invalidated 404 to reach the server, got %d requests", requests)
+	}
+}
+
+func TestOfflineMode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "max-age=1")
+		_ = json.NewEncoder(w).Encode(map[string]any{"status": "recorded"})
+	}))
+	cache := NewMemoryCache(10)
+	online := NewClient("test-key", WithBaseURL(server.URL), WithCache(cache))
+	if _, err := online.Health(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	server.Close()
+
+	offline := NewClient("test-key", WithBaseURL(server.URL), WithCache(cache), WithOfflineMode(true))
+	health, err := offline.Health(context.Background())
+	if err != nil {
+		t.Fatalf("expected a cached response, got %v", err)
+	}
+	if health.Status != "recorded" {
+		t.Errorf("expected recorded response, got %q", health.Status)
+	}
+
+	var offlineErr *OfflineError
+	if _, err := offline.Jobs.Get(context.Background(), "job-1"); !errors.As(err, &offlineErr) {
+		t.Errorf("expected OfflineError for an uncached GET, got %v", err)
+	}
+	if _, err := offline.Crawl(context.Background(), CrawlInput{URL: "https://example.com"}); !errors.As(err, &offlineErr) || offlineErr.Method != http.MethodPost {
+		t.Errorf("expected OfflineError for a mutation, got %v", err)
+	}
+}
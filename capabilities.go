@@ -0,0 +1,110 @@
+package refyne
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// capabilitiesTTL is how long capabilities are cached for feature checks.
+const capabilitiesTTL = 5 * time.Minute
+
+// Feature is an optional API feature that may be turned off for an
+// account or deployment.
+type Feature string
+
+// Optional API features.
+const (
+	// FeatureSSE is streaming job events with Jobs.Watch.
+	FeatureSSE         Feature = "sse"
+	FeatureScreenshots Feature = "screenshots"
+	FeatureDatasets    Feature = "datasets"
+	FeatureSearch      Feature = "search"
+)
+
+// Capabilities reports which optional API features are enabled.
+type Capabilities struct {
+	Features      map[Feature]bool `json:"features"`
+	UnknownFields `json:"-"`
+}
+
+// Enabled reports whether f is enabled. Features the API does not report
+// are assumed to be enabled.
+func (c *Capabilities) Enabled(f Feature) bool {
+	enabled, ok := c.Features[f]
+	return enabled || !ok
+}
+
+// GetCapabilities returns which optional API features are enabled for the
+// account and deployment.
+func (c *Client) GetCapabilities(ctx context.Context, reqOpts ...RequestOption) (*Capabilities, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
+	var result Capabilities
+	if err := c.request(ctx, http.MethodGet, "/api/v1/capabilities", nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// WithFeatureChecks makes helpers that depend on an optional feature, such
+// as Search and Jobs.Watch, check GetCapabilities first and return a
+// *FeatureUnavailableError when the feature is turned off, instead of
+// failing with a bare 404. Capabilities are loaded on first use and cached
+// for five minutes; if they cannot be loaded, calls go ahead unchecked.
+func WithFeatureChecks(enabled bool) ClientOption {
+	return func(c *Client) {
+		c.capabilities = nil
+		if enabled {
+			c.capabilities = &capabilityCache{}
+		}
+	}
+}
+
+// requireFeature returns a *FeatureUnavailableError if feature checks are
+// on and the API reports f as turned off.
+func (c *Client) requireFeature(ctx context.Context, f Feature) error {
+	if c.capabilities == nil {
+		return nil
+	}
+	caps := c.capabilities.get(ctx, c)
+	if caps != nil && !caps.Enabled(f) {
+		return &FeatureUnavailableError{Feature: f}
+	}
+	return nil
+}
+
+// capabilityCache holds the capabilities used for feature checks. Clients
+// derived with With share it.
+type capabilityCache struct {
+	mu      sync.Mutex
+	caps    *Capabilities
+	expires time.Time
+	loading bool
+}
+
+// get returns the cached capabilities, loading them if they have expired
+// and no other call is loading them. It returns nil if they are unknown.
+func (cc *capabilityCache) get(ctx context.Context, c *Client) *Capabilities {
+	cc.mu.Lock()
+	if cc.loading || time.Now().Before(cc.expires) {
+		defer cc.mu.Unlock()
+		return cc.caps
+	}
+	cc.loading = true
+	cc.mu.Unlock()
+
+	caps, err := c.GetCapabilities(limitsContext(ctx))
+
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	cc.loading = false
+	if err != nil {
+		cc.expires = time.Now().Add(time.Minute)
+		c.logger.Debug("Failed to load capabilities for feature checks", map[string]any{"error": err.Error()})
+		return cc.caps
+	}
+	cc.caps = caps
+	cc.expires = time.Now().Add(capabilitiesTTL)
+	return caps
+}
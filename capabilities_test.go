@@ -0,0 +1,67 @@
+package refyne
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestFeatureChecks(t *testing.T) {
+	var loads, searches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/capabilities":
+			loads.Add(1)
+			_, _ = w.Write([]byte(`{"features":{"search":false,"sse":true}}`))
+		case "/api/v1/search":
+			searches.Add(1)
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL), WithCacheEnabled(false), WithFeatureChecks(true))
+	caps, err := client.GetCapabilities(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if caps.Enabled(FeatureSearch) || !caps.Enabled(FeatureSSE) || !caps.Enabled(FeatureDatasets) {
+		t.Errorf("unexpected capabilities: %v", caps.Features)
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err = client.Search(context.Background(), SearchInput{Query: "laptops"})
+		var unavailable *FeatureUnavailableError
+		if !errors.As(err, &unavailable) || unavailable.Feature != FeatureSearch {
+			t.Fatalf("expected FeatureUnavailableError for search, got %v", err)
+		}
+	}
+	if searches.Load() != 0 {
+		t.Errorf("expected search not to be called, got %d calls", searches.Load())
+	}
+	if loads.Load() != 2 {
+		t.Errorf("expected GetCapabilities and one cached load for the checks, got %d loads", loads.Load())
+	}
+}
+
+func TestFeatureChecksUnavailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/capabilities" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"results":[]}`))
+	}))
+	defer server.Close()
+
+	// Deployments without the capabilities endpoint are not blocked.
+	client := NewClient("test-key", WithBaseURL(server.URL), WithCacheEnabled(false), WithMaxRetries(0), WithFeatureChecks(true))
+	if _, err := client.Search(context.Background(), SearchInput{Query: "laptops"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
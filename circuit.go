@@ -0,0 +1,84 @@
+package refyne
+
+import (
+	"sync"
+	"time"
+)
+
+// WithCircuitBreaker stops calling a degraded API. After threshold
+// consecutive network errors or 5xx responses the breaker opens and calls
+// fail immediately with a *CircuitOpenError instead of tying up workers on
+// timeouts. Once cooldown has passed a single trial request is let through;
+// if it succeeds the breaker closes, otherwise it stays open for another
+// cooldown. Clients derived with With share the breaker.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) ClientOption {
+	return func(c *Client) {
+		if threshold <= 0 {
+			c.breaker = nil
+			return
+		}
+		c.breaker = &circuitBreaker{threshold: threshold, cooldown: cooldown, now: time.Now}
+	}
+}
+
+// circuitBreaker counts consecutive failures and fails fast while open.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	failures int
+	open     bool
+	// openUntil is when the next trial request may be sent.
+	openUntil time.Time
+}
+
+// allow returns a *CircuitOpenError if a request may not be sent. While
+// open, the first caller after the cooldown is let through as the trial
+// and the cooldown restarts, so an abandoned trial does not keep the
+// breaker shut.
+func (b *circuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.open {
+		return nil
+	}
+	now := b.now()
+	if now.Before(b.openUntil) {
+		return &CircuitOpenError{Until: b.openUntil, Failures: b.failures}
+	}
+	b.openUntil = now.Add(b.cooldown)
+	return nil
+}
+
+// report records the outcome of a request and reports whether it opened
+// the breaker.
+func (b *circuitBreaker) report(ok bool) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if ok {
+		b.failures, b.open = 0, false
+		return false
+	}
+	b.failures++
+	if b.open {
+		b.openUntil = b.now().Add(b.cooldown)
+		return false
+	}
+	if b.failures >= b.threshold {
+		b.open = true
+		b.openUntil = b.now().Add(b.cooldown)
+		return true
+	}
+	return false
+}
+
+// reportCircuit records the outcome of a request with the circuit breaker.
+func (c *Client) reportCircuit(ok bool) {
+	if c.breaker != nil && c.breaker.report(ok) {
+		c.logger.Warn("Circuit breaker open, failing fast", map[string]any{
+			"cooldown": c.breaker.cooldown,
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package refyne
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var hits, failing atomic.Int32
+	failing.Store(1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if failing.Load() == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"ok"}`))
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	client := NewClient("test-key", WithBaseURL(server.URL), WithCacheEnabled(false), WithMaxRetries(0), WithCircuitBreaker(2, time.Minute))
+	client.breaker.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		var apiErr *APIError
+		if _, err := client.Health(context.Background()); !errors.As(err, &apiErr) {
+			t.Fatalf("expected APIError, got %v", err)
+		}
+	}
+	var open *CircuitOpenError
+	if _, err := client.Health(context.Background()); !errors.As(err, &open) || open.Failures != 2 {
+		t.Fatalf("expected CircuitOpenError, got %v", err)
+	}
+	if hits.Load() != 2 {
+		t.Errorf("expected the open breaker to fail fast, got %d requests", hits.Load())
+	}
+
+	// After the cooldown a failed trial reopens the breaker.
+	now = now.Add(time.Minute)
+	if _, err := client.Health(context.Background()); errors.As(err, &open) {
+		t.Fatalf("expected a trial request, got %v", err)
+	}
+	if _, err := client.Health(context.Background()); !errors.As(err, &open) {
+		t.Fatalf("expected the breaker to reopen, got %v", err)
+	}
+
+	// A successful trial closes it.
+	failing.Store(0)
+	now = now.Add(time.Minute)
+	for i := 0; i < 2; i++ {
+		if _, err := client.Health(context.Background()); err != nil {
+			t.Fatalf("expected the breaker to close, got %v", err)
+		}
+	}
+	if hits.Load() != 5 {
+		t.Errorf("expected 5 requests, got %d", hits.Load())
+	}
+}
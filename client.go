@@ -21,12 +21,15 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math/rand/v2"
+	"net"
 	"net/http"
-	"strconv"
+	"net/http/httptrace"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -36,24 +39,76 @@ const (
 	DefaultBaseURL    = "https://api.refyne.uk"
 	DefaultTimeout    = 30 * time.Second
 	DefaultMaxRetries = 3
+
+	// DefaultMaxResponseSize is the default limit on response body size.
+	DefaultMaxResponseSize = 512 << 20
 )
 
 // Client is the main Refyne SDK client.
 type Client struct {
-	apiKey     string
-	baseURL    string
-	httpClient *http.Client
-	timeout    time.Duration
-	maxRetries int
-	logger Logger
+	apiKey         Secret
+	baseURL        string
+	httpClient     *http.Client
+	timeout        time.Duration
+	overallTimeout time.Duration
+	maxRetries     int
+	retryBudget    time.Duration
+	retryable      func(status int, err error) bool
+	retryOverrides map[Operation]RetryConfig
+	maxBody        int64
+	compress       bool
+	compressMin    int
+	wireFormat     WireFormat
+	strictDecoding bool
+	crawlOpts      *CrawlOptions
+	userAgent      string
+	logger         Logger
+	mu             *sync.RWMutex
+	metrics        MetricsRecorder
+	retryHook      RetryHook
+	latency        *latencyEstimate
+	pathPrefix     string
+	capabilities   *capabilityCache
+
+	hostOverrides map[string]string
+	resolver      *net.Resolver
+	unixSocket    string
+	signer        RequestSigner
+	credentials   CredentialProvider
+	idempotency   bool
+	queue         QueueStore
+	redial        bool
+	ownTransport  bool
+	replayProtect bool
+	endpoints     *endpointPool
+	failover      failoverPolicy
+	region        Region
+	offline       bool
+	onDeprecation func(DeprecationNotice)
+	deprecations  *sync.Map
+	providerWarn  func(ProviderWarning)
+	tuner         *autoTuner
+	breaker       *circuitBreaker
+	limiter       *rateLimiter
+	slots         chan struct{}
+
+	cache         Cache
+	cacheEnabled  bool
+	cacheKeyFunc  CacheKeyFunc
+	notFoundTTL   time.Duration
+	revalidating  *sync.Map
+	cacheVariants *cacheVariants
 
 	// Sub-clients for organized API access
-	Jobs     *JobsClient
-	Schemas  *SchemasClient
-	Sites    *SitesClient
-	Keys     *KeysClient
-	LLM      *LLMClient
-	Webhooks *WebhooksClient
+	Jobs        *JobsClient
+	Schemas     *SchemasClient
+	Sites       *SitesClient
+	Schedules   *SchedulesClient
+	Keys        *KeysClient
+	LLM         *LLMClient
+	Webhooks    *WebhooksClient
+	Templates   *TemplatesClient
+	Collections *CollectionsClient
 }
 
 // ClientOption configures the client.
@@ -63,6 +118,7 @@ type ClientOption func(*Client)
 func WithBaseURL(url string) ClientOption {
 	return func(c *Client) {
 		c.baseURL = strings.TrimRight(url, "/")
+		c.endpoints = nil
 	}
 }
 
@@ -70,16 +126,36 @@ func WithBaseURL(url string) ClientOption {
 func WithHTTPClient(client *http.Client) ClientOption {
 	return func(c *Client) {
 		c.httpClient = client
+		c.ownTransport = false
+		c.redial = true
 	}
 }
 
-// WithTimeout sets the request timeout.
+// WithTimeout sets the per-attempt timeout. It is equivalent to
+// WithPerAttemptTimeout.
 func WithTimeout(timeout time.Duration) ClientOption {
+	return WithPerAttemptTimeout(timeout)
+}
+
+// WithPerAttemptTimeout bounds each HTTP attempt, so a hung attempt is
+// retried rather than consuming the whole call. It only applies when the
+// caller's context has no deadline; a caller deadline, shorter or longer,
+// always governs. Zero disables it.
+func WithPerAttemptTimeout(timeout time.Duration) ClientOption {
 	return func(c *Client) {
 		c.timeout = timeout
 	}
 }
 
+// WithOverallTimeout bounds a whole call, including retries and backoff.
+// Like the per-attempt timeout it only applies when the caller's context
+// has no deadline. It is disabled by default.
+func WithOverallTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.overallTimeout = timeout
+	}
+}
+
 // WithMaxRetries sets the maximum retry attempts.
 func WithMaxRetries(retries int) ClientOption {
 	return func(c *Client) {
@@ -87,6 +163,57 @@ func WithMaxRetries(retries int) ClientOption {
 	}
 }
 
+// WithMaxElapsedRetryTime bounds the wall-clock time a call spends on
+// attempts and backoff. Attempts are cut short and retries are abandoned
+// when they would exceed d, and the last error is returned wrapped in a
+// *RetryBudgetError. Unlike WithOverallTimeout it applies even when the
+// caller's context has a deadline. Zero disables it.
+func WithMaxElapsedRetryTime(d time.Duration) ClientOption {
+	return func(c *Client) {
+		c.retryBudget = d
+	}
+}
+
+// WithRetryClassifier retries errors the default policy treats as fatal.
+// After a 4xx response other than 429, retryable is called with the status
+// and the error the call would return, e.g. a *ConflictError for a transient
+// "fetcher busy", and the request is retried with backoff if it returns
+// true. Network errors, 429s and 5xx responses are always retried.
+func WithRetryClassifier(retryable func(status int, err error) bool) ClientOption {
+	return func(c *Client) {
+		c.retryable = retryable
+	}
+}
+
+// WithMaxResponseSize limits how many bytes of a response body are read
+// before the call fails with a *ResponseTooLargeError, protecting small
+// containers from huge merged results. A limit of zero or less disables the
+// check.
+func WithMaxResponseSize(n int64) ClientOption {
+	return func(c *Client) {
+		c.maxBody = n
+	}
+}
+
+// WithDefaultCrawlOptions sets the options used by Crawl when the input has
+// none.
+func WithDefaultCrawlOptions(opts *CrawlOptions) ClientOption {
+	return func(c *Client) {
+		c.crawlOpts = opts
+	}
+}
+
+// WithUserAgentSuffix appends suffix, e.g. "MyApp/1.0", to the SDK's
+// User-Agent header.
+func WithUserAgentSuffix(suffix string) ClientOption {
+	return func(c *Client) {
+		c.userAgent = fmt.Sprintf("refyne-go/%s", SDKVersion)
+		if suffix != "" {
+			c.userAgent += " " + suffix
+		}
+	}
+}
+
 // WithLogger sets a custom logger.
 func WithLogger(logger Logger) ClientOption {
 	return func(c *Client) {
@@ -94,43 +221,211 @@ func WithLogger(logger Logger) ClientOption {
 	}
 }
 
+// WithMetricsRecorder sets a recorder that receives client events.
+func WithMetricsRecorder(metrics MetricsRecorder) ClientOption {
+	return func(c *Client) {
+		c.metrics = metrics
+	}
+}
+
+// RequestSigner signs an outgoing request, e.g. for gateways that enforce
+// SigV4-style or HMAC signatures. It is called on every attempt, after all
+// headers are set; the body can be read from req.GetBody.
+type RequestSigner func(req *http.Request) error
+
+// WithRequestSigner sets a signer applied to every request.
+func WithRequestSigner(signer RequestSigner) ClientOption {
+	return func(c *Client) {
+		c.signer = signer
+	}
+}
+
+// WithCache sets the cache used for GET responses. The default is a
+// MemoryCache of DefaultCacheMaxEntries entries.
+func WithCache(cache Cache) ClientOption {
+	return func(c *Client) {
+		c.cache = cache
+	}
+}
+
+// WithCacheEnabled enables or disables response caching. Caching is
+// enabled by default, so GET responses are reused for as long as their
+// Cache-Control headers allow.
+func WithCacheEnabled(enabled bool) ClientOption {
+	return func(c *Client) {
+		c.cacheEnabled = enabled
+	}
+}
+
+// WithNotFoundCacheTTL caches 404 responses to GET requests for ttl, so
+// tight loops looking up deleted jobs or schemas don't hammer the API.
+// Negative caching is disabled by default. Use Client.InvalidateCache to
+// drop a cached 404 once the resource exists again.
+func WithNotFoundCacheTTL(ttl time.Duration) ClientOption {
+	return func(c *Client) {
+		c.notFoundTTL = ttl
+	}
+}
+
+// WithCacheKeyFunc sets how cache keys are built. Use it to partition the
+// cache by tenant, or to cache idempotent POST lookups by returning a key
+// derived from the request body.
+func WithCacheKeyFunc(fn CacheKeyFunc) ClientOption {
+	return func(c *Client) {
+		c.cacheKeyFunc = fn
+	}
+}
+
+// WithOfflineMode serves GET requests exclusively from the cache and fails
+// every other request with an *OfflineError without touching the network,
+// for demos and tests in air-gapped environments. Cached entries are served
+// however old they are, as long as the Cache still returns them, so pair it
+// with a cache populated by an earlier online run.
+func WithOfflineMode(offline bool) ClientOption {
+	return func(c *Client) {
+		c.offline = offline
+	}
+}
+
 // NewClient creates a new Refyne client.
 func NewClient(apiKey string, opts ...ClientOption) *Client {
 	c := &Client{
-		apiKey:     apiKey,
+		apiKey:     NewSecret(apiKey),
 		baseURL:    DefaultBaseURL,
 		httpClient: &http.Client{},
 		timeout:    DefaultTimeout,
 		maxRetries: DefaultMaxRetries,
+		maxBody:    DefaultMaxResponseSize,
+		userAgent:  fmt.Sprintf("refyne-go/%s", SDKVersion),
 		logger:     &noopLogger{},
+		mu:         &sync.RWMutex{},
+		metrics:    NoopMetricsRecorder{},
+
+		cache:         NewMemoryCache(DefaultCacheMaxEntries),
+		cacheEnabled:  true,
+		cacheKeyFunc:  DefaultCacheKey,
+		revalidating:  &sync.Map{},
+		cacheVariants: newCacheVariants(),
+
+		deprecations: &sync.Map{},
+		latency:      &latencyEstimate{},
 	}
 
 	for _, opt := range opts {
 		opt(c)
 	}
 
-	// Initialize sub-clients
+	c.configureTransport()
+	c.initServices()
+
+	return c
+}
+
+// With returns a derived client that shares the HTTP transport and cache with c but
+// applies the given options on top of c's configuration. The parent client
+// is left untouched, which makes it cheap to vary the timeout or logger for
+// a single request scope, or to hold per-tenant settings in a multi-tenant
+// server. Only options that change how connections are dialed, such as
+// WithHostOverride, give the derived client a transport of its own.
+func (c *Client) With(opts ...ClientOption) *Client {
+	c.mu.RLock()
+	clone := *c
+	c.mu.RUnlock()
+	clone.mu = &sync.RWMutex{}
+	clone.apiKey = clone.apiKey.clone()
+	for _, opt := range opts {
+		opt(&clone)
+	}
+	clone.configureTransport()
+	clone.initServices()
+	return &clone
+}
+
+// Clone returns a copy of c that shares its transport and cache. It is
+// equivalent to c.With().
+func (c *Client) Clone() *Client {
+	return c.With()
+}
+
+// initServices points the sub-clients at c.
+func (c *Client) initServices() {
 	c.Jobs = &JobsClient{client: c}
 	c.Schemas = &SchemasClient{client: c}
 	c.Sites = &SitesClient{client: c}
+	c.Schedules = &SchedulesClient{client: c}
 	c.Keys = &KeysClient{client: c}
 	c.LLM = &LLMClient{client: c}
 	c.Webhooks = &WebhooksClient{client: c}
+	c.Templates = &TemplatesClient{client: c}
+	c.Collections = &CollectionsClient{client: c}
+}
 
-	return c
+// FetchOptions controls how pages are fetched, for sites behind consent
+// walls, logins or geo restrictions.
+type FetchOptions struct {
+	// Headers are sent with every page request.
+	Headers map[string]string `json:"headers,omitempty"`
+	// Cookies are set before the page is requested.
+	Cookies []Cookie `json:"cookies,omitempty"`
+	// WaitFor is a CSS selector to wait for before capturing a dynamically
+	// fetched page.
+	WaitFor string `json:"wait_for,omitempty"`
+	// WaitMs is an extra delay in milliseconds after a dynamic page loads.
+	WaitMs int `json:"wait_ms,omitempty"`
+	// Geo is an ISO 3166-1 alpha-2 country code to fetch from.
+	Geo string `json:"geo,omitempty"`
+	// MaxRedirects limits how many redirects are followed. Nil uses the API
+	// default; zero disables redirects.
+	MaxRedirects *int `json:"max_redirects,omitempty"`
+	// DisallowCrossDomainRedirects fails the fetch if a redirect leaves the
+	// requested domain, e.g. to a consent or geo page.
+	DisallowCrossDomainRedirects bool `json:"disallow_cross_domain_redirects,omitempty"`
+}
+
+// Cookie is a cookie sent when fetching pages.
+type Cookie struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Domain string `json:"domain,omitempty"`
+	Path   string `json:"path,omitempty"`
 }
 
 // ExtractInput contains parameters for single-page extraction.
 type ExtractInput struct {
-	URL       string          `json:"url"`
-	Schema    any             `json:"schema"`
-	FetchMode *string         `json:"fetch_mode,omitempty"`
-	LLMConfig *LLMConfigInput `json:"llm_config,omitempty"`
+	URL          string          `json:"url"`
+	Schema       any             `json:"schema"`
+	FetchMode    *string         `json:"fetch_mode,omitempty"`
+	FetchOptions *FetchOptions   `json:"fetch_options,omitempty"`
+	LLMConfig    *LLMConfigInput `json:"llm_config,omitempty"`
+}
+
+// ExtractOutput is the result of Extract.
+type ExtractOutput struct {
+	ExtractOutputBody
+	Metadata      ExtractionMetadata `json:"metadata"`
+	UnknownFields `json:"-"`
+}
+
+// ExtractionMetadata describes how a page was fetched and extracted.
+type ExtractionMetadata struct {
+	MetadataResponse
+	// FinalURL is the URL the page was fetched from after redirects.
+	FinalURL string `json:"final_url,omitempty"`
+	// RedirectChain lists the URLs visited before FinalURL, in order.
+	RedirectChain []string `json:"redirect_chain,omitempty"`
+}
+
+// Redirected reports whether the page was fetched from a different URL
+// than requested.
+func (m ExtractionMetadata) Redirected() bool {
+	return len(m.RedirectChain) > 0
 }
 
 // Extract extracts structured data from a single web page.
-func (c *Client) Extract(ctx context.Context, input ExtractInput) (*ExtractOutputBody, error) {
-	var result ExtractOutputBody
+func (c *Client) Extract(ctx context.Context, input ExtractInput, reqOpts ...RequestOption) (*ExtractOutput, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
+	ctx = c.withIdempotencyKey(ctx)
+	var result ExtractOutput
 	err := c.request(ctx, http.MethodPost, "/api/v1/extract", input, &result)
 	if err != nil {
 		return nil, err
@@ -145,13 +440,25 @@ type CrawlInput struct {
 	Options    *CrawlOptions   `json:"options,omitempty"`
 	WebhookURL *string         `json:"webhook_url,omitempty"`
 	LLMConfig  *LLMConfigInput `json:"llm_config,omitempty"`
+	// IncludeContent stores the raw content of each page so it can be
+	// retrieved later with Jobs.GetPageContent.
+	IncludeContent bool `json:"include_content,omitempty"`
 }
 
 // Crawl starts an asynchronous crawl job.
-func (c *Client) Crawl(ctx context.Context, input CrawlInput) (*CrawlJobResponseBody, error) {
+func (c *Client) Crawl(ctx context.Context, input CrawlInput, reqOpts ...RequestOption) (*CrawlJobResponseBody, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
+	ctx = c.withIdempotencyKey(ctx)
+	ctx = c.withQueueKey(ctx)
+	if input.Options == nil {
+		input.Options = c.snapshot().crawlOpts
+	}
 	var result CrawlJobResponseBody
 	err := c.request(ctx, http.MethodPost, "/api/v1/crawl", input, &result)
 	if err != nil {
+		if c.queue != nil && queueable(ctx, err) {
+			return nil, c.enqueueCrawl(ctx, input, err)
+		}
 		return nil, err
 	}
 	return &result, nil
@@ -159,13 +466,61 @@ func (c *Client) Crawl(ctx context.Context, input CrawlInput) (*CrawlJobResponse
 
 // AnalyzeInput contains parameters for website analysis.
 type AnalyzeInput struct {
-	URL   string `json:"url"`
-	Depth *int   `json:"depth,omitempty"`
+	URL          string        `json:"url"`
+	Depth        *int          `json:"depth,omitempty"`
+	FetchMode    *string       `json:"fetch_mode,omitempty"`
+	FetchOptions *FetchOptions `json:"fetch_options,omitempty"`
+}
+
+// AnalyzeOutput is the result of Analyze.
+type AnalyzeOutput struct {
+	AnalyzeResponseBody
+	// SuggestedCrawl is set when the analysis found enough structure to
+	// suggest how to crawl the site.
+	SuggestedCrawl *SuggestedCrawl `json:"suggested_crawl,omitempty"`
+	UnknownFields  `json:"-"`
+}
+
+// SuggestedCrawl describes how Analyze suggests crawling a site.
+type SuggestedCrawl struct {
+	// FollowSelectors are CSS selectors for links to detail pages.
+	FollowSelectors []string `json:"follow_selectors,omitempty"`
+	// NextSelectors are CSS selectors for pagination links.
+	NextSelectors []string `json:"next_selectors,omitempty"`
+	// EstimatedPages is the estimated number of pages a crawl would visit.
+	EstimatedPages int64 `json:"estimated_pages,omitempty"`
+	// FetchMode is the recommended fetch mode: static or dynamic.
+	FetchMode string `json:"fetch_mode,omitempty"`
+}
+
+// CrawlOptions converts the suggestion into options for Crawl. Multiple
+// selectors are joined with commas; MaxPages is set from EstimatedPages.
+func (s *SuggestedCrawl) CrawlOptions() *CrawlOptions {
+	opts := &CrawlOptions{}
+	if len(s.FollowSelectors) > 0 {
+		follow := strings.Join(s.FollowSelectors, ", ")
+		opts.FollowSelector = &follow
+	}
+	if len(s.NextSelectors) > 0 {
+		next := strings.Join(s.NextSelectors, ", ")
+		opts.NextSelector = &next
+	}
+	if s.EstimatedPages > 0 {
+		pages := s.EstimatedPages
+		opts.MaxPages = &pages
+	}
+	if s.FetchMode != "" {
+		mode := CrawlOptionsFetchMode(s.FetchMode)
+		opts.FetchMode = &mode
+	}
+	return opts
 }
 
-// Analyze analyzes a website to detect structure and suggest schemas.
-func (c *Client) Analyze(ctx context.Context, input AnalyzeInput) (*AnalyzeResponseBody, error) {
-	var result AnalyzeResponseBody
+// Analyze analyzes a website to detect structure and suggest schemas and
+// crawl options.
+func (c *Client) Analyze(ctx context.Context, input AnalyzeInput, reqOpts ...RequestOption) (*AnalyzeOutput, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
+	var result AnalyzeOutput
 	err := c.request(ctx, http.MethodPost, "/api/v1/analyze", input, &result)
 	if err != nil {
 		return nil, err
@@ -173,8 +528,119 @@ func (c *Client) Analyze(ctx context.Context, input AnalyzeInput) (*AnalyzeRespo
 	return &result, nil
 }
 
+// PageType is the kind of page reported by ClassifyPages.
+type PageType string
+
+// Page types returned by ClassifyPages.
+const (
+	PageTypeListing PageType = "listing"
+	PageTypeDetail  PageType = "detail"
+	PageTypeArticle PageType = "article"
+	PageTypeLogin   PageType = "login"
+	PageTypeError   PageType = "error"
+)
+
+// PageClassification is the classification of a single URL.
+type PageClassification struct {
+	URL        string   `json:"url"`
+	PageType   PageType `json:"page_type"`
+	Confidence float64  `json:"confidence"`
+	// Error is set when the page could not be fetched or classified.
+	Error string `json:"error,omitempty"`
+}
+
+// ClassifyPagesOutput contains a classification for each requested URL.
+type ClassifyPagesOutput struct {
+	Pages         []PageClassification `json:"pages"`
+	UnknownFields `json:"-"`
+}
+
+// ClassifyPages classifies URLs by page type without extracting data. It is
+// much cheaper than Extract, so large URL inventories can be triaged first.
+func (c *Client) ClassifyPages(ctx context.Context, urls []string, reqOpts ...RequestOption) (*ClassifyPagesOutput, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
+	var result ClassifyPagesOutput
+	err := c.request(ctx, http.MethodPost, "/api/v1/classify", map[string]any{"urls": urls}, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// URLStatus reports the reachability of a single URL.
+type URLStatus struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"status_code"`
+	// RedirectURL is the final URL if the request was redirected.
+	RedirectURL string `json:"redirect_url,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	// RobotsAllowed reports whether robots.txt permits fetching the URL.
+	RobotsAllowed bool `json:"robots_allowed"`
+	// Error is set when the URL could not be fetched at all.
+	Error string `json:"error,omitempty"`
+}
+
+// CheckURLsOutput contains the status of each requested URL.
+type CheckURLsOutput struct {
+	URLs          []URLStatus `json:"urls"`
+	UnknownFields `json:"-"`
+}
+
+// CheckURLs fetches the status of URLs without extracting data, so dead or
+// disallowed links can be pruned from seed lists before jobs run.
+func (c *Client) CheckURLs(ctx context.Context, urls []string, reqOpts ...RequestOption) (*CheckURLsOutput, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
+	var result CheckURLsOutput
+	err := c.request(ctx, http.MethodPost, "/api/v1/urls/check", map[string]any{"urls": urls}, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SearchInput contains parameters for a web search with extraction.
+type SearchInput struct {
+	Query string `json:"query"`
+	// N is the number of top hits to extract from. Zero uses the API default.
+	N         int             `json:"n,omitempty"`
+	Schema    any             `json:"schema"`
+	LLMConfig *LLMConfigInput `json:"llm_config,omitempty"`
+}
+
+// SearchResult is the extraction result for one search hit.
+type SearchResult struct {
+	URL   string `json:"url"`
+	Title string `json:"title"`
+	Rank  int    `json:"rank"`
+	Data  any    `json:"data"`
+	// Error is set when the hit could not be fetched or extracted.
+	Error string `json:"error,omitempty"`
+}
+
+// SearchOutput contains the extracted results of a search.
+type SearchOutput struct {
+	JobID         string         `json:"job_id"`
+	Results       []SearchResult `json:"results"`
+	UnknownFields `json:"-"`
+}
+
+// Search runs a web search and extracts data from the top hits.
+func (c *Client) Search(ctx context.Context, input SearchInput, reqOpts ...RequestOption) (*SearchOutput, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
+	if err := c.requireFeature(ctx, FeatureSearch); err != nil {
+		return nil, err
+	}
+	var result SearchOutput
+	err := c.request(ctx, http.MethodPost, "/api/v1/search", input, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
 // GetUsage returns usage statistics for the current billing period.
-func (c *Client) GetUsage(ctx context.Context) (*GetUsageOutputBody, error) {
+func (c *Client) GetUsage(ctx context.Context, reqOpts ...RequestOption) (*GetUsageOutputBody, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
 	var result GetUsageOutputBody
 	err := c.request(ctx, http.MethodGet, "/api/v1/usage", nil, &result)
 	if err != nil {
@@ -184,7 +650,8 @@ func (c *Client) GetUsage(ctx context.Context) (*GetUsageOutputBody, error) {
 }
 
 // Health checks the API health status.
-func (c *Client) Health(ctx context.Context) (*HealthCheckOutputBody, error) {
+func (c *Client) Health(ctx context.Context, reqOpts ...RequestOption) (*HealthCheckOutputBody, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
 	var result HealthCheckOutputBody
 	err := c.request(ctx, http.MethodGet, "/api/v1/health", nil, &result)
 	if err != nil {
@@ -194,7 +661,8 @@ func (c *Client) Health(ctx context.Context) (*HealthCheckOutputBody, error) {
 }
 
 // ListCleaners returns available content cleaners.
-func (c *Client) ListCleaners(ctx context.Context) (*ListCleanersOutputBody, error) {
+func (c *Client) ListCleaners(ctx context.Context, reqOpts ...RequestOption) (*ListCleanersOutputBody, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
 	var result ListCleanersOutputBody
 	err := c.request(ctx, http.MethodGet, "/api/v1/cleaners", nil, &result)
 	if err != nil {
@@ -204,7 +672,8 @@ func (c *Client) ListCleaners(ctx context.Context) (*ListCleanersOutputBody, err
 }
 
 // GetPricingTiers returns the available pricing tiers and their limits.
-func (c *Client) GetPricingTiers(ctx context.Context) (*ListTierLimitsOutputBody, error) {
+func (c *Client) GetPricingTiers(ctx context.Context, reqOpts ...RequestOption) (*ListTierLimitsOutputBody, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
 	var result ListTierLimitsOutputBody
 	err := c.request(ctx, http.MethodGet, "/api/v1/pricing/tiers", nil, &result)
 	if err != nil {
@@ -215,124 +684,404 @@ func (c *Client) GetPricingTiers(ctx context.Context) (*ListTierLimitsOutputBody
 
 // request performs an HTTP request with retry logic.
 func (c *Client) request(ctx context.Context, method, path string, body any, result any) error {
-	return c.requestWithRetry(ctx, method, path, body, result, 1)
+	key, err := c.requestCacheKey(ctx, method, c.apiPath(ctx, path), body)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := c.callContext(ctx)
+	defer cancel()
+
+	start := time.Now()
+	var resp *response
+	if key != "" {
+		resp, err = c.cachedRequest(ctx, key, method, path, body)
+	} else {
+		resp, err = c.requestWithRetry(ctx, method, path, body, result, 1)
+	}
+	if err != nil {
+		id, _ := RequestIDFromContext(ctx)
+		return withRequestID(err, id)
+	}
+	recordCallInfo(ctx, resp, time.Since(start))
+	c.invalidateAfterWrite(ctx, method, path)
+
+	// Parse successful response
+	if result != nil && !resp.decoded && len(resp.body) > 0 {
+		if err := c.decode(resp, result); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// requestCacheKey returns the cache key for a request, or "" if it is not cacheable.
+func (c *Client) requestCacheKey(ctx context.Context, method, path string, body any) (string, error) {
+	if !c.cacheEnabled || c.cache == nil {
+		return "", nil
+	}
+	var bodyBytes []byte
+	if body != nil {
+		var err error
+		if bodyBytes, err = json.Marshal(body); err != nil {
+			return "", fmt.Errorf("failed to marshal request body: %w", err)
+		}
+	}
+	return c.cacheKey(ctx, method, c.snapshot().baseURL+path, bodyBytes), nil
+}
+
+// response is a successful HTTP response with its body already read.
+type response struct {
+	status   int
+	header   http.Header
+	body     []byte
+	attempts int
+	cached   bool
+	// decoded is set when the body was decoded into the result while it
+	// was read, leaving body empty.
+	decoded bool
 }
 
-func (c *Client) requestWithRetry(ctx context.Context, method, path string, body any, result any, attempt int) error {
+func (c *Client) requestWithRetry(ctx context.Context, method, path string, body, result any, attempt int) (*response, error) {
 	// Check if context is already cancelled before proceeding
 	if err := ctx.Err(); err != nil {
-		return &NetworkError{Err: err}
+		return nil, &NetworkError{Err: err}
 	}
+	retry := c.retryConfig(ctx, method, path)
 
-	// Create a request-scoped context with timeout, but respect parent's deadline if shorter
-	reqCtx, cancel := c.contextWithTimeout(ctx, c.timeout)
+	// Bound this attempt with the timeout resolved by callContext and the
+	// remaining retry budget
+	reqCtx, cancel := ctx, context.CancelFunc(func() {})
+	timeout := requestOptionsFromContext(ctx).timeout
+	if remaining, ok := c.remainingRetryBudget(ctx); ok && (timeout == 0 || remaining < timeout) {
+		timeout = remaining
+	}
+	if timeout > 0 {
+		reqCtx, cancel = context.WithTimeout(ctx, timeout)
+	}
 	defer cancel()
 
-	url := c.baseURL + path
-
 	var bodyReader io.Reader
+	var compressed bool
 	if body != nil {
 		bodyBytes, err := json.Marshal(body)
 		if err != nil {
-			return fmt.Errorf("failed to marshal request body: %w", err)
+			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		if bodyBytes, compressed, err = c.compressBody(bodyBytes); err != nil {
+			return nil, err
 		}
 		bodyReader = bytes.NewReader(bodyBytes)
 	}
 
-	req, err := http.NewRequestWithContext(reqCtx, method, url, bodyReader)
+	if c.breaker != nil {
+		if err := c.breaker.allow(); err != nil {
+			return nil, err
+		}
+	}
+	if c.limiter != nil {
+		if err := c.limiter.wait(ctx, c.sleepWithContext); err != nil {
+			return nil, &NetworkError{Err: err}
+		}
+	}
+	if c.tuner != nil {
+		if err := c.tuner.wait(ctx, c); err != nil {
+			return nil, &NetworkError{Err: err}
+		}
+	}
+
+	req, err := c.newRequest(reqCtx, method, path, bodyReader)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return nil, err
+	}
+	if compressed {
+		req.Header.Set("Content-Encoding", "gzip")
 	}
+	if c.compress {
+		req.Header.Set("Accept-Encoding", "gzip")
+	}
+	requestID, _ := RequestIDFromContext(ctx)
+	tracer := newRequestTracer(method, path, requestID, attempt)
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), tracer.clientTrace()))
 
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("User-Agent", fmt.Sprintf("refyne-go/%s", SDKVersion))
+	release, err := c.acquireSlot(ctx)
+	if err != nil {
+		return nil, &NetworkError{Err: err}
+	}
+	defer release()
 
 	resp, err := c.httpClient.Do(req)
+	if err == nil || ctx.Err() == nil {
+		ok := err == nil && resp.StatusCode < 500
+		c.reportEndpoint(ctx, req, ok)
+		c.reportCircuit(ok)
+	}
 	if err != nil {
+		c.recordTiming(tracer.done(0))
 		// Check if context was cancelled
 		if ctx.Err() != nil {
-			return &NetworkError{Err: ctx.Err()}
+			return nil, &NetworkError{Err: ctx.Err()}
 		}
 		// Retry on network errors
-		if attempt <= c.maxRetries {
-			backoff := c.calculateBackoff(attempt)
+		if attempt <= retry.MaxRetries {
+			backoff := c.retryBackoff(retry, attempt)
+			if err := c.checkRetry(ctx, attempt, backoff, &NetworkError{Err: err}); err != nil {
+				return nil, err
+			}
 			c.logger.Warn("Network error, retrying", map[string]any{
-				"error":   err.Error(),
-				"attempt": attempt,
-				"backoff": backoff,
+				"error":      err.Error(),
+				"attempt":    attempt,
+				"backoff":    backoff,
+				"request_id": requestID,
 			})
+			c.reportRetry(RetryEvent{Method: method, Path: path, Attempt: attempt, Delay: backoff, Reason: RetryNetworkError, Err: &NetworkError{Err: err}, RequestID: requestID})
+			release()
 			// Sleep with context cancellation support
 			if err := c.sleepWithContext(ctx, backoff); err != nil {
-				return &NetworkError{Err: err}
+				return nil, &NetworkError{Err: err}
 			}
 			return c.requestWithRetry(ctx, method, path, body, result, attempt+1)
 		}
-		return &NetworkError{Err: err}
+		return nil, &NetworkError{Err: err}
 	}
 	defer func() { _ = resp.Body.Close() }()
+	c.checkDeprecation(method, path, resp.Header)
+	if c.limiter != nil {
+		c.limiter.observe(resp.Header)
+	}
+	if c.tuner != nil {
+		c.tuner.observe(resp)
+	}
+
+	respReader, err := c.responseBody(resp)
+	if err != nil {
+		c.recordTiming(tracer.done(resp.StatusCode))
+		return nil, err
+	}
 
-	respBody, err := io.ReadAll(resp.Body)
+	// Decode successful JSON responses as they arrive rather than buffering
+	// the whole body first.
+	if result != nil && resp.StatusCode < 300 && !c.usesWireFormat(resp.Header) {
+		err := c.decodeStream(respReader, path, result)
+		c.recordTiming(tracer.done(resp.StatusCode))
+		if err != nil {
+			return nil, err
+		}
+		return &response{status: resp.StatusCode, header: resp.Header, attempts: attempt, decoded: true}, nil
+	}
+
+	respBody, err := c.readBody(respReader, path)
+	c.recordTiming(tracer.done(resp.StatusCode))
 	if err != nil {
-		return fmt.Errorf("failed to read response: %w", err)
+		return nil, err
 	}
 
 	// Handle rate limiting
-	if resp.StatusCode == http.StatusTooManyRequests && attempt <= c.maxRetries {
+	if resp.StatusCode == http.StatusTooManyRequests && attempt <= retry.MaxRetries {
 		retryAfter := c.parseRetryAfter(resp.Header.Get("Retry-After"))
+		if hint, ok := backoffHint(resp.Header); ok {
+			retryAfter = hint
+		}
+		if err := c.checkRetry(ctx, attempt, retryAfter, c.responseError(resp, respBody)); err != nil {
+			return nil, err
+		}
 		c.logger.Warn("Rate limited, retrying", map[string]any{
 			"retry_after": retryAfter,
 			"attempt":     attempt,
+			"request_id":  requestID,
 		})
+		c.reportRetry(RetryEvent{Method: method, Path: path, Attempt: attempt, Delay: retryAfter, Reason: RetryRateLimited, Status: resp.StatusCode, Err: c.responseError(resp, respBody), RequestID: requestID})
+		release()
 		// Sleep with context cancellation support
 		if err := c.sleepWithContext(ctx, retryAfter); err != nil {
-			return &NetworkError{Err: err}
+			return nil, &NetworkError{Err: err}
 		}
 		return c.requestWithRetry(ctx, method, path, body, result, attempt+1)
 	}
 
 	// Handle server errors with retry
-	if resp.StatusCode >= 500 && attempt <= c.maxRetries {
-		backoff := c.calculateBackoff(attempt)
+	if resp.StatusCode >= 500 && attempt <= retry.MaxRetries {
+		backoff := c.retryBackoff(retry, attempt)
+		if hint, ok := serverBackoff(resp.Header); ok {
+			backoff = hint
+		}
+		if err := c.checkRetry(ctx, attempt, backoff, c.responseError(resp, respBody)); err != nil {
+			return nil, err
+		}
 		c.logger.Warn("Server error, retrying", map[string]any{
-			"status":  resp.StatusCode,
-			"attempt": attempt,
-			"backoff": backoff,
+			"status":     resp.StatusCode,
+			"attempt":    attempt,
+			"backoff":    backoff,
+			"request_id": requestID,
 		})
+		c.reportRetry(RetryEvent{Method: method, Path: path, Attempt: attempt, Delay: backoff, Reason: RetryServerError, Status: resp.StatusCode, Err: c.responseError(resp, respBody), RequestID: requestID})
+		release()
 		// Sleep with context cancellation support
 		if err := c.sleepWithContext(ctx, backoff); err != nil {
-			return &NetworkError{Err: err}
+			return nil, &NetworkError{Err: err}
 		}
 		return c.requestWithRetry(ctx, method, path, body, result, attempt+1)
 	}
 
-	// Handle errors
+	// Handle errors, retrying those the classifier marks as retryable
 	if resp.StatusCode >= 400 {
-		return c.parseError(resp.StatusCode, respBody)
+		apiErr := c.responseError(resp, respBody)
+		if c.retryable != nil && attempt <= retry.MaxRetries && c.retryable(resp.StatusCode, apiErr) {
+			backoff := c.retryBackoff(retry, attempt)
+			if hint, ok := serverBackoff(resp.Header); ok {
+				backoff = hint
+			}
+			if err := c.checkRetry(ctx, attempt, backoff, apiErr); err != nil {
+				return nil, err
+			}
+			c.logger.Warn("Retryable error, retrying", map[string]any{
+				"status":     resp.StatusCode,
+				"attempt":    attempt,
+				"backoff":    backoff,
+				"request_id": requestID,
+			})
+			c.reportRetry(RetryEvent{Method: method, Path: path, Attempt: attempt, Delay: backoff, Reason: RetryClassified, Status: resp.StatusCode, Err: apiErr, RequestID: requestID})
+			release()
+			if err := c.sleepWithContext(ctx, backoff); err != nil {
+				return nil, &NetworkError{Err: err}
+			}
+			return c.requestWithRetry(ctx, method, path, body, result, attempt+1)
+		}
+		return nil, apiErr
 	}
 
-	// Parse successful response
-	if result != nil && len(respBody) > 0 {
-		if err := json.Unmarshal(respBody, result); err != nil {
-			return fmt.Errorf("failed to parse response: %w", err)
-		}
+	return &response{status: resp.StatusCode, header: resp.Header, body: respBody, attempts: attempt}, nil
+}
+
+// decodeStream decodes a JSON body into result as it is read, enforcing the
+// maximum response size. An empty body leaves result unchanged.
+func (c *Client) decodeStream(r io.Reader, path string, result any) error {
+	if c.maxBody > 0 {
+		r = &sizeLimitReader{r: r, limit: c.maxBody, path: path}
+	}
+	err := c.decodeJSON(r, result)
+	var tooLarge *ResponseTooLargeError
+	switch {
+	case err == nil:
+		// Drain trailing whitespace so the connection can be reused.
+		_, _ = io.Copy(io.Discard, io.LimitReader(r, 4<<10))
+		return nil
+	case errors.Is(err, io.EOF):
+		return nil
+	case errors.As(err, &tooLarge):
+		return tooLarge
+	default:
+		return fmt.Errorf("failed to parse response: %w", err)
 	}
+}
 
-	return nil
+// sizeLimitReader fails with a *ResponseTooLargeError once more than limit
+// bytes are read.
+type sizeLimitReader struct {
+	r     io.Reader
+	limit int64
+	n     int64
+	path  string
+}
+
+func (l *sizeLimitReader) Read(p []byte) (int, error) {
+	if rest := l.limit - l.n + 1; int64(len(p)) > rest {
+		p = p[:rest]
+	}
+	n, err := l.r.Read(p)
+	l.n += int64(n)
+	if l.n > l.limit {
+		return 0, &ResponseTooLargeError{Path: l.path, Limit: l.limit}
+	}
+	return n, err
+}
+
+// readBody reads a response body, enforcing the maximum response size.
+func (c *Client) readBody(r io.Reader, path string) ([]byte, error) {
+	if c.maxBody > 0 {
+		r = io.LimitReader(r, c.maxBody+1)
+	}
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if c.maxBody > 0 && int64(len(body)) > c.maxBody {
+		return nil, &ResponseTooLargeError{Path: path, Limit: c.maxBody}
+	}
+	return body, nil
 }
 
-// contextWithTimeout creates a context with timeout, respecting the parent's deadline if shorter.
-func (c *Client) contextWithTimeout(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
-	// If parent has a deadline, use the shorter of the two
-	if deadline, ok := parent.Deadline(); ok {
-		parentTimeout := time.Until(deadline)
-		if parentTimeout < timeout {
-			// Parent deadline is sooner, use it (context.WithTimeout would pick the shorter anyway)
-			return context.WithTimeout(parent, parentTimeout)
+// newRequest builds an API request with the standard headers, plus any
+// headers carried by ctx.
+func (c *Client) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	if c.offline {
+		return nil, &OfflineError{Method: method, Path: path}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.endpointURL(ctx)+c.apiPath(ctx, path), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	token, err := c.token(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", c.accept(ctx))
+	req.Header.Set("User-Agent", c.userAgent)
+	if c.region != "" {
+		req.Header.Set(RegionHeader, string(c.region))
+	}
+	applyContextHeaders(ctx, req)
+
+	if c.replayProtect && method != http.MethodGet && method != http.MethodHead {
+		if err := signRequest(req, token, time.Now()); err != nil {
+			return nil, fmt.Errorf("failed to sign request: %w", err)
+		}
+	}
+	if c.signer != nil {
+		if err := c.signer(req); err != nil {
+			return nil, fmt.Errorf("failed to sign request: %w", err)
 		}
 	}
-	return context.WithTimeout(parent, timeout)
+
+	return req, nil
+}
+
+// accept returns the Accept header for a request made with ctx.
+func (c *Client) accept(ctx context.Context) string {
+	if accept := requestOptionsFromContext(ctx).accept; accept != "" {
+		return accept
+	}
+	return c.acceptHeader()
+}
+
+// callContext prepares ctx for a call. It pins the client's current
+// settings for the whole call and, unless the caller set a deadline,
+// applies the overall timeout and records the per-attempt timeout for
+// requestWithRetry. A per-call WithRequestTimeout always applies.
+func (c *Client) callContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	settings := c.snapshot()
+	ctx = context.WithValue(ctx, settingsContextKey, settings)
+	ctx = withCallRequestID(ctx)
+	o := requestOptionsFromContext(ctx)
+	if c.retryBudget > 0 {
+		o.callStart = time.Now()
+		ctx = context.WithValue(ctx, requestOptionsContextKey, o)
+	}
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+	if o.timeout == 0 && settings.timeout > 0 {
+		o.timeout = settings.timeout
+		ctx = context.WithValue(ctx, requestOptionsContextKey, o)
+	}
+	if settings.overallTimeout > 0 {
+		return context.WithTimeout(ctx, settings.overallTimeout)
+	}
+	return ctx, func() {}
 }
 
 // sleepWithContext sleeps for the given duration, but returns early if context is cancelled.
@@ -347,6 +1096,39 @@ func (c *Client) sleepWithContext(ctx context.Context, d time.Duration) error {
 	}
 }
 
+// remainingRetryBudget returns the time left in the call's retry budget,
+// if WithMaxElapsedRetryTime is set.
+func (c *Client) remainingRetryBudget(ctx context.Context) (time.Duration, bool) {
+	start := requestOptionsFromContext(ctx).callStart
+	if c.retryBudget <= 0 || start.IsZero() {
+		return 0, false
+	}
+	return c.retryBudget - time.Since(start), true
+}
+
+// checkRetry returns err wrapped in a *RetryBudgetError if waiting wait
+// before another attempt would leave no retry budget, or in a
+// *DeadlineWouldExceedError if the attempt could not finish before ctx's
+// deadline. Otherwise it returns nil.
+func (c *Client) checkRetry(ctx context.Context, attempt int, wait time.Duration, err error) error {
+	if deadline, ok := ctx.Deadline(); ok {
+		remaining := time.Until(deadline)
+		if typical := c.latency.typical(); wait+typical >= remaining {
+			return &DeadlineWouldExceedError{Attempts: attempt, Delay: wait, Typical: typical, Remaining: remaining, Err: err}
+		}
+	}
+	remaining, ok := c.remainingRetryBudget(ctx)
+	if !ok || wait < remaining {
+		return nil
+	}
+	return &RetryBudgetError{
+		Attempts: attempt,
+		Elapsed:  c.retryBudget - remaining,
+		Budget:   c.retryBudget,
+		Err:      err,
+	}
+}
+
 // calculateBackoff returns exponential backoff duration with jitter.
 // Formula: min(2^(attempt-1) * 1s, 30s) + random jitter (0-25% of backoff)
 func (c *Client) calculateBackoff(attempt int) time.Duration {
@@ -362,20 +1144,29 @@ func (c *Client) calculateBackoff(attempt int) time.Duration {
 }
 
 func (c *Client) parseRetryAfter(header string) time.Duration {
-	if header == "" {
-		return time.Second
-	}
-	if seconds, err := strconv.Atoi(header); err == nil {
-		return time.Duration(seconds) * time.Second
+	if d, ok := parseRetryAfterHeader(header, time.Now()); ok {
+		return d
 	}
 	return time.Second
 }
 
+// responseError returns the error for a failed response, carrying the
+// server's request ID.
+func (c *Client) responseError(resp *http.Response, body []byte) error {
+	err := c.parseError(resp.StatusCode, body)
+	var rateErr *RateLimitError
+	if errors.As(err, &rateErr) {
+		rateErr.setQuota(resp.Header, time.Now())
+	}
+	return withRequestID(err, resp.Header.Get(RequestIDHeader))
+}
+
 func (c *Client) parseError(status int, body []byte) error {
 	var errResp struct {
-		Error  string            `json:"error"`
-		Detail string            `json:"detail"`
-		Errors map[string]string `json:"errors"`
+		Error      string            `json:"error"`
+		Detail     string            `json:"detail"`
+		Errors     map[string]string `json:"errors"`
+		ResourceID string            `json:"resource_id"`
 	}
 	_ = json.Unmarshal(body, &errResp)
 
@@ -393,6 +1184,10 @@ func (c *Client) parseError(status int, body []byte) error {
 		return &ForbiddenError{APIError: APIError{Message: msg, Status: status}}
 	case http.StatusNotFound:
 		return &NotFoundError{APIError: APIError{Message: msg, Status: status}}
+	case http.StatusConflict:
+		return &ConflictError{APIError: APIError{Message: msg, Status: status, Detail: errResp.Detail}, ResourceID: errResp.ResourceID}
+	case http.StatusPreconditionFailed:
+		return &PreconditionFailedError{ConflictError{APIError: APIError{Message: msg, Status: status, Detail: errResp.Detail}, ResourceID: errResp.ResourceID}}
 	case http.StatusTooManyRequests:
 		return &RateLimitError{APIError: APIError{Message: msg, Status: status}}
 	default:
@@ -1,11 +1,18 @@
 package refyne
 
 import (
+	"compress/gzip"
 	"context"
 	"encoding/json"
+	"errors"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -13,8 +20,8 @@ import (
 func TestNewClient(t *testing.T) {
 	client := NewClient("test-api-key")
 
-	if client.apiKey != "test-api-key" {
-		t.Errorf("expected apiKey 'test-api-key', got '%s'", client.apiKey)
+	if client.apiKey.Value() != "test-api-key" {
+		t.Errorf("expected apiKey 'test-api-key', got '%s'", client.apiKey.Value())
 	}
 	if client.baseURL != DefaultBaseURL {
 		t.Errorf("expected baseURL '%s', got '%s'", DefaultBaseURL, client.baseURL)
@@ -66,6 +73,31 @@ func TestClientOptions(t *testing.T) {
 	}
 }
 
+func TestClientWith(t *testing.T) {
+	httpClient := &http.Client{}
+	parent := NewClient("test-api-key", WithHTTPClient(httpClient))
+	derived := parent.With(WithTimeout(5*time.Second), WithMaxRetries(0))
+
+	if derived.timeout != 5*time.Second {
+		t.Errorf("expected derived timeout 5s, got %v", derived.timeout)
+	}
+	if derived.maxRetries != 0 {
+		t.Errorf("expected derived maxRetries 0, got %d", derived.maxRetries)
+	}
+	if parent.timeout != DefaultTimeout {
+		t.Errorf("expected parent timeout to stay %v, got %v", DefaultTimeout, parent.timeout)
+	}
+	if derived.httpClient != httpClient {
+		t.Error("expected derived client to share the HTTP client")
+	}
+	if derived.apiKey.Value() != parent.apiKey.Value() {
+		t.Errorf("expected derived apiKey '%s', got '%s'", parent.apiKey.Value(), derived.apiKey.Value())
+	}
+	if derived.Jobs.client != derived {
+		t.Error("expected derived sub-clients to point at the derived client")
+	}
+}
+
 func TestAuthenticationHeader(t *testing.T) {
 	apiKey := "test-bearer-token"
 	var capturedAuth string
@@ -117,6 +149,53 @@ func TestUserAgentHeader(t *testing.T) {
 	if capturedUA != expected {
 		t.Errorf("expected User-Agent '%s', got '%s'", expected, capturedUA)
 	}
+
+	_, err = client.With(WithUserAgentSuffix("MyApp/1.0"), WithCacheEnabled(false)).GetUsage(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected += " MyApp/1.0"; capturedUA != expected {
+		t.Errorf("expected User-Agent '%s', got '%s'", expected, capturedUA)
+	}
+}
+
+func TestContextHeaders(t *testing.T) {
+	var captured http.Header
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		captured = r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"status": "ok"})
+	}))
+	defer server.Close()
+
+	ctx := ContextWithHeader(context.Background(), "X-Tenant-ID", "tenant-a")
+	ctx = ContextWithHeader(ctx, "X-Tenant-ID", "tenant-b")
+	ctx = ContextWithHeader(ctx, "authorization", "Bearer stolen")
+	ctx = ContextWithHeader(ctx, "Content-Type", "text/plain")
+	ctx = ContextWithHeader(ctx, IdempotencyKeyHeader, "shared")
+	ctx = ContextWithRequestID(ctx, "req-123")
+
+	client := NewClient("test-key", WithBaseURL(server.URL))
+	if _, err := client.Health(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := captured.Get("X-Tenant-ID"); got != "tenant-b" {
+		t.Errorf("expected X-Tenant-ID 'tenant-b', got '%s'", got)
+	}
+	if got := captured.Get(RequestIDHeader); got != "req-123" {
+		t.Errorf("expected %s 'req-123', got '%s'", RequestIDHeader, got)
+	}
+	if got := captured.Get("Authorization"); got != "Bearer test-key" {
+		t.Errorf("expected Authorization to be preserved, got '%s'", got)
+	}
+	if got := captured.Get("Content-Type"); got != "application/json" {
+		t.Errorf("expected Content-Type to be preserved, got '%s'", got)
+	}
+	if got := captured.Get(IdempotencyKeyHeader); got != "" {
+		t.Errorf("expected a context Idempotency-Key to be ignored, got '%s'", got)
+	}
 }
 
 func TestExtract(t *testing.T) {
@@ -149,6 +228,8 @@ func TestExtract(t *testing.T) {
 				"model":               "test-model",
 				"fetch_duration_ms":   100,
 				"extract_duration_ms": 200,
+				"final_url":           "https://example.com/",
+				"redirect_chain":      []string{"https://example.com"},
 			},
 		})
 	}))
@@ -166,6 +247,12 @@ func TestExtract(t *testing.T) {
 	if result.Url != "https://example.com" {
 		t.Errorf("expected url 'https://example.com', got '%s'", result.Url)
 	}
+	if result.Metadata.Model != "test-model" {
+		t.Errorf("expected model 'test-model', got '%s'", result.Metadata.Model)
+	}
+	if !result.Metadata.Redirected() || result.Metadata.FinalURL != "https://example.com/" {
+		t.Errorf("expected redirect metadata, got %+v", result.Metadata)
+	}
 }
 
 func TestCrawl(t *testing.T) {
@@ -703,3 +790,1604 @@ func TestRetryAfterParsing(t *testing.T) {
 		}
 	}
 }
+
+func TestWebhookDeliveriesAndRedeliver(t *testing.T) {
+	var listQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/api/v1/webhooks/wh-1/deliveries" && r.Method == http.MethodGet:
+			listQuery = r.URL.RawQuery
+			_ = json.NewEncoder(w).Encode(map[string]any{"deliveries": []any{}})
+		case r.URL.Path == "/api/v1/webhooks/deliveries/del-1/redeliver" && r.Method == http.MethodPost:
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "del-2", "status": "pending"})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL))
+	ctx := context.Background()
+
+	_, err := client.Webhooks.ListDeliveries(ctx, "wh-1", &ListDeliveriesOptions{
+		Limit:  10,
+		Status: "failed",
+		Since:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("ListDeliveries failed: %v", err)
+	}
+	if want := "limit=10&since=2024-01-02T03%3A04%3A05Z&status=failed"; listQuery != want {
+		t.Errorf("expected query %q, got %q", want, listQuery)
+	}
+
+	delivery, err := client.Webhooks.RedeliverEvent(ctx, "del-1")
+	if err != nil {
+		t.Fatalf("RedeliverEvent failed: %v", err)
+	}
+	if delivery.Id != "del-2" {
+		t.Errorf("expected delivery id 'del-2', got '%s'", delivery.Id)
+	}
+}
+
+func TestNotificationChannels(t *testing.T) {
+	var bodies []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		bodies = append(bodies, body)
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "wh-1"})
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL))
+	ctx := context.Background()
+
+	if _, err := client.Webhooks.Create(ctx, SlackChannel("alerts", "https://hooks.slack.com/services/x", "job.failed")); err != nil {
+		t.Fatalf("Create slack channel failed: %v", err)
+	}
+	if _, err := client.Webhooks.Create(ctx, EmailChannel("ops", []string{"ops@example.com"}, "job.failed")); err != nil {
+		t.Fatalf("Create email channel failed: %v", err)
+	}
+
+	if bodies[0]["type"] != "slack" || bodies[0]["url"] != "https://hooks.slack.com/services/x" {
+		t.Errorf("unexpected slack channel body: %v", bodies[0])
+	}
+	if bodies[1]["type"] != "email" || bodies[1]["url"] != nil {
+		t.Errorf("unexpected email channel body: %v", bodies[1])
+	}
+	if recipients, _ := bodies[1]["recipients"].([]any); len(recipients) != 1 {
+		t.Errorf("expected 1 email recipient, got %v", bodies[1]["recipients"])
+	}
+}
+
+func TestJobsWatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/jobs/job-1/stream" {
+			t.Errorf("expected path '/api/v1/jobs/job-1/stream', got '%s'", r.URL.Path)
+		}
+		if r.Header.Get("Accept") != "text/event-stream" {
+			t.Errorf("expected Accept 'text/event-stream', got '%s'", r.Header.Get("Accept"))
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(": keep-alive\n\n" +
+			"event: status\ndata: {\"job_id\":\"job-1\",\"status\":\"running\",\"page_count\":1}\n\n" +
+			"event: result\ndata: {\"id\":\"r-1\",\"url\":\"https://example.com\",\"status\":\"completed\"}\n\n" +
+			"event: complete\ndata: {\"job_id\":\"job-1\",\"status\":\"completed\",\"page_count\":1}\n\n" +
+			"event: status\ndata: {\"job_id\":\"job-1\",\"status\":\"ignored\"}\n\n"))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL))
+
+	var types []EventType
+	err := client.Jobs.Watch(context.Background(), "job-1", func(event Event) error {
+		types = append(types, event.EventType())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+
+	want := []EventType{EventJobProgress, EventJobResult, EventJobCompleted}
+	if len(types) != len(want) {
+		t.Fatalf("expected events %v, got %v", want, types)
+	}
+	for i := range want {
+		if types[i] != want[i] {
+			t.Errorf("event %d: expected %s, got %s", i, want[i], types[i])
+		}
+	}
+}
+
+func TestJobsWatchTruncated(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("event: status\ndata: {\"job_id\":\"job-1\",\"status\":\"running\",\"page_count\":1}\n\n" +
+			"event: result\ndata: {\"id\":\"r-1\",\"url\":\"https://example.com\",\"status\":\"completed\"}\n\n"))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL))
+
+	events := 0
+	err := client.Jobs.Watch(context.Background(), "job-1", func(Event) error {
+		events++
+		return nil
+	})
+	var netErr *NetworkError
+	if !errors.As(err, &netErr) || !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected a NetworkError wrapping io.ErrUnexpectedEOF, got %v", err)
+	}
+	if events != 2 {
+		t.Errorf("expected the 2 events before the stream ended, got %d", events)
+	}
+}
+
+func TestSchemaGallery(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.RequestURI())
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/schemas/gallery":
+			_ = json.NewEncoder(w).Encode(map[string]any{"schemas": []any{
+				map[string]any{"id": "tpl-1", "name": "Job posting", "visibility": "public"},
+			}})
+		default:
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "schema-1", "name": "Job posting"})
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL))
+	ctx := context.Background()
+
+	gallery, err := client.Schemas.Gallery(ctx, &GalleryFilter{Category: "jobs", Limit: 5})
+	if err != nil {
+		t.Fatalf("Gallery failed: %v", err)
+	}
+	if gallery.Schemas == nil || len(*gallery.Schemas) != 1 {
+		t.Fatalf("expected 1 gallery schema, got %v", gallery.Schemas)
+	}
+	if _, err := client.Schemas.Fork(ctx, "tpl-1", "My jobs"); err != nil {
+		t.Fatalf("Fork failed: %v", err)
+	}
+	if _, err := client.Schemas.Publish(ctx, "schema-1"); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if _, err := client.Schemas.Unpublish(ctx, "schema-1"); err != nil {
+		t.Fatalf("Unpublish failed: %v", err)
+	}
+
+	want := []string{
+		"GET /api/v1/schemas/gallery?category=jobs&limit=5",
+		"POST /api/v1/schemas/tpl-1/fork",
+		"POST /api/v1/schemas/schema-1/publish",
+		"POST /api/v1/schemas/schema-1/unpublish",
+	}
+	for i := range want {
+		if i >= len(requests) || requests[i] != want[i] {
+			t.Errorf("request %d: expected %q, got %v", i, want[i], requests)
+		}
+	}
+}
+
+func TestSchemaDeleteInUse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodDelete && r.URL.Path == "/api/v1/schemas/schema-1":
+			w.WriteHeader(http.StatusConflict)
+			_ = json.NewEncoder(w).Encode(map[string]any{"error": "schema is in use"})
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/schemas/schema-1/references":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"sites":     []any{map[string]any{"id": "site-1", "url": "https://example.com"}},
+				"schedules": []any{map[string]any{"id": "sched-1", "name": "nightly"}},
+				"jobs":      []any{},
+			})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL))
+	err := client.Schemas.Delete(context.Background(), "schema-1")
+
+	var inUse *SchemaInUseError
+	if !errors.As(err, &inUse) {
+		t.Fatalf("expected SchemaInUseError, got %T: %v", err, err)
+	}
+	if len(inUse.References.Sites) != 1 || len(inUse.References.Schedules) != 1 {
+		t.Errorf("unexpected references: %+v", inUse.References)
+	}
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) {
+		t.Error("expected SchemaInUseError to unwrap to ConflictError")
+	}
+}
+
+func TestSiteUpdateIfMatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("If-Match"); got != "2024-01-01T00:00:00Z" {
+			t.Errorf("expected If-Match header, got %q", got)
+		}
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if _, ok := body["IfMatch"]; ok {
+			t.Error("IfMatch should not be sent in the body")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusPreconditionFailed)
+		_ = json.NewEncoder(w).Encode(map[string]any{"error": "site was modified", "resource_id": "site-1"})
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL))
+	_, err := client.Sites.Update(context.Background(), "site-1", CreateSiteInput{
+		Name:    "Example",
+		URL:     "https://example.com",
+		IfMatch: "2024-01-01T00:00:00Z",
+	})
+
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("expected ConflictError, got %T: %v", err, err)
+	}
+	if conflict.Status != http.StatusPreconditionFailed || conflict.ResourceID != "site-1" {
+		t.Errorf("expected status 412 for site-1, got %d for %q", conflict.Status, conflict.ResourceID)
+	}
+	var precondition *PreconditionFailedError
+	if !errors.As(err, &precondition) {
+		t.Errorf("expected PreconditionFailedError, got %T", err)
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			URL          string       `json:"url"`
+			FetchOptions FetchOptions `json:"fetch_options"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body.FetchOptions.Headers["Accept-Language"] != "en-GB" {
+			t.Errorf("expected headers to be sent, got %+v", body.FetchOptions.Headers)
+		}
+		if len(body.FetchOptions.Cookies) != 1 || body.FetchOptions.Cookies[0].Name != "consent" {
+			t.Errorf("expected consent cookie, got %+v", body.FetchOptions.Cookies)
+		}
+		if body.FetchOptions.Geo != "GB" {
+			t.Errorf("expected geo 'GB', got %q", body.FetchOptions.Geo)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"job_id":    "job-1",
+			"page_type": "listing",
+			"suggested_crawl": map[string]any{
+				"follow_selectors": []string{"a.product", "a.item"},
+				"next_selectors":   []string{"a.next"},
+				"estimated_pages":  40,
+				"fetch_mode":       "dynamic",
+			},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL))
+	result, err := client.Analyze(context.Background(), AnalyzeInput{
+		URL: "https://example.com",
+		FetchOptions: &FetchOptions{
+			Headers: map[string]string{"Accept-Language": "en-GB"},
+			Cookies: []Cookie{{Name: "consent", Value: "yes"}},
+			Geo:     "GB",
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.PageType != "listing" {
+		t.Errorf("expected page type 'listing', got %q", result.PageType)
+	}
+	if result.SuggestedCrawl == nil {
+		t.Fatal("expected suggested crawl options")
+	}
+	opts := result.SuggestedCrawl.CrawlOptions()
+	if opts.FollowSelector == nil || *opts.FollowSelector != "a.product, a.item" {
+		t.Errorf("unexpected follow selector: %v", opts.FollowSelector)
+	}
+	if opts.NextSelector == nil || *opts.NextSelector != "a.next" {
+		t.Errorf("unexpected next selector: %v", opts.NextSelector)
+	}
+	if opts.MaxPages == nil || *opts.MaxPages != 40 {
+		t.Errorf("unexpected max pages: %v", opts.MaxPages)
+	}
+	if opts.FetchMode == nil || *opts.FetchMode != CrawlOptionsFetchModeDynamic {
+		t.Errorf("unexpected fetch mode: %v", opts.FetchMode)
+	}
+}
+
+func TestClassifyPages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/classify" {
+			t.Errorf("expected path '/api/v1/classify', got '%s'", r.URL.Path)
+		}
+		var body struct {
+			URLs []string `json:"urls"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if len(body.URLs) != 2 {
+			t.Errorf("expected 2 urls, got %v", body.URLs)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"pages": []any{
+				map[string]any{"url": body.URLs[0], "page_type": "listing", "confidence": 0.9},
+				map[string]any{"url": body.URLs[1], "page_type": "login", "confidence": 0.8},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL))
+	result, err := client.ClassifyPages(context.Background(), []string{
+		"https://example.com/products",
+		"https://example.com/login",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Pages) != 2 || result.Pages[1].PageType != PageTypeLogin {
+		t.Errorf("unexpected classification: %+v", result.Pages)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/search" {
+			t.Errorf("expected path '/api/v1/search', got '%s'", r.URL.Path)
+		}
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["query"] != "best espresso grinders" || body["n"] != float64(3) {
+			t.Errorf("unexpected body: %v", body)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"job_id": "job-1",
+			"results": []any{
+				map[string]any{"url": "https://example.com/a", "rank": 1, "data": map[string]any{"name": "A"}},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL))
+	result, err := client.Search(context.Background(), SearchInput{
+		Query:  "best espresso grinders",
+		N:      3,
+		Schema: map[string]any{"name": "string"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Results) != 1 || result.Results[0].URL != "https://example.com/a" {
+		t.Errorf("unexpected results: %+v", result.Results)
+	}
+}
+
+func TestCheckURLs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/urls/check" {
+			t.Errorf("expected path '/api/v1/urls/check', got '%s'", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"urls": []any{
+				map[string]any{
+					"url":            "http://example.com",
+					"status_code":    200,
+					"redirect_url":   "https://example.com/",
+					"content_type":   "text/html",
+					"robots_allowed": true,
+				},
+				map[string]any{"url": "https://example.com/gone", "status_code": 404},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL))
+	result, err := client.CheckURLs(context.Background(), []string{"http://example.com", "https://example.com/gone"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.URLs) != 2 {
+		t.Fatalf("expected 2 statuses, got %d", len(result.URLs))
+	}
+	if result.URLs[0].RedirectURL != "https://example.com/" || !result.URLs[0].RobotsAllowed {
+		t.Errorf("unexpected status: %+v", result.URLs[0])
+	}
+	if result.URLs[1].StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", result.URLs[1].StatusCode)
+	}
+}
+
+func TestHostOverride(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Host, "api.refyne.internal:") {
+			t.Errorf("expected original Host header, got %q", r.Host)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"status": "ok"})
+	}))
+	defer server.Close()
+
+	port := strings.TrimPrefix(server.URL, "http://127.0.0.1:")
+	client := NewClient("test-key",
+		WithBaseURL("http://api.refyne.internal:"+port),
+		WithHostOverride("api.refyne.internal", "127.0.0.1"),
+	)
+	if _, err := client.Health(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestUnixSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "refyne.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"status": "ok"})
+	}))
+	server.Listener = listener
+	server.Start()
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL("http://refyne"), WithUnixSocket(socket))
+	if _, err := client.Health(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRequestSigner(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if got, want := r.Header.Get("X-Signature"), r.Method+" "+r.URL.Path+" "+string(body); got != want {
+			t.Errorf("expected signature %q, got %q", want, got)
+		}
+		if r.Header.Get("X-Tenant") != "acme" {
+			t.Error("expected context headers to be set before signing")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "site-1"})
+	}))
+	defer server.Close()
+
+	signer := func(req *http.Request) error {
+		if req.Header.Get("X-Tenant") == "" {
+			return errors.New("context headers missing")
+		}
+		var body []byte
+		if req.GetBody != nil {
+			rc, err := req.GetBody()
+			if err != nil {
+				return err
+			}
+			body, _ = io.ReadAll(rc)
+		}
+		req.Header.Set("X-Signature", req.Method+" "+req.URL.Path+" "+string(body))
+		return nil
+	}
+
+	client := NewClient("test-key", WithBaseURL(server.URL), WithRequestSigner(signer))
+	ctx := ContextWithHeader(context.Background(), "X-Tenant", "acme")
+	if _, err := client.Sites.Create(ctx, CreateSiteInput{Name: "Example", URL: "https://example.com"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	failing := client.With(WithRequestSigner(func(*http.Request) error { return errors.New("no credentials") }))
+	if _, err := failing.Sites.Create(ctx, CreateSiteInput{Name: "Example"}); err == nil || !strings.Contains(err.Error(), "no credentials") {
+		t.Errorf("expected signer error, got %v", err)
+	}
+}
+
+func TestCallInfo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set(RequestIDHeader, "req-123")
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "job-1"})
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL))
+
+	var info CallInfo
+	if _, err := client.Jobs.Get(ContextWithCallInfo(context.Background(), &info), "job-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.Status != http.StatusOK || info.RequestID != "req-123" || info.Attempts != 1 || info.Cached {
+		t.Errorf("unexpected call info: %+v", info)
+	}
+	if info.Header.Get("Cache-Control") != "max-age=60" {
+		t.Errorf("expected response headers, got %v", info.Header)
+	}
+
+	var cached CallInfo
+	if _, err := client.Jobs.Get(ContextWithCallInfo(context.Background(), &cached), "job-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cached.Cached || cached.Attempts != 0 {
+		t.Errorf("expected cached call info, got %+v", cached)
+	}
+}
+
+type timingMetrics struct {
+	NoopMetricsRecorder
+	mu      sync.Mutex
+	timings []RequestTiming
+}
+
+func (m *timingMetrics) RecordRequestTiming(timing RequestTiming) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.timings = append(m.timings, timing)
+}
+
+func TestRequestTiming(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"status": "ok"})
+	}))
+	defer server.Close()
+
+	metrics := &timingMetrics{}
+	client := NewClient("test-key", WithBaseURL(server.URL), WithMetricsRecorder(metrics), WithCacheEnabled(false))
+	for i := 0; i < 2; i++ {
+		if _, err := client.Health(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if len(metrics.timings) != 2 {
+		t.Fatalf("expected 2 timings, got %d", len(metrics.timings))
+	}
+	first, second := metrics.timings[0], metrics.timings[1]
+	if first.Method != http.MethodGet || first.Path != "/api/v1/health" || first.Status != http.StatusOK || first.Attempt != 1 {
+		t.Errorf("unexpected timing: %+v", first)
+	}
+	if first.ReusedConn || !second.ReusedConn {
+		t.Errorf("expected the second request to reuse the connection: %+v, %+v", first, second)
+	}
+	if first.TTFB <= 0 || first.Total < first.TTFB {
+		t.Errorf("expected 0 < TTFB <= Total, got %v and %v", first.TTFB, first.Total)
+	}
+}
+
+func TestMaxResponseSize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"results":"` + strings.Repeat("x", 1024) + `"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL), WithMaxResponseSize(512))
+	_, err := client.Jobs.GetResults(context.Background(), "job-1", nil)
+
+	var tooLarge *ResponseTooLargeError
+	if !errors.As(err, &tooLarge) {
+		t.Fatalf("expected ResponseTooLargeError, got %T: %v", err, err)
+	}
+	if tooLarge.Limit != 512 || tooLarge.Path != "/api/v1/jobs/job-1/results" {
+		t.Errorf("unexpected error: %+v", tooLarge)
+	}
+
+	// Uncached responses are decoded while streaming; the limit still applies.
+	if _, err := client.With(WithCacheEnabled(false)).Jobs.GetResults(context.Background(), "job-1", nil); !errors.As(err, &tooLarge) {
+		t.Errorf("expected ResponseTooLargeError when streaming, got %T: %v", err, err)
+	}
+
+	if _, err := client.With(WithMaxResponseSize(0)).Jobs.GetResults(context.Background(), "job-1", nil); err != nil {
+		t.Errorf("expected no limit, got %v", err)
+	}
+}
+
+func TestRequestOptions(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("X-Trace") != "abc" {
+			t.Errorf("expected X-Trace header, got %q", r.Header.Get("X-Trace"))
+		}
+		if r.URL.Path == "/api/v1/jobs/slow" {
+			time.Sleep(100 * time.Millisecond)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "max-age=60")
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "job-1"})
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL), WithMaxRetries(0))
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Jobs.Get(ctx, "job-1", WithHeader("X-Trace", "abc")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if requests != 1 {
+		t.Fatalf("expected the second call to be cached, got %d requests", requests)
+	}
+	if _, err := client.Jobs.Get(ctx, "job-1", WithHeader("X-Trace", "abc"), WithoutCache()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("expected WithoutCache to reach the API, got %d requests", requests)
+	}
+
+	_, err := client.Jobs.Get(ctx, "slow", WithHeader("X-Trace", "abc"), WithRequestTimeout(10*time.Millisecond))
+	var netErr *NetworkError
+	if !errors.As(err, &netErr) {
+		t.Errorf("expected timeout error, got %v", err)
+	}
+}
+
+func TestGetPageContent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/jobs/job-1/pages/page-7/content" || r.URL.Query().Get("format") != "markdown" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"page_id": "page-7",
+			"url":     "https://example.com/item/7",
+			"format":  "markdown",
+			"content": "# Item 7",
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL))
+	page, err := client.Jobs.GetPageContent(context.Background(), "job-1", "page-7", ContentMarkdown)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if page.Content != "# Item 7" || page.Format != ContentMarkdown {
+		t.Errorf("unexpected page content: %+v", page)
+	}
+}
+
+func TestCredentialProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer rotated-key" {
+			t.Errorf("expected rotated key, got %q", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"status": "ok"})
+	}))
+	defer server.Close()
+
+	key := "rotated-key"
+	client := NewClient("", WithBaseURL(server.URL), WithCacheEnabled(false),
+		WithCredentialProvider(CredentialProviderFunc(func(context.Context) (string, error) {
+			if key == "" {
+				return "", errors.New("vault unavailable")
+			}
+			return key, nil
+		})))
+	if _, err := client.Health(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	key = ""
+	if _, err := client.Health(context.Background()); err == nil || !strings.Contains(err.Error(), "vault unavailable") {
+		t.Errorf("expected provider error, got %v", err)
+	}
+}
+
+func TestCredentialProviderCachePartition(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "s-1", "name": r.Header.Get("Authorization")})
+	}))
+	defer server.Close()
+
+	provider := func(key string) ClientOption {
+		return WithCredentialProvider(CredentialProviderFunc(func(context.Context) (string, error) { return key, nil }))
+	}
+	alice := NewClient("", WithBaseURL(server.URL), provider("alice-key"))
+	bob := alice.With(provider("bob-key"))
+
+	ctx := context.Background()
+	for _, tc := range []struct {
+		client *Client
+		want   string
+	}{{alice, "Bearer alice-key"}, {bob, "Bearer bob-key"}, {alice, "Bearer alice-key"}} {
+		schema, err := tc.client.Schemas.Get(ctx, "s-1")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if schema.Name != tc.want {
+			t.Errorf("expected the response fetched with %q, got %q", tc.want, schema.Name)
+		}
+	}
+}
+
+func TestAnnotateResult(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/jobs/job-1/results/rec-3/feedback" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var body Feedback
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body.Rating != 2 || body.FieldCorrections["price"] != "9.99" {
+			t.Errorf("unexpected feedback: %+v", body)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL))
+	err := client.Jobs.AnnotateResult(context.Background(), "job-1", "rec-3", Feedback{
+		FieldCorrections: map[string]any{"price": "9.99"},
+		Rating:           2,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestGetQualityReport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/jobs/job-1/quality" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"job_id":      "job-1",
+			"pages":       10,
+			"empty_pages": []string{"https://example.com/9"},
+			"fields": []any{
+				map[string]any{"field": "price", "fill_rate": 0.7, "null_percent": 30, "type_mismatches": 2},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL))
+	report, err := client.Jobs.GetQualityReport(context.Background(), "job-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Fields) != 1 || report.Fields[0].TypeMismatches != 2 || len(report.EmptyPages) != 1 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+}
+
+func TestTimeouts(t *testing.T) {
+	var mu sync.Mutex
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		attempts++
+		n := attempts
+		mu.Unlock()
+		if r.URL.Path == "/api/v1/jobs/slow" || (r.URL.Path == "/api/v1/jobs/hang-once" && n == 1) {
+			time.Sleep(100 * time.Millisecond)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "job-1"})
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL), WithCacheEnabled(false), WithTimeout(20*time.Millisecond))
+
+	// A longer caller deadline is not truncated by the client timeout.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := client.Jobs.Get(ctx, "slow"); err != nil {
+		t.Errorf("expected the caller deadline to govern, got %v", err)
+	}
+
+	// Without a caller deadline the timeout applies per attempt, so a hung
+	// attempt is retried.
+	attempts = 0
+	if _, err := client.With(WithMaxRetries(1)).Jobs.Get(context.Background(), "hang-once"); err != nil {
+		t.Errorf("expected the retry to succeed, got %v", err)
+	}
+	if attempts != 2 {
+		t.Errorf("expected 2 attempts, got %d", attempts)
+	}
+
+	// The overall timeout bounds the whole call.
+	overall := client.With(WithPerAttemptTimeout(0), WithOverallTimeout(20*time.Millisecond))
+	var netErr *NetworkError
+	if _, err := overall.Jobs.Get(context.Background(), "slow"); !errors.As(err, &netErr) {
+		t.Errorf("expected a timeout, got %v", err)
+	}
+}
+
+func TestMaxElapsedRetryTime(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/jobs/hang" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"error":"unavailable"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL), WithCacheEnabled(false), WithMaxRetries(5), WithMaxElapsedRetryTime(50*time.Millisecond))
+
+	// The first backoff alone exceeds the budget, so no retry is attempted.
+	start := time.Now()
+	_, err := client.Jobs.Get(context.Background(), "job-1")
+	var budgetErr *RetryBudgetError
+	if !errors.As(err, &budgetErr) || budgetErr.Attempts != 1 {
+		t.Fatalf("expected RetryBudgetError after 1 attempt, got %v", err)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusServiceUnavailable {
+		t.Errorf("expected the last error to be wrapped, got %v", err)
+	}
+
+	// A hung attempt is cut short by the budget.
+	_, err = client.Jobs.Get(context.Background(), "hang")
+	var netErr *NetworkError
+	if !errors.As(err, &budgetErr) || !errors.As(err, &netErr) {
+		t.Errorf("expected a wrapped network error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Errorf("expected the budget to bound the calls, took %v", elapsed)
+	}
+}
+
+func TestAPIPathPrefix(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Header().Set("Cache-Control", "max-age=60")
+		_, _ = w.Write([]byte(`{"total":1}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL))
+	if _, err := client.Jobs.Stats(context.Background(), WithRequestAPIPathPrefix("/api/v2")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The v1 response is cached separately from the v2 one.
+	if _, err := client.Jobs.Stats(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	preview := client.With(WithAPIPathPrefix("/api/v2-preview/"))
+	if _, err := preview.Jobs.Get(context.Background(), "job-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"/api/v2/jobs/stats", "/api/v1/jobs/stats", "/api/v2-preview/jobs/job-1"}
+	if len(paths) != len(want) {
+		t.Fatalf("expected paths %v, got %v", want, paths)
+	}
+	for i := range want {
+		if paths[i] != want[i] {
+			t.Errorf("expected path %s, got %s", want[i], paths[i])
+		}
+	}
+}
+
+func TestDeadlineWouldExceed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"error":"unavailable"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL), WithCacheEnabled(false), WithMaxRetries(3))
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	// The first backoff of a second outlasts the deadline, so the call
+	// fails at once rather than sleeping until the deadline.
+	start := time.Now()
+	_, err := client.Jobs.Get(ctx, "job-1")
+	var deadlineErr *DeadlineWouldExceedError
+	if !errors.As(err, &deadlineErr) || deadlineErr.Attempts != 1 {
+		t.Fatalf("expected DeadlineWouldExceedError after 1 attempt, got %v", err)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusServiceUnavailable {
+		t.Errorf("expected the last error to be wrapped, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 250*time.Millisecond {
+		t.Errorf("expected an immediate failure, took %v", elapsed)
+	}
+}
+
+func TestRetryClassifier(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) == 1 || r.URL.Path == "/api/v1/jobs/conflict" {
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"error":"fetcher busy"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"job-1","status":"running"}`))
+	}))
+	defer server.Close()
+
+	var statuses []int
+	client := NewClient("test-key", WithBaseURL(server.URL), WithCacheEnabled(false), WithMaxRetries(1),
+		WithRetryClassifier(func(status int, err error) bool {
+			statuses = append(statuses, status)
+			var conflict *ConflictError
+			return errors.As(err, &conflict) && conflict.Message == "fetcher busy"
+		}))
+
+	job, err := client.Jobs.Get(context.Background(), "job-1")
+	if err != nil {
+		t.Fatalf("expected the busy response to be retried, got %v", err)
+	}
+	if job.Id != "job-1" || hits.Load() != 2 {
+		t.Errorf("expected 2 requests, got %d", hits.Load())
+	}
+
+	// Retries stop once they are used up.
+	hits.Store(1)
+	var conflict *ConflictError
+	if _, err := client.Jobs.Get(context.Background(), "conflict"); !errors.As(err, &conflict) {
+		t.Fatalf("expected ConflictError, got %v", err)
+	}
+	if len(statuses) != 2 || statuses[0] != http.StatusConflict {
+		t.Errorf("unexpected classifier calls: %v", statuses)
+	}
+}
+
+func TestRetryHook(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"job-1","status":"running"}`))
+	}))
+	defer server.Close()
+
+	var events []RetryEvent
+	client := NewClient("test-key", WithBaseURL(server.URL), WithCacheEnabled(false),
+		WithEndpointRetryOverrides(map[Operation]RetryConfig{OperationRead: {MaxRetries: 1, Backoff: 10 * time.Millisecond}}),
+		WithRetryHook(RetryHookFunc(func(event RetryEvent) { events = append(events, event) })))
+
+	ctx := ContextWithRequestID(context.Background(), "req-1")
+	if _, err := client.Jobs.Get(ctx, "job-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("expected 1 retry event, got %+v", events)
+	}
+	event := events[0]
+	if event.Reason != RetryServerError || event.Status != http.StatusServiceUnavailable || event.Attempt != 1 ||
+		event.Path != "/api/v1/jobs/job-1" || event.RequestID != "req-1" || event.Delay <= 0 || event.Err == nil {
+		t.Errorf("unexpected retry event: %+v", event)
+	}
+}
+
+func TestAlertRules(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/sites":
+			var body CreateSiteInput
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			if len(body.AlertRules) != 1 || body.AlertRules[0].Condition != AlertNullRate {
+				t.Errorf("unexpected alert rules: %+v", body.AlertRules)
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "site-1"})
+		case "/api/v1/jobs/job-1/alerts":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"job_id": "job-1",
+				"failed": true,
+				"results": []any{
+					map[string]any{"rule": map[string]any{"name": "price missing"}, "value": 0.35, "triggered": true},
+					map[string]any{"rule": map[string]any{"name": "empty pages"}, "value": 0.01, "triggered": false},
+				},
+			})
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL))
+	_, err := client.Sites.Create(context.Background(), CreateSiteInput{
+		Name: "Shop",
+		URL:  "https://example.com",
+		AlertRules: []AlertRule{{
+			Name: "price missing", Field: "price", Condition: AlertNullRate, Threshold: 0.2, Action: AlertActionFail,
+		}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	eval, err := client.Jobs.GetAlertEvaluation(context.Background(), "job-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !eval.Failed || len(eval.Triggered()) != 1 || eval.Triggered()[0].Rule.Name != "price missing" {
+		t.Errorf("unexpected evaluation: %+v", eval)
+	}
+}
+
+func TestSiteFieldTrends(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/sites/site-1/trends" || r.URL.Query().Get("field") != "price" || r.URL.Query().Get("period") != "30d" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"site_id": "site-1",
+			"field":   "price",
+			"points": []any{
+				map[string]any{"job_id": "job-1", "timestamp": "2024-01-01T00:00:00Z", "fill_rate": 0.98, "average": 12.5},
+				map[string]any{"job_id": "job-2", "timestamp": "2024-01-02T00:00:00Z", "fill_rate": 0.41},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL))
+	trends, err := client.Sites.FieldTrends(context.Background(), "site-1", "price", TrendPeriod30Days)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(trends.Points) != 2 || trends.Points[0].Average == nil || trends.Points[1].Average != nil {
+		t.Errorf("unexpected trends: %+v", trends)
+	}
+}
+
+func TestReload(t *testing.T) {
+	handler := func(name string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{"status": name + " " + r.Header.Get("Authorization")})
+		})
+	}
+	oldServer := httptest.NewServer(handler("old"))
+	defer oldServer.Close()
+	newServer := httptest.NewServer(handler("new"))
+	defer newServer.Close()
+
+	client := NewClient("old-key", WithBaseURL(oldServer.URL), WithCacheEnabled(false))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.Health(context.Background()); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	client.Reload(&Profile{APIKey: NewSecret("new-key"), BaseURL: newServer.URL, Timeout: time.Minute})
+	wg.Wait()
+
+	health, err := client.Health(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if health.Status != "new Bearer new-key" {
+		t.Errorf("expected reloaded settings, got %q", health.Status)
+	}
+	if s := client.snapshot(); s.timeout != time.Minute || s.maxRetries != DefaultMaxRetries {
+		t.Errorf("expected only set fields to change, got %+v", s)
+	}
+}
+
+func TestIdempotencyKey(t *testing.T) {
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get(IdempotencyKeyHeader))
+		if len(keys) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"job_id": "job-1"})
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL), WithAutoIdempotencyKeys())
+	if _, err := client.Crawl(context.Background(), CrawlInput{URL: "https://example.com"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(keys) != 2 || keys[0] == "" || keys[0] != keys[1] {
+		t.Fatalf("expected the same generated key on both attempts, got %q", keys)
+	}
+
+	keys = keys[:1]
+	if _, err := client.Crawl(context.Background(), CrawlInput{URL: "https://example.com"}, WithIdempotencyKey("order-42")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if keys[1] != "order-42" {
+		t.Errorf("expected explicit key, got %q", keys[1])
+	}
+
+	keys = keys[:1]
+	if _, err := NewClient("test-key", WithBaseURL(server.URL)).Crawl(context.Background(), CrawlInput{URL: "https://example.com"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if keys[1] != "" {
+		t.Errorf("expected no key without auto mode, got %q", keys[1])
+	}
+}
+
+func TestClientWithHostOverride(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"status": "ok"})
+	}))
+	defer server.Close()
+
+	port := strings.TrimPrefix(server.URL, "http://127.0.0.1:")
+	parent := NewClient("test-key",
+		WithBaseURL("http://api.refyne.internal:"+port),
+		WithHostOverride("api.refyne.internal", "192.0.2.1"),
+	)
+	if clone := parent.Clone(); clone.httpClient != parent.httpClient {
+		t.Error("expected clone to share the HTTP client")
+	}
+
+	derived := parent.With(WithHostOverride("api.refyne.internal", "127.0.0.1"))
+	if derived.httpClient == parent.httpClient {
+		t.Fatal("expected a new transport for a changed host override")
+	}
+	if _, err := derived.Health(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if parent.hostOverrides["api.refyne.internal"] != "192.0.2.1" {
+		t.Error("expected parent override to be unchanged")
+	}
+}
+
+func TestCompression(t *testing.T) {
+	var encodings []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encodings = append(encodings, r.Header.Get("Content-Encoding"))
+		body := io.Reader(r.Body)
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				t.Fatalf("invalid gzip body: %v", err)
+			}
+			body = zr
+		}
+		var input ExtractInput
+		if err := json.NewDecoder(body).Decode(&input); err != nil {
+			t.Errorf("failed to decode body: %v", err)
+		}
+		if r.Header.Get("Accept-Encoding") != "gzip" {
+			t.Errorf("expected Accept-Encoding gzip, got %q", r.Header.Get("Accept-Encoding"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+		zw := gzip.NewWriter(w)
+		_ = json.NewEncoder(zw).Encode(map[string]any{"url": input.URL})
+		_ = zw.Close()
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL), WithCompression(1024))
+	large := map[string]any{"description": strings.Repeat("x", 2048)}
+	result, err := client.Extract(context.Background(), ExtractInput{URL: "https://example.com/large", Schema: large})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Url != "https://example.com/large" {
+		t.Errorf("expected decompressed response, got %+v", result)
+	}
+	if _, err := client.Extract(context.Background(), ExtractInput{URL: "https://example.com/small"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(encodings) != 2 || encodings[0] != "gzip" || encodings[1] != "" {
+		t.Errorf("expected only the large body to be compressed, got %q", encodings)
+	}
+}
+
+func TestJobsGetWait(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/jobs/job-1" || r.URL.Query().Get("wait") != "2" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		time.Sleep(100 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "job-1", "status": "completed"})
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL), WithTimeout(50*time.Millisecond), WithMaxRetries(0))
+	job, err := client.Jobs.GetWait(context.Background(), "job-1", 1500*time.Millisecond)
+	if err != nil {
+		t.Fatalf("expected the long poll to outlast the client timeout, got %v", err)
+	}
+	if job.Status != "completed" {
+		t.Errorf("expected completed, got %q", job.Status)
+	}
+}
+
+func TestWireFormat(t *testing.T) {
+	reverse := func(b []byte) []byte {
+		out := make([]byte, len(b))
+		for i, c := range b {
+			out[len(b)-1-i] = c
+		}
+		return out
+	}
+	format := NewWireFormat("application/x-reversed", func(data []byte, v any) error {
+		return json.Unmarshal(reverse(data), v)
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") != "application/x-reversed, application/json;q=0.9" {
+			t.Errorf("unexpected Accept %q", r.Header.Get("Accept"))
+		}
+		body, _ := json.Marshal(map[string]any{"status": "ok", "pages": 2})
+		if r.URL.Path == "/api/v1/health" {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write(body)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-reversed")
+		_, _ = w.Write(reverse(body))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL), WithWireFormat(format))
+	results, err := client.Jobs.GetResults(context.Background(), "job-1", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(results) != `{"pages":2,"status":"ok"}` {
+		t.Errorf("expected results converted to JSON, got %s", results)
+	}
+	health, err := client.Health(context.Background())
+	if err != nil {
+		t.Fatalf("expected JSON fallback, got %v", err)
+	}
+	if health.Status != "ok" {
+		t.Errorf("expected ok, got %q", health.Status)
+	}
+}
+
+func TestWithRegion(t *testing.T) {
+	client := NewClient("test-key", WithRegion(RegionEU))
+	if client.baseURL != "https://eu.api.refyne.uk" {
+		t.Errorf("expected the EU endpoint, got %q", client.baseURL)
+	}
+
+	var region string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		region = r.Header.Get(RegionHeader)
+		_ = json.NewEncoder(w).Encode(map[string]any{"status": "ok"})
+	}))
+	defer server.Close()
+
+	client = NewClient("test-key", WithRegion(RegionUS), WithBaseURL(server.URL))
+	if _, err := client.Health(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if region != "us" {
+		t.Errorf("expected region hint us, got %q", region)
+	}
+}
+
+func TestGeneratedRequestID(t *testing.T) {
+	var sent []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = append(sent, r.Header.Get(RequestIDHeader))
+		if r.URL.Path == "/api/v1/jobs/echo" {
+			w.Header().Set(RequestIDHeader, "server-id")
+		}
+		if len(sent) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]any{"error": "job not found"})
+	}))
+	defer server.Close()
+
+	metrics := &timingMetrics{}
+	client := NewClient("test-key", WithBaseURL(server.URL), WithMaxRetries(1), WithMetricsRecorder(metrics), WithCacheEnabled(false))
+	_, err := client.Jobs.Get(context.Background(), "job-1")
+
+	var notFound *NotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("expected NotFoundError, got %v", err)
+	}
+	if len(sent) != 2 || sent[0] == "" || sent[0] != sent[1] {
+		t.Fatalf("expected one generated ID across retries, got %q", sent)
+	}
+	if ErrorRequestID(err) != sent[0] || notFound.RequestID != sent[0] {
+		t.Errorf("expected the error to carry %q, got %q", sent[0], ErrorRequestID(err))
+	}
+	for _, timing := range metrics.timings {
+		if timing.RequestID != sent[0] {
+			t.Errorf("expected timing request ID %q, got %q", sent[0], timing.RequestID)
+		}
+	}
+
+	_, err = client.Jobs.Get(context.Background(), "echo")
+	if ErrorRequestID(err) != "server-id" {
+		t.Errorf("expected the server's request ID, got %q", ErrorRequestID(err))
+	}
+	if sent[2] == sent[0] {
+		t.Error("expected a new ID per call")
+	}
+}
+
+func TestSimulateChain(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/llm/chain/simulate" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var input SimulationInput
+		_ = json.NewDecoder(r.Body).Decode(&input)
+		if input.EstimatedTokens != 150000 || len(input.RequiredCapabilities) != 1 || input.RequiredCapabilities[0] != ModelCapabilityJSONMode {
+			t.Errorf("unexpected input %+v", input)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"selected": map[string]any{"provider": "anthropic", "model": "claude-3-5-sonnet", "is_enabled": true},
+			"position": 1,
+			"reason":   "first entry with a large enough context window",
+			"skipped": []map[string]any{
+				{"provider": "openai", "model": "gpt-4o-mini", "is_enabled": true, "position": 0, "reason": "context window 128000 < 150000"},
+			},
+			"estimated_cost_usd": 0.45,
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL))
+	sim, err := client.LLM.SimulateChain(context.Background(), SimulationInput{
+		EstimatedTokens:      150000,
+		RequiredCapabilities: []ModelCapability{ModelCapabilityJSONMode},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sim.Selected == nil || sim.Selected.Model != "claude-3-5-sonnet" || sim.Position != 1 {
+		t.Errorf("unexpected selection %+v", sim)
+	}
+	if len(sim.Skipped) != 1 || sim.Skipped[0].Model != "gpt-4o-mini" || sim.Skipped[0].Reason == "" {
+		t.Errorf("unexpected skipped entries %+v", sim.Skipped)
+	}
+}
+
+func TestDeprecationHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "@1767225600")
+		w.Header().Set("Sunset", "Wed, 01 Jul 2026 00:00:00 GMT")
+		w.Header().Set("Link", `<https://docs.refyne.uk/migrate>; rel="deprecation"`)
+		w.Header().Add("Warning", `299 - "use /api/v2/jobs"`)
+		_ = json.NewEncoder(w).Encode(map[string]any{"jobs": []any{}})
+	}))
+	defer server.Close()
+
+	var notices []DeprecationNotice
+	client := NewClient("test-key", WithBaseURL(server.URL), WithCacheEnabled(false),
+		WithDeprecationHandler(func(n DeprecationNotice) { notices = append(notices, n) }))
+	for i := 0; i < 2; i++ {
+		if _, err := client.Jobs.List(context.Background(), &ListOptions{Limit: i + 1}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if len(notices) != 1 {
+		t.Fatalf("expected one notice per endpoint, got %d", len(notices))
+	}
+	n := notices[0]
+	if !n.Deprecated || n.Path != "/api/v1/jobs" || n.Method != http.MethodGet {
+		t.Errorf("unexpected notice %+v", n)
+	}
+	if !n.DeprecatedAt.Equal(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)) || !n.Sunset.Equal(time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected dates %v, %v", n.DeprecatedAt, n.Sunset)
+	}
+	if n.Link != "https://docs.refyne.uk/migrate" || len(n.Warnings) != 1 {
+		t.Errorf("unexpected link or warnings %+v", n)
+	}
+}
+
+func TestWatchProviderWarnings(t *testing.T) {
+	statusCalls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/llm/providers/status" {
+			statusCalls++
+			_ = json.NewEncoder(w).Encode(map[string]any{"providers": []map[string]any{
+				{"provider": "openai", "status": "operational"},
+				{"provider": "anthropic", "status": "degraded", "message": "elevated error rates"},
+			}})
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(
+			"event: result\ndata: {\"id\":\"r-1\",\"url\":\"https://example.com/1\",\"llm_provider\":\"openai\"}\n\n" +
+				"event: result\ndata: {\"id\":\"r-2\",\"url\":\"https://example.com/2\",\"llm_provider\":\"anthropic\"}\n\n" +
+				"event: result\ndata: {\"id\":\"r-3\",\"url\":\"https://example.com/3\",\"llm_provider\":\"anthropic\"}\n\n" +
+				"event: complete\ndata: {\"job_id\":\"job-1\",\"status\":\"completed\"}\n\n"))
+	}))
+	defer server.Close()
+
+	var warnings []ProviderWarning
+	client := NewClient("test-key", WithBaseURL(server.URL), WithCacheEnabled(false),
+		WithProviderWarnings(func(w ProviderWarning) { warnings = append(warnings, w) }))
+	if err := client.Jobs.Watch(context.Background(), "job-1", func(Event) error { return nil }); err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+
+	if len(warnings) != 1 || warnings[0].JobID != "job-1" || warnings[0].Status.Provider != "anthropic" || warnings[0].Status.Status != ProviderDegraded {
+		t.Errorf("expected one warning for anthropic, got %+v", warnings)
+	}
+	if statusCalls != 1 {
+		t.Errorf("expected provider status to be fetched once, got %d", statusCalls)
+	}
+}
+
+func TestDecodingModes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/extract":
+			_, _ = w.Write([]byte(`{"job_id":"job-1","url":"https://example.com","data":{},"metadata":{"final_url":"https://example.com/"},"Billing_Tier":"pro"}`))
+		case "/api/v1/jobs/stats":
+			_, _ = w.Write([]byte(`{"total":3,"by_status":{"completed":3},"archived":1}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL))
+	result, err := client.Extract(context.Background(), ExtractInput{URL: "https://example.com"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.UnknownFields) != 1 || string(result.UnknownFields["Billing_Tier"]) != `"pro"` {
+		t.Errorf("expected only Billing_Tier to be unknown, got %v", result.UnknownFields)
+	}
+	if result.JobId != "job-1" || result.Metadata.FinalURL != "https://example.com/" {
+		t.Errorf("known fields not decoded: %+v", result)
+	}
+
+	// Cached responses are collected the same way.
+	stats, err := client.Jobs.Stats(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.Total != 3 || string(stats.UnknownFields["archived"]) != "1" {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+
+	strict := client.With(WithStrictDecoding(), WithCacheEnabled(false))
+	if _, err := strict.Extract(context.Background(), ExtractInput{URL: "https://example.com"}); err == nil || !strings.Contains(err.Error(), `unknown field "Billing_Tier"`) {
+		t.Errorf("expected unknown field error, got %v", err)
+	}
+	if _, err := strict.Jobs.Stats(context.Background()); err == nil || !strings.Contains(err.Error(), `unknown field "archived"`) {
+		t.Errorf("expected unknown field error, got %v", err)
+	}
+}
+
+func TestCamelCaseFieldNames(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"jobId":"job-1","fetchedAt":"2026-01-02T10:00:00Z","usage":{"costUsd":0.25,"inputTokens":10},"metadata":{"finalURL":"https://example.com/"},"data":{"productName":"Widget"}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL), WithStrictDecoding())
+	result, err := client.Extract(context.Background(), ExtractInput{URL: "https://example.com"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.JobId != "job-1" || result.FetchedAt != "2026-01-02T10:00:00Z" || result.Metadata.FinalURL != "https://example.com/" {
+		t.Errorf("camelCase fields not decoded: %+v", result)
+	}
+	if result.Usage.CostUsd != 0.25 || result.Usage.InputTokens != 10 {
+		t.Errorf("nested camelCase fields not decoded: %+v", result.Usage)
+	}
+	if data, _ := result.Data.(map[string]any); data["productName"] != "Widget" {
+		t.Errorf("expected extracted data to keep its keys, got %v", result.Data)
+	}
+}
+
+func TestDecodeJSONFieldNames(t *testing.T) {
+	client := NewClient("test-key")
+	body := `{"url":"https://example.com/\"q\"\u003c\n","usage":{"cost_usd":0.5,"costUsd":0.1},` +
+		`"data":[{"productName":"Widget","n":1e3}],"fetchedAt":"now","jobID":null,"Billing_Tier":{"planName":["pro"]},"fetched_at":"later"}`
+
+	var result ExtractOutput
+	if err := client.decodeJSON(strings.NewReader(body), &result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Url != "https://example.com/\"q\"<\n" {
+		t.Errorf("expected the string to survive the rewrite, got %q", result.Url)
+	}
+	if result.Usage.CostUsd != 0.5 {
+		t.Errorf("expected the earlier snake_case field to win, got %v", result.Usage.CostUsd)
+	}
+	if result.FetchedAt != "later" {
+		t.Errorf("expected the later snake_case field to win, got %q", result.FetchedAt)
+	}
+	if items, _ := result.Data.([]any); len(items) != 1 || items[0].(map[string]any)["productName"] != "Widget" {
+		t.Errorf("expected extracted data to keep its keys, got %v", result.Data)
+	}
+	if len(result.UnknownFields) != 1 || string(result.UnknownFields["Billing_Tier"]) != `{"planName":["pro"]}` {
+		t.Errorf("expected Billing_Tier to be collected as sent, got %v", result.UnknownFields)
+	}
+
+	var empty JobStats
+	if err := client.decodeJSON(strings.NewReader(""), &empty); !errors.Is(err, io.EOF) {
+		t.Errorf("expected io.EOF for an empty body, got %v", err)
+	}
+	for _, truncated := range []string{`{"total":`, `{"total":3`, `{`} {
+		if err := client.decodeJSON(strings.NewReader(truncated), &empty); err == nil || errors.Is(err, io.EOF) {
+			t.Errorf("expected a parse error for %s, got %v", truncated, err)
+		}
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	for in, want := range map[string]string{"fetchedAt": "fetched_at", "costUsd": "cost_usd", "jobID": "job_id", "finalURL": "final_url", "HTTPStatus": "http_status", "url": "url"} {
+		if got := snakeCase(in); got != want {
+			t.Errorf("snakeCase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestJobQueueETA(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/jobs/job-1" {
+			_, _ = w.Write([]byte(`{"id":"job-1","status":"pending","queue_position":3,"estimated_start_at":"2026-01-02T10:00:00Z","estimated_finish_at":"2026-01-02T10:05:00Z"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(
+			"event: status\ndata: {\"job_id\":\"job-1\",\"status\":\"pending\",\"queue_position\":2,\"estimated_start_at\":\"2026-01-02T10:00:00Z\"}\n\n" +
+				"event: complete\ndata: {\"job_id\":\"job-1\",\"status\":\"completed\"}\n\n"))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL))
+	job, err := client.Jobs.Get(context.Background(), "job-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	start := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	if !job.Queued() || job.QueuePosition != 3 || job.EstimatedStartAt == nil || !job.EstimatedStartAt.Equal(start) ||
+		job.EstimatedFinishAt == nil || !job.EstimatedFinishAt.Equal(start.Add(5*time.Minute)) {
+		t.Errorf("unexpected queue estimate: %+v", job)
+	}
+
+	var status *JobStatusEvent
+	err = client.Jobs.Watch(context.Background(), "job-1", func(event Event) error {
+		if e, ok := event.(*JobStatusEvent); ok {
+			status = e
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	if status == nil || status.Data.QueuePosition != 2 || status.Data.EstimatedStartAt == nil || !status.Data.EstimatedStartAt.Equal(start) {
+		t.Errorf("expected queue position in status event, got %+v", status)
+	}
+}
@@ -0,0 +1,102 @@
+package refyne
+
+import (
+	"context"
+	"fmt"
+)
+
+// CollectionsClient sets up data feeds.
+type CollectionsClient struct {
+	client *Client
+}
+
+// Collection bundles the resources behind one data feed: the site to crawl,
+// the schema to extract with, when to crawl it, where to send notifications
+// and the alert rules checked on every run. The schema, site and schedule
+// are named after the collection and matched by name, so Apply creates them
+// the first time and afterwards only updates what changed.
+type Collection struct {
+	Name      string
+	URL       string
+	FetchMode string
+	// SchemaYAML is the schema the site is extracted with.
+	SchemaYAML string
+	// Cron schedules crawls of the site. If empty the collection has no
+	// schedule.
+	Cron string
+	// Paused keeps the schedule inactive.
+	Paused bool
+	// Delivery is the notification channel for the feed, e.g. from
+	// SlackChannel. Its Name defaults to the collection's.
+	Delivery   *CreateWebhookInput
+	AlertRules []AlertRule
+
+	// The IDs of the collection's resources, set by Apply.
+	SchemaID   string
+	SiteID     string
+	ScheduleID string
+	DeliveryID string
+
+	client *Client
+}
+
+// Define returns col bound to the client, ready to Apply.
+func (c *CollectionsClient) Define(col Collection) *Collection {
+	col.client = c.client
+	return &col
+}
+
+// Spec returns the resources that make up the collection.
+func (col *Collection) Spec() ResourcesSpec {
+	spec := ResourcesSpec{
+		Schemas: []CreateSchemaInput{{Name: col.Name, SchemaYAML: col.SchemaYAML}},
+		Sites: []SiteSpec{{
+			Name:          col.Name,
+			URL:           col.URL,
+			DefaultSchema: col.Name,
+			FetchMode:     col.FetchMode,
+			AlertRules:    col.AlertRules,
+		}},
+	}
+	if col.Cron != "" {
+		spec.Schedules = []ScheduleSpec{{Name: col.Name, Site: col.Name, Cron: col.Cron, IsActive: !col.Paused}}
+	}
+	if col.Delivery != nil {
+		spec.Webhooks = []CreateWebhookInput{col.deliveryInput()}
+	}
+	return spec
+}
+
+func (col *Collection) deliveryInput() CreateWebhookInput {
+	input := *col.Delivery
+	if input.Name == "" {
+		input.Name = col.Name
+	}
+	return input
+}
+
+// Apply creates or updates the collection's resources in one call and sets
+// their IDs on col. Resources in the account that are not part of the
+// collection are left alone. On error the returned plan contains the
+// actions applied so far.
+func (col *Collection) Apply(ctx context.Context) (*SyncPlan, error) {
+	if col.client == nil {
+		return nil, fmt.Errorf("collection %s: not defined with Collections.Define", col.Name)
+	}
+	if col.Name == "" {
+		return nil, &ValidationError{APIError: APIError{Message: "invalid collection"}, Fields: map[string]string{"name": "is required"}}
+	}
+	s := &syncer{client: col.client, opts: &SyncOptions{}, plan: &SyncPlan{}}
+	if err := s.run(ctx, col.Spec()); err != nil {
+		return s.plan, err
+	}
+	col.SchemaID, _, _ = s.resolve(ResourceSchema, col.Name)
+	col.SiteID, _, _ = s.resolve(ResourceSite, col.Name)
+	if col.Cron != "" {
+		col.ScheduleID, _, _ = s.resolve(ResourceSchedule, col.Name)
+	}
+	if col.Delivery != nil {
+		col.DeliveryID, _, _ = s.resolve(ResourceWebhook, col.deliveryInput().Name)
+	}
+	return s.plan, nil
+}
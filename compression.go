@@ -0,0 +1,60 @@
+package refyne
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// DefaultCompressionThreshold is the request body size from which
+// WithCompression gzips bodies when given a threshold of zero.
+const DefaultCompressionThreshold = 8 << 10
+
+// WithCompression gzips request bodies of at least minSize bytes, such as
+// large schemas, and asks for gzip-compressed responses, which are
+// decompressed transparently even when WithHTTPClient supplies a transport
+// that does not. A minSize of zero uses DefaultCompressionThreshold. The
+// response size limit applies to the decompressed body.
+func WithCompression(minSize int) ClientOption {
+	return func(c *Client) {
+		if minSize <= 0 {
+			minSize = DefaultCompressionThreshold
+		}
+		c.compress = true
+		c.compressMin = minSize
+	}
+}
+
+// compressBody gzips body if compression is enabled and body is large
+// enough, reporting whether it did.
+func (c *Client) compressBody(body []byte) ([]byte, bool, error) {
+	if !c.compress || len(body) < c.compressMin {
+		return body, false, nil
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(body); err != nil {
+		return nil, false, fmt.Errorf("failed to compress request body: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, false, fmt.Errorf("failed to compress request body: %w", err)
+	}
+	return buf.Bytes(), true, nil
+}
+
+// responseBody returns the body of resp, decompressing it if the client
+// asked for gzip and the server sent it.
+func (c *Client) responseBody(resp *http.Response) (io.Reader, error) {
+	if !c.compress || resp.Header.Get("Content-Encoding") != "gzip" {
+		return resp.Body, nil
+	}
+	zr, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress response: %w", err)
+	}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	return zr, nil
+}
@@ -0,0 +1,36 @@
+package refyne
+
+import (
+	"context"
+	"sync"
+)
+
+// WithMaxConcurrentRequests bounds the number of API requests in flight at
+// once across all goroutines sharing the client, so large fan-outs need no
+// semaphore of their own. Excess callers queue until a slot frees up or
+// their context is done. A slot is held for a single attempt, not while
+// waiting to retry. Streams and downloads are not counted. Clients derived
+// with With share the limit. An n of zero or less removes it.
+func WithMaxConcurrentRequests(n int) ClientOption {
+	return func(c *Client) {
+		c.slots = nil
+		if n > 0 {
+			c.slots = make(chan struct{}, n)
+		}
+	}
+}
+
+// acquireSlot waits for an in-flight request slot and returns a function
+// that releases it. Releasing more than once is harmless.
+func (c *Client) acquireSlot(ctx context.Context) (func(), error) {
+	if c.slots == nil {
+		return func() {}, nil
+	}
+	select {
+	case c.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	var once sync.Once
+	return func() { once.Do(func() { <-c.slots }) }, nil
+}
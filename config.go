@@ -0,0 +1,158 @@
+package refyne
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Environment variables read by the config loader.
+const (
+	// ConfigEnv overrides the config file path.
+	ConfigEnv = "REFYNE_CONFIG"
+	// ProfileEnv selects the profile when none is named.
+	ProfileEnv = "REFYNE_PROFILE"
+)
+
+// Config is the contents of a Refyne config file:
+//
+//	default_profile: staging
+//	profiles:
+//	  staging:
+//	    api_key: rf_test_...
+//	    base_url: https://staging.api.refyne.uk
+//	  prod:
+//	    api_key: rf_live_...
+//	    timeout: 1m
+//	    crawl_options:
+//	      max_pages: 50
+//	      delay: 1s
+type Config struct {
+	DefaultProfile string              `yaml:"default_profile"`
+	Profiles       map[string]*Profile `yaml:"profiles"`
+}
+
+// Profile is a named set of client settings.
+type Profile struct {
+	APIKey     Secret        `yaml:"api_key"`
+	BaseURL    string        `yaml:"base_url"`
+	Timeout    time.Duration `yaml:"timeout"`
+	MaxRetries *int          `yaml:"max_retries"`
+	// CrawlOptions are used by Crawl when the input has no options. Keys
+	// match the JSON field names of CrawlOptions.
+	CrawlOptions *CrawlOptions `yaml:"-"`
+}
+
+// UnmarshalYAML decodes a profile, reading crawl_options through the JSON
+// field names of CrawlOptions.
+func (p *Profile) UnmarshalYAML(node *yaml.Node) error {
+	type plain Profile
+	var raw struct {
+		plain        `yaml:",inline"`
+		CrawlOptions map[string]any `yaml:"crawl_options"`
+	}
+	if err := node.Decode(&raw); err != nil {
+		return err
+	}
+	*p = Profile(raw.plain)
+	if raw.CrawlOptions != nil {
+		data, err := json.Marshal(normalizeYAML(raw.CrawlOptions))
+		if err != nil {
+			return fmt.Errorf("invalid crawl_options: %w", err)
+		}
+		if err := json.Unmarshal(data, &p.CrawlOptions); err != nil {
+			return fmt.Errorf("invalid crawl_options: %w", err)
+		}
+	}
+	return nil
+}
+
+// DefaultConfigPath returns $REFYNE_CONFIG, or ~/.refyne/config.yaml.
+func DefaultConfigPath() (string, error) {
+	if path := os.Getenv(ConfigEnv); path != "" {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory: %w", err)
+	}
+	return filepath.Join(home, ".refyne", "config.yaml"), nil
+}
+
+// LoadConfig reads a config file. An empty path uses DefaultConfigPath.
+func LoadConfig(path string) (*Config, error) {
+	if path == "" {
+		var err error
+		if path, err = DefaultConfigPath(); err != nil {
+			return nil, err
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// Profile returns the named profile. An empty name uses $REFYNE_PROFILE,
+// then the config's default_profile, then "default".
+func (c *Config) Profile(name string) (*Profile, error) {
+	if name == "" {
+		name = os.Getenv(ProfileEnv)
+	}
+	if name == "" {
+		name = c.DefaultProfile
+	}
+	if name == "" {
+		name = "default"
+	}
+	profile, ok := c.Profiles[name]
+	if !ok || profile == nil {
+		return nil, fmt.Errorf("profile %q not found", name)
+	}
+	return profile, nil
+}
+
+// ClientOptions returns the client options configured by the profile.
+func (p *Profile) ClientOptions() []ClientOption {
+	var opts []ClientOption
+	if p.BaseURL != "" {
+		opts = append(opts, WithBaseURL(p.BaseURL))
+	}
+	if p.Timeout > 0 {
+		opts = append(opts, WithTimeout(p.Timeout))
+	}
+	if p.MaxRetries != nil {
+		opts = append(opts, WithMaxRetries(*p.MaxRetries))
+	}
+	if p.CrawlOptions != nil {
+		opts = append(opts, WithDefaultCrawlOptions(p.CrawlOptions))
+	}
+	return opts
+}
+
+// NewClientFromProfile creates a client from a profile in the default
+// config file. opts are applied after the profile's settings.
+func NewClientFromProfile(name string, opts ...ClientOption) (*Client, error) {
+	cfg, err := LoadConfig("")
+	if err != nil {
+		return nil, err
+	}
+	profile, err := cfg.Profile(name)
+	if err != nil {
+		return nil, err
+	}
+	if profile.APIKey.IsEmpty() {
+		return nil, errors.New("profile has no api_key")
+	}
+	return NewClient(profile.APIKey.Value(), append(profile.ClientOptions(), opts...)...), nil
+}
@@ -0,0 +1,90 @@
+package refyne
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testConfig = `
+default_profile: staging
+profiles:
+  staging:
+    api_key: rf_test_key
+    base_url: https://staging.example.com/
+  prod:
+    api_key: rf_live_key
+    timeout: 1m
+    max_retries: 5
+    crawl_options:
+      max_pages: 50
+      delay: 1s
+`
+
+func writeTestConfig(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(testConfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Setenv(ProfileEnv, "")
+	cfg, err := LoadConfig(writeTestConfig(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	staging, err := cfg.Profile("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if staging.APIKey.Value() != "rf_test_key" {
+		t.Errorf("expected the default profile, got %+v", staging)
+	}
+
+	prod, err := cfg.Profile("prod")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if prod.Timeout != time.Minute || prod.MaxRetries == nil || *prod.MaxRetries != 5 {
+		t.Errorf("unexpected prod profile: %+v", prod)
+	}
+	if prod.CrawlOptions == nil || *prod.CrawlOptions.MaxPages != 50 || *prod.CrawlOptions.Delay != "1s" {
+		t.Errorf("unexpected crawl options: %+v", prod.CrawlOptions)
+	}
+
+	if _, err := cfg.Profile("missing"); err == nil {
+		t.Error("expected an error for a missing profile")
+	}
+}
+
+func TestNewClientFromProfile(t *testing.T) {
+	t.Setenv(ConfigEnv, writeTestConfig(t))
+	t.Setenv(ProfileEnv, "prod")
+
+	client, err := NewClientFromProfile("", WithMaxRetries(1))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if client.apiKey.Value() != "rf_live_key" || client.timeout != time.Minute {
+		t.Errorf("expected the prod profile, got key %q timeout %v", client.apiKey.Value(), client.timeout)
+	}
+	if client.maxRetries != 1 {
+		t.Errorf("expected explicit options to win, got %d retries", client.maxRetries)
+	}
+	if client.crawlOpts == nil {
+		t.Error("expected default crawl options")
+	}
+
+	staging, err := NewClientFromProfile("staging")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if staging.baseURL != "https://staging.example.com" {
+		t.Errorf("unexpected base URL %q", staging.baseURL)
+	}
+}
@@ -0,0 +1,170 @@
+package refyne
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// RequestIDHeader is the header used to correlate a request with server-side logs.
+const RequestIDHeader = "X-Request-ID"
+
+type contextKey int
+
+const (
+	headersContextKey contextKey = iota
+	requestIDContextKey
+	callInfoContextKey
+	requestOptionsContextKey
+	settingsContextKey
+)
+
+// reservedHeaders are set by the SDK itself and cannot be overridden by
+// context or per-call headers.
+var reservedHeaders = map[string]bool{
+	"Authorization": true,
+	"Content-Type":  true,
+	"Accept":        true,
+}
+
+// ContextWithHeader returns a copy of ctx that carries an extra HTTP header.
+// Every SDK request made with the returned context sends the header, which
+// lets values such as tenant or trace identifiers flow from an incoming
+// request through to the Refyne API without threading options through every
+// call. Setting the same key again replaces the earlier value. Responses
+// are cached separately for each set of context headers.
+//
+// Headers the SDK sets itself (Authorization, Content-Type and Accept) and
+// Idempotency-Key, which must differ between calls, are ignored; use
+// WithCredentialProvider or WithIdempotencyKey instead.
+func ContextWithHeader(ctx context.Context, key, value string) context.Context {
+	key = http.CanonicalHeaderKey(key)
+	if reservedHeaders[key] || key == IdempotencyKeyHeader {
+		return ctx
+	}
+	return withContextHeader(ctx, key, value)
+}
+
+// withContextHeader is ContextWithHeader without the check for
+// Idempotency-Key, for per-call options.
+func withContextHeader(ctx context.Context, key, value string) context.Context {
+	headers := headersFromContext(ctx).Clone()
+	if headers == nil {
+		headers = http.Header{}
+	}
+	headers.Set(key, value)
+	return context.WithValue(ctx, headersContextKey, headers)
+}
+
+// ContextWithRequestID returns a copy of ctx that carries a request ID. The
+// SDK sends it in the X-Request-ID header of every request made with the
+// returned context. Without one, each call generates its own ID.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey, id)
+}
+
+// RequestIDFromContext returns the request ID stored by ContextWithRequestID.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDContextKey).(string)
+	return id, ok && id != ""
+}
+
+// CallInfo describes the HTTP response behind a successful SDK call.
+type CallInfo struct {
+	Status int
+	Header http.Header
+	// RequestID is the server's X-Request-ID, or the one sent if the
+	// server did not echo it.
+	RequestID string
+	// Duration covers the whole call, including retries and backoff.
+	Duration time.Duration
+	// Attempts is the number of HTTP requests made; zero for cache hits.
+	Attempts int
+	// Cached reports whether the response was served from the cache.
+	Cached bool
+}
+
+// ContextWithCallInfo returns a copy of ctx that records response metadata
+// into info. Each successful SDK call made with the returned context
+// overwrites info, so use a separate context per call:
+//
+//	var info refyne.CallInfo
+//	job, err := client.Jobs.Get(refyne.ContextWithCallInfo(ctx, &info), id)
+//	log.Println(info.RequestID, info.Duration)
+func ContextWithCallInfo(ctx context.Context, info *CallInfo) context.Context {
+	return context.WithValue(ctx, callInfoContextKey, info)
+}
+
+// recordCallInfo fills the CallInfo carried by ctx, if any.
+func recordCallInfo(ctx context.Context, resp *response, d time.Duration) {
+	info, _ := ctx.Value(callInfoContextKey).(*CallInfo)
+	if info == nil {
+		return
+	}
+	*info = CallInfo{
+		Status:    resp.status,
+		Header:    resp.header,
+		RequestID: resp.header.Get(RequestIDHeader),
+		Duration:  d,
+		Attempts:  resp.attempts,
+		Cached:    resp.cached,
+	}
+	if info.RequestID == "" {
+		info.RequestID, _ = RequestIDFromContext(ctx)
+	}
+}
+
+// headersFromContext returns the headers stored by ContextWithHeader, or nil.
+func headersFromContext(ctx context.Context) http.Header {
+	headers, _ := ctx.Value(headersContextKey).(http.Header)
+	return headers
+}
+
+// applyContextHeaders copies the headers carried by ctx onto req, except
+// those reserved for the SDK.
+func applyContextHeaders(ctx context.Context, req *http.Request) {
+	for key, values := range headersFromContext(ctx) {
+		if reservedHeaders[key] {
+			continue
+		}
+		req.Header[key] = append([]string(nil), values...)
+	}
+	if id, ok := RequestIDFromContext(ctx); ok {
+		req.Header.Set(RequestIDHeader, id)
+	}
+}
+
+// contextHeadersKey identifies the headers carried by ctx for the cache
+// key, or returns "" if there are none. Idempotency-Key is left out as it
+// identifies the call rather than what the response depends on.
+func contextHeadersKey(ctx context.Context) string {
+	headers := headersFromContext(ctx)
+	keys := make([]string, 0, len(headers))
+	for key := range headers {
+		if !reservedHeaders[key] && key != IdempotencyKeyHeader {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+	sort.Strings(keys)
+	h := sha256.New()
+	for _, key := range keys {
+		fmt.Fprintf(h, "%s: %q\n", key, headers[key])
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// withCallRequestID returns ctx with a generated request ID unless it
+// already carries one, so every attempt of a call shares an ID.
+func withCallRequestID(ctx context.Context) context.Context {
+	if _, ok := RequestIDFromContext(ctx); ok {
+		return ctx
+	}
+	return ContextWithRequestID(ctx, newUUID())
+}
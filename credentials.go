@@ -0,0 +1,38 @@
+package refyne
+
+import "context"
+
+// CredentialProvider supplies the API key for each request, so it can be
+// fetched from a secret store such as Vault or AWS Secrets Manager and
+// rotated without recreating the client. Implementations should cache the
+// key; Token is called for every request attempt and must be safe for
+// concurrent use.
+type CredentialProvider interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// CredentialProviderFunc adapts a function to a CredentialProvider.
+type CredentialProviderFunc func(ctx context.Context) (string, error)
+
+// Token implements CredentialProvider.
+func (f CredentialProviderFunc) Token(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// WithCredentialProvider fetches the API key from provider instead of using
+// the key passed to NewClient. Cached responses are partitioned by the key
+// the provider returns, so clients with different providers can share a
+// cache.
+func WithCredentialProvider(provider CredentialProvider) ClientOption {
+	return func(c *Client) {
+		c.credentials = provider
+	}
+}
+
+// token returns the API key for a request.
+func (c *Client) token(ctx context.Context) (string, error) {
+	if c.credentials == nil {
+		return c.settings(ctx).apiKey.Value(), nil
+	}
+	return c.credentials.Token(ctx)
+}
@@ -0,0 +1,358 @@
+package refyne
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// UnknownFields holds top-level response fields that this version of the
+// SDK does not know about, keyed by their JSON name, so data added to the
+// API is not silently dropped. It is embedded in the SDK's response types
+// and is nil when there are none.
+type UnknownFields map[string]json.RawMessage
+
+func (u *UnknownFields) setUnknownFields(fields map[string]json.RawMessage) {
+	*u = fields
+}
+
+// unknownFieldsCollector is implemented by response types embedding
+// UnknownFields.
+type unknownFieldsCollector interface {
+	setUnknownFields(map[string]json.RawMessage)
+}
+
+// WithStrictDecoding makes JSON responses with fields the SDK does not know
+// about fail to decode, so drift between the SDK and the API is caught in
+// tests. By default such fields are ignored, or kept in UnknownFields by
+// the response types that embed it.
+func WithStrictDecoding() ClientOption {
+	return func(c *Client) {
+		c.strictDecoding = true
+	}
+}
+
+// decodeJSON decodes a JSON value from r into result, rejecting unknown
+// fields in strict mode and otherwise collecting them if result embeds
+// UnknownFields. Field names sent in camelCase are normalized as the value
+// is decoded, see fieldDecoder, so the response is still read in a single
+// pass without being buffered.
+func (c *Client) decodeJSON(r io.Reader, result any) error {
+	v := reflect.ValueOf(result)
+	dec := json.NewDecoder(r)
+	if c.strictDecoding {
+		dec.DisallowUnknownFields()
+	}
+	if v.Kind() != reflect.Pointer || v.IsNil() || !hasFieldNames(v.Type()) {
+		return dec.Decode(result)
+	}
+
+	d := &fieldDecoder{dec: dec, strict: c.strictDecoding}
+	collector, collect := result.(unknownFieldsCollector)
+	if collect && !c.strictDecoding {
+		d.unknown = map[string]json.RawMessage{}
+	}
+	if err := d.value(v.Elem(), d.unknown); err != nil {
+		if errors.Is(err, io.EOF) && d.started {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	if len(d.unknown) > 0 {
+		collector.setUnknownFields(d.unknown)
+	}
+	return nil
+}
+
+// fieldDecoder decodes a JSON value into the SDK's types, accepting
+// camelCase object keys, such as "fetchedAt" or "costUsd", for the
+// snake_case names of the SDK's fields, such as "fetched_at" and
+// "cost_usd", so responses in either convention decode without silently
+// dropping fields. It walks the tokens of SDK structs and the slices and
+// maps holding them, and leaves every other value, such as extracted data,
+// to encoding/json as it is. A camelCase key whose snake_case form came
+// earlier in the same object is treated as unknown; one that comes later
+// replaces the camelCase key's value, as duplicate keys do.
+type fieldDecoder struct {
+	dec    *json.Decoder
+	strict bool
+	// unknown collects the top-level fields that are not fields of the
+	// result's type, if it embeds UnknownFields.
+	unknown map[string]json.RawMessage
+	// started is set once the first token has been read, after which the
+	// end of the input is unexpected.
+	started bool
+}
+
+// value decodes the next value into v. unknown, if not nil, collects the
+// value's unknown fields when it is an object.
+func (d *fieldDecoder) value(v reflect.Value, unknown map[string]json.RawMessage) error {
+	if !hasFieldNames(v.Type()) {
+		return d.dec.Decode(v.Addr().Interface())
+	}
+	tok, err := d.dec.Token()
+	if err != nil {
+		return err
+	}
+	d.started = true
+	if tok == nil {
+		// As encoding/json does, null clears pointers, slices and maps
+		// and leaves other values unchanged.
+		switch v.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Map:
+			v.Set(reflect.Zero(v.Type()))
+		}
+		return nil
+	}
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+
+	switch {
+	case tok == json.Delim('{') && v.Kind() == reflect.Struct:
+		return d.object(v, unknown)
+	case tok == json.Delim('{') && v.Kind() == reflect.Map:
+		return d.mapEntries(v)
+	case tok == json.Delim('[') && (v.Kind() == reflect.Slice || v.Kind() == reflect.Array):
+		return d.array(v)
+	}
+	kind := "value"
+	switch tok := tok.(type) {
+	case json.Delim:
+		kind = map[json.Delim]string{'{': "object", '[': "array"}[tok]
+	case string:
+		kind = "string"
+	case float64:
+		kind = "number"
+	case bool:
+		kind = "bool"
+	}
+	return &json.UnmarshalTypeError{Value: kind, Type: v.Type(), Offset: d.dec.InputOffset()}
+}
+
+// object decodes the fields of an object, whose '{' has been read, into
+// the struct v.
+func (d *fieldDecoder) object(v reflect.Value, unknown map[string]json.RawMessage) error {
+	fields := knownFields(v.Type())
+	seen := map[string]bool{}
+	for d.dec.More() {
+		tok, err := d.dec.Token()
+		if err != nil {
+			return err
+		}
+		name, _ := tok.(string)
+		lower := strings.ToLower(name)
+		f, ok := fields[lower]
+		if !ok {
+			if snake := snakeCase(name); !seen[snake] {
+				f, ok = fields[snake]
+				lower = snake
+			}
+		}
+		if !ok {
+			if err := d.unknownField(name, unknown); err != nil {
+				return err
+			}
+			continue
+		}
+		seen[lower] = true
+		if err := d.value(fieldByIndex(v, f.index), nil); err != nil {
+			return err
+		}
+	}
+	_, err := d.dec.Token()
+	return err
+}
+
+// unknownField reads the value of an unknown field, collecting it in
+// unknown if that is not nil or failing in strict mode.
+func (d *fieldDecoder) unknownField(name string, unknown map[string]json.RawMessage) error {
+	if d.strict {
+		return fmt.Errorf("json: unknown field %q", name)
+	}
+	var raw json.RawMessage
+	if err := d.dec.Decode(&raw); err != nil {
+		return err
+	}
+	if unknown != nil {
+		unknown[name] = raw
+	}
+	return nil
+}
+
+// mapEntries decodes the entries of an object, whose '{' has been read,
+// into the map v.
+func (d *fieldDecoder) mapEntries(v reflect.Value) error {
+	if v.IsNil() {
+		v.Set(reflect.MakeMap(v.Type()))
+	}
+	for d.dec.More() {
+		tok, err := d.dec.Token()
+		if err != nil {
+			return err
+		}
+		key := reflect.New(v.Type().Key()).Elem()
+		if key.Kind() != reflect.String {
+			return &json.UnmarshalTypeError{Value: "object", Type: v.Type(), Offset: d.dec.InputOffset()}
+		}
+		key.SetString(tok.(string))
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if err := d.value(elem, nil); err != nil {
+			return err
+		}
+		v.SetMapIndex(key, elem)
+	}
+	_, err := d.dec.Token()
+	return err
+}
+
+// array decodes the items of an array, whose '[' has been read, into the
+// slice or array v.
+func (d *fieldDecoder) array(v reflect.Value) error {
+	i := 0
+	for ; d.dec.More(); i++ {
+		if v.Kind() == reflect.Slice {
+			if i == v.Len() {
+				v.Set(reflect.Append(v, reflect.Zero(v.Type().Elem())))
+			}
+		} else if i >= v.Len() {
+			var discard json.RawMessage
+			if err := d.dec.Decode(&discard); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := d.value(v.Index(i), nil); err != nil {
+			return err
+		}
+	}
+	switch {
+	case v.Kind() == reflect.Slice && v.IsNil():
+		v.Set(reflect.MakeSlice(v.Type(), 0, 0))
+	case v.Kind() == reflect.Slice:
+		v.SetLen(i)
+	default:
+		for ; i < v.Len(); i++ {
+			v.Index(i).Set(reflect.Zero(v.Type().Elem()))
+		}
+	}
+	_, err := d.dec.Token()
+	return err
+}
+
+// fieldByIndex returns the field of struct v at index, allocating any
+// nil embedded struct pointers on the way.
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+// knownFieldsCache maps struct types to their fields by lowercased JSON
+// name.
+var knownFieldsCache sync.Map
+
+// knownField is a field of a struct type decoded from JSON.
+type knownField struct {
+	index []int
+}
+
+// knownFields returns the fields of a struct type by lowercased JSON name,
+// including promoted fields, matching encoding/json's case-insensitive
+// field lookup.
+func knownFields(t reflect.Type) map[string]knownField {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if known, ok := knownFieldsCache.Load(t); ok {
+		return known.(map[string]knownField)
+	}
+	known := make(map[string]knownField)
+	if t.Kind() == reflect.Struct {
+		addKnownFields(known, t, nil)
+	}
+	knownFieldsCache.Store(t, known)
+	return known
+}
+
+func addKnownFields(known map[string]knownField, t reflect.Type, index []int) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		ft := f.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		fieldIndex := append(index[:len(index):len(index)], i)
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			addKnownFields(known, ft, fieldIndex)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		lower := strings.ToLower(name)
+		if existing, ok := known[lower]; ok && len(existing.index) <= len(fieldIndex) {
+			// The shallower field wins, as in encoding/json.
+			continue
+		}
+		known[lower] = knownField{index: fieldIndex}
+	}
+}
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// hasFieldNames reports whether values of t may contain SDK structs whose
+// field names fieldDecoder normalizes.
+func hasFieldNames(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		return !reflect.PointerTo(t).Implements(jsonUnmarshalerType)
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return hasFieldNames(t.Elem())
+	}
+	return false
+}
+
+// snakeCase converts a camelCase name to lowercase snake_case, keeping
+// acronyms together: "costUsd" becomes "cost_usd" and "jobID" "job_id".
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
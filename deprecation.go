@@ -0,0 +1,118 @@
+package refyne
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DeprecationNotice describes deprecation headers returned by the API for
+// an endpoint.
+type DeprecationNotice struct {
+	Method string
+	Path   string
+	// Deprecated is set by a Deprecation header.
+	Deprecated bool
+	// DeprecatedAt is when the endpoint was or will be deprecated, if the
+	// Deprecation header gives a date.
+	DeprecatedAt time.Time
+	// Sunset is when the endpoint will stop working, from the Sunset header.
+	Sunset time.Time
+	// Link points to documentation about the deprecation, if any.
+	Link string
+	// Warnings are the values of Warning headers.
+	Warnings []string
+}
+
+// WithDeprecationHandler calls fn the first time the API reports a
+// Deprecation, Sunset or Warning header for an endpoint. Notices are logged
+// at warn level whether or not a handler is set.
+func WithDeprecationHandler(fn func(DeprecationNotice)) ClientOption {
+	return func(c *Client) {
+		c.onDeprecation = fn
+	}
+}
+
+// checkDeprecation reports the deprecation headers of a response, once per
+// endpoint.
+func (c *Client) checkDeprecation(method, path string, header http.Header) {
+	notice, ok := parseDeprecation(header)
+	if !ok {
+		return
+	}
+	notice.Method = method
+	notice.Path, _, _ = strings.Cut(path, "?")
+	if _, seen := c.deprecations.LoadOrStore(method+" "+notice.Path, struct{}{}); seen {
+		return
+	}
+
+	fields := map[string]any{
+		"method": notice.Method,
+		"path":   notice.Path,
+	}
+	if !notice.DeprecatedAt.IsZero() {
+		fields["deprecated_at"] = notice.DeprecatedAt
+	}
+	if !notice.Sunset.IsZero() {
+		fields["sunset"] = notice.Sunset
+	}
+	if notice.Link != "" {
+		fields["link"] = notice.Link
+	}
+	if len(notice.Warnings) > 0 {
+		fields["warnings"] = notice.Warnings
+	}
+	c.logger.Warn("API deprecation notice", fields)
+	if c.onDeprecation != nil {
+		c.onDeprecation(notice)
+	}
+}
+
+// parseDeprecation reads the Deprecation, Sunset, Warning and Link headers.
+func parseDeprecation(header http.Header) (DeprecationNotice, bool) {
+	var n DeprecationNotice
+	if value := strings.TrimSpace(header.Get("Deprecation")); value != "" {
+		n.Deprecated = true
+		n.DeprecatedAt = parseHeaderDate(value)
+	}
+	if value := header.Get("Sunset"); value != "" {
+		n.Sunset = parseHeaderDate(value)
+	}
+	n.Warnings = header.Values("Warning")
+	if !n.Deprecated && n.Sunset.IsZero() && len(n.Warnings) == 0 {
+		return n, false
+	}
+	n.Link = deprecationLink(header.Values("Link"))
+	return n, true
+}
+
+// parseHeaderDate parses an HTTP-date or a structured field date such as
+// "@1688169599". Other values, such as "true", give the zero time.
+func parseHeaderDate(value string) time.Time {
+	if unix, ok := strings.CutPrefix(value, "@"); ok {
+		if secs, err := strconv.ParseInt(unix, 10, 64); err == nil {
+			return time.Unix(secs, 0).UTC()
+		}
+		return time.Time{}
+	}
+	t, _ := http.ParseTime(value)
+	return t
+}
+
+// deprecationLink returns the target of a Link with rel="deprecation" or
+// rel="sunset".
+func deprecationLink(links []string) string {
+	for _, header := range links {
+		for _, link := range strings.Split(header, ",") {
+			target, params, _ := strings.Cut(link, ";")
+			for _, param := range strings.Split(params, ";") {
+				name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+				if rel := strings.Trim(value, `"`); name == "rel" && (rel == "deprecation" || rel == "sunset") {
+					return strings.Trim(strings.TrimSpace(target), "<>")
+				}
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,233 @@
+package refyne
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultDiskCacheMaxBytes is the size limit of a DiskCache created with a
+// maxBytes of zero.
+const DefaultDiskCacheMaxBytes = 64 << 20
+
+// diskCacheExt is the extension of DiskCache entry files.
+const diskCacheExt = ".entry"
+
+// DiskCache is a Cache that keeps each entry in a file in a directory, so
+// CLI tools and other short-lived processes reuse cached responses, such as
+// provider, model and schema lists, across runs. Entries are written
+// atomically and checksummed; one that cannot be read back intact is
+// treated as a miss and removed. When the entries outgrow the size limit
+// the least recently used are evicted. Write errors are ignored, as the
+// cache is only an optimization.
+type DiskCache struct {
+	dir      string
+	maxBytes int64
+
+	mu      sync.Mutex
+	files   map[string]diskCacheFile
+	size    int64
+	stats   CacheStats
+	onEvict func(name string)
+}
+
+// diskCacheFile is the index entry for an entry file.
+type diskCacheFile struct {
+	size int64
+	used time.Time
+}
+
+// diskCacheRecord is the content of an entry file after its checksum line.
+type diskCacheRecord struct {
+	Key   string      `json:"key"`
+	Entry *CacheEntry `json:"entry"`
+}
+
+// NewDiskCache creates a DiskCache in dir holding at most maxBytes of
+// entries, creating the directory if needed. Entries already in dir are
+// kept.
+func NewDiskCache(dir string, maxBytes int64) (*DiskCache, error) {
+	if maxBytes <= 0 {
+		maxBytes = DefaultDiskCacheMaxBytes
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &DiskCache{dir: dir, maxBytes: maxBytes, files: make(map[string]diskCacheFile)}
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), diskCacheExt) {
+			continue
+		}
+		info, err := file.Info()
+		if err != nil {
+			continue
+		}
+		d.files[file.Name()] = diskCacheFile{size: info.Size(), used: info.ModTime()}
+		d.size += info.Size()
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.evict()
+	return d, nil
+}
+
+// name returns the file name for key; keys are hashed as they contain URLs.
+func (d *DiskCache) name(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:]) + diskCacheExt
+}
+
+// Get returns the entry for key if it is intact and still usable.
+func (d *DiskCache) Get(key string) (*CacheEntry, bool) {
+	name := d.name(key)
+	data, err := os.ReadFile(filepath.Join(d.dir, name))
+	if err != nil {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		d.stats.Misses++
+		return nil, false
+	}
+	record, ok := decodeDiskCacheRecord(data)
+	if !ok || record.Key != key || !record.Entry.Usable(time.Now()) {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		d.remove(name)
+		d.stats.Misses++
+		return nil, false
+	}
+
+	now := time.Now()
+	_ = os.Chtimes(filepath.Join(d.dir, name), now, now)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.stats.Hits++
+	if file, ok := d.files[name]; ok {
+		file.used = now
+		d.files[name] = file
+	}
+	return record.Entry, true
+}
+
+// Set writes entry under key, evicting the least recently used entries if
+// the cache grows past its size limit.
+func (d *DiskCache) Set(key string, entry *CacheEntry) {
+	payload, err := json.Marshal(diskCacheRecord{Key: key, Entry: entry})
+	if err != nil {
+		return
+	}
+	sum := sha256.Sum256(payload)
+	data := append([]byte(hex.EncodeToString(sum[:])+"\n"), payload...)
+	if int64(len(data)) > d.maxBytes {
+		return
+	}
+
+	name := d.name(key)
+	if err := writeFileAtomic(d.dir, name, data); err != nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.size += int64(len(data)) - d.files[name].size
+	d.files[name] = diskCacheFile{size: int64(len(data)), used: time.Now()}
+	d.evict()
+}
+
+// Delete removes the entry for key.
+func (d *DiskCache) Delete(key string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.remove(d.name(key))
+}
+
+// Stats returns the cache's statistics. Hits and misses are counted since
+// the cache was created; entries and size include those from earlier runs.
+func (d *DiskCache) Stats() CacheStats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	stats := d.stats
+	stats.Entries = len(d.files)
+	stats.SizeBytes = d.size
+	return stats
+}
+
+// OnEvict sets a function called with the file name of each entry evicted
+// to make room; entries are stored under a hash of their key, which is not
+// known for entries from earlier runs. It is called with the cache locked
+// and must not use the cache.
+func (d *DiskCache) OnEvict(fn func(name string)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.onEvict = fn
+}
+
+// remove deletes the entry file name. The caller must hold d.mu.
+func (d *DiskCache) remove(name string) {
+	_ = os.Remove(filepath.Join(d.dir, name))
+	d.size -= d.files[name].size
+	delete(d.files, name)
+}
+
+// evict removes the least recently used entries until the cache fits its
+// size limit. The caller must hold d.mu.
+func (d *DiskCache) evict() {
+	for d.size > d.maxBytes && len(d.files) > 0 {
+		var oldest string
+		for name, file := range d.files {
+			if oldest == "" || file.used.Before(d.files[oldest].used) {
+				oldest = name
+			}
+		}
+		d.remove(oldest)
+		d.stats.Evictions++
+		if d.onEvict != nil {
+			d.onEvict(oldest)
+		}
+	}
+}
+
+// decodeDiskCacheRecord verifies an entry file's checksum and decodes it.
+func decodeDiskCacheRecord(data []byte) (*diskCacheRecord, bool) {
+	sumHex, payload, ok := bytes.Cut(data, []byte("\n"))
+	if !ok {
+		return nil, false
+	}
+	sum := sha256.Sum256(payload)
+	if string(sumHex) != hex.EncodeToString(sum[:]) {
+		return nil, false
+	}
+	var record diskCacheRecord
+	if err := json.Unmarshal(payload, &record); err != nil || record.Entry == nil {
+		return nil, false
+	}
+	return &record, true
+}
+
+// writeFileAtomic writes data to name in dir through a temporary file, so
+// readers never see a partial file.
+func writeFileAtomic(dir, name string, data []byte) error {
+	tmp, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(dir, name))
+}
@@ -0,0 +1,261 @@
+package refyne
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ContentSHA256Header carries the hex SHA-256 of a download's content.
+const ContentSHA256Header = "X-Content-SHA256"
+
+// DownloadOptions contains options for Jobs.DownloadResults.
+type DownloadOptions struct {
+	// Merge downloads the merged results instead of per-page results.
+	Merge bool
+	// Gzip requests gzip-compressed results and writes them to w still
+	// compressed.
+	Gzip bool
+	// Offset is the number of bytes already written by an earlier
+	// download, which is resumed from there.
+	Offset int64
+	// SkipChecksum disables checksum verification.
+	SkipChecksum bool
+}
+
+// DownloadResults streams the raw results of a job to w and returns the
+// number of bytes written, including opts.Offset. Interrupted transfers are
+// resumed with Range requests up to the client's retry limit. The client
+// timeout does not apply; use ctx to bound the download.
+//
+// When the server provides a SHA-256 checksum, in the X-Content-SHA256
+// header or as a strong ETag, the content is verified and an
+// *IntegrityError is returned on mismatch. Verification is skipped for
+// gzip downloads and when resuming from opts.Offset, since the hash of the
+// earlier bytes is unknown.
+func (j *JobsClient) DownloadResults(ctx context.Context, id string, w io.Writer, opts *DownloadOptions, reqOpts ...RequestOption) (int64, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
+	if opts == nil {
+		opts = &DownloadOptions{}
+	}
+	path := "/api/v1/jobs/" + id + "/results"
+	if opts.Merge {
+		path += "?merge=true"
+	}
+	return j.download(ctx, path, w, opts)
+}
+
+// GetResultsReader returns the raw JSON results of a job as a stream, for
+// callers that decode them incrementally instead of holding them in memory.
+// The caller must close the reader. Unlike GetResults, the request is not
+// retried or cached, the response size limit does not apply, and the client
+// timeout does not apply; use ctx to bound it.
+func (j *JobsClient) GetResultsReader(ctx context.Context, id string, opts *ResultsOptions, reqOpts ...RequestOption) (io.ReadCloser, error) {
+	ctx = withCallRequestID(withRequestOptions(ctx, reqOpts))
+	requestID, _ := RequestIDFromContext(ctx)
+	path := "/api/v1/jobs/" + id + "/results"
+	if opts != nil && opts.Merge {
+		path += "?merge=true"
+	}
+
+	req, err := j.client.newRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := j.client.httpClient.Do(req)
+	if err != nil {
+		return nil, &NetworkError{Err: err, RequestID: requestID}
+	}
+	if resp.StatusCode >= 400 {
+		defer func() { _ = resp.Body.Close() }()
+		body, _ := j.client.readBody(resp.Body, path)
+		return nil, withRequestID(j.client.responseError(resp, body), requestID)
+	}
+	return resp.Body, nil
+}
+
+// ArchiveFormat is the container format of an exported archive.
+type ArchiveFormat string
+
+// Archive formats supported by ExportArchive.
+const (
+	ArchiveZip   ArchiveFormat = "zip"
+	ArchiveTarGz ArchiveFormat = "tar.gz"
+)
+
+// ExportArchive streams a compressed archive of a job's results to w. The
+// archive holds one JSON file per page plus a manifest.json describing
+// them. Downloads resume and are verified like DownloadResults; opts.Merge
+// and opts.Gzip are ignored.
+func (j *JobsClient) ExportArchive(ctx context.Context, id string, format ArchiveFormat, w io.Writer, opts *DownloadOptions, reqOpts ...RequestOption) (int64, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
+	archiveOpts := DownloadOptions{}
+	if opts != nil {
+		archiveOpts = *opts
+	}
+	archiveOpts.Merge, archiveOpts.Gzip = false, false
+	path := "/api/v1/jobs/" + id + "/export?format=" + url.QueryEscape(string(format))
+	return j.download(ctx, path, w, &archiveOpts)
+}
+
+// download streams path to w, resuming interrupted transfers and verifying
+// the server's checksum.
+func (j *JobsClient) download(ctx context.Context, path string, w io.Writer, opts *DownloadOptions) (written int64, err error) {
+	ctx = withCallRequestID(ctx)
+	requestID, _ := RequestIDFromContext(ctx)
+	verify := !opts.SkipChecksum && !opts.Gzip && opts.Offset == 0
+	hash := sha256.New()
+	if verify {
+		w = io.MultiWriter(w, hash)
+	}
+
+	written = opts.Offset
+	if progress := requestOptionsFromContext(ctx).progress; progress != nil {
+		w = &progressWriter{w: w, reporter: progress, progress: Progress{Current: written, Unit: ProgressBytes}}
+		defer func() { progress.Done(err) }()
+	}
+
+	var checksum string
+	for attempt := 1; ; attempt++ {
+		n, sum, err := j.downloadRange(ctx, path, w, written, opts.Gzip)
+		written += n
+		if checksum == "" {
+			checksum = sum
+		}
+		if err == nil {
+			if verify && checksum != "" {
+				if actual := hex.EncodeToString(hash.Sum(nil)); actual != checksum {
+					return written, &IntegrityError{Path: path, Expected: checksum, Actual: actual}
+				}
+			}
+			return written, nil
+		}
+
+		var interrupted *interruptedDownloadError
+		if !errors.As(err, &interrupted) || attempt > j.client.settings(ctx).maxRetries || ctx.Err() != nil {
+			if ctx.Err() != nil {
+				return written, &NetworkError{Err: ctx.Err(), RequestID: requestID}
+			}
+			return written, withRequestID(err, requestID)
+		}
+		backoff := j.client.calculateBackoff(attempt)
+		j.client.logger.Warn("Download interrupted, resuming", map[string]any{
+			"error":      interrupted.Err.Error(),
+			"offset":     written,
+			"attempt":    attempt,
+			"backoff":    backoff,
+			"request_id": requestID,
+		})
+		j.client.reportRetry(RetryEvent{Method: http.MethodGet, Path: path, Attempt: attempt, Delay: backoff, Reason: RetryDownloadInterrupted, Err: interrupted.Err, RequestID: requestID})
+		if err := j.client.sleepWithContext(ctx, backoff); err != nil {
+			return written, &NetworkError{Err: err, RequestID: requestID}
+		}
+	}
+}
+
+// interruptedDownloadError marks a download failure that can be resumed.
+type interruptedDownloadError struct {
+	Err error
+}
+
+func (e *interruptedDownloadError) Error() string {
+	return fmt.Sprintf("download interrupted: %v", e.Err)
+}
+
+func (e *interruptedDownloadError) Unwrap() error {
+	return e.Err
+}
+
+// downloadRange copies path to w starting at offset, returning the number
+// of bytes written and the server's checksum, if any.
+func (j *JobsClient) downloadRange(ctx context.Context, path string, w io.Writer, offset int64, gzip bool) (int64, string, error) {
+	req, err := j.client.newRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Accept", "*/*")
+	if gzip {
+		req.Header.Set("Accept-Encoding", "gzip")
+	}
+	if offset > 0 {
+		req.Header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-")
+	}
+
+	resp, err := j.client.httpClient.Do(req)
+	if err != nil {
+		return 0, "", &interruptedDownloadError{Err: err}
+	}
+	defer func() { _ = resp.Body.Close() }()
+	sum := responseChecksum(resp.Header)
+
+	switch {
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		// Everything was already written.
+		return 0, sum, nil
+	case resp.StatusCode >= 500:
+		body, _ := j.client.readBody(resp.Body, path)
+		return 0, "", &interruptedDownloadError{Err: j.client.responseError(resp, body)}
+	case resp.StatusCode >= 400:
+		body, _ := j.client.readBody(resp.Body, path)
+		return 0, "", j.client.responseError(resp, body)
+	case resp.StatusCode == http.StatusOK && offset > 0:
+		// The server ignored the Range header; skip what we already have.
+		if _, err := io.CopyN(io.Discard, resp.Body, offset); err != nil {
+			return 0, sum, &interruptedDownloadError{Err: err}
+		}
+	}
+
+	if pw, ok := w.(*progressWriter); ok && resp.ContentLength >= 0 {
+		pw.progress.Total = resp.ContentLength
+		if resp.StatusCode == http.StatusPartialContent {
+			pw.progress.Total += offset
+		}
+	}
+
+	cw := &countingWriter{w: w}
+	if _, err := io.Copy(cw, resp.Body); err != nil {
+		if cw.err != nil {
+			return cw.n, sum, cw.err
+		}
+		return cw.n, sum, &interruptedDownloadError{Err: err}
+	}
+	return cw.n, sum, nil
+}
+
+// responseChecksum returns the lowercase hex SHA-256 advertised by the
+// server, or "" if there is none. Weak ETags are ignored.
+func responseChecksum(h http.Header) string {
+	sum := h.Get(ContentSHA256Header)
+	if sum == "" {
+		if etag := h.Get("ETag"); !strings.HasPrefix(etag, "W/") {
+			sum = strings.Trim(etag, `"`)
+		}
+	}
+	sum = strings.ToLower(sum)
+	if b, err := hex.DecodeString(sum); err != nil || len(b) != sha256.Size {
+		return ""
+	}
+	return sum
+}
+
+// countingWriter counts bytes written to w and keeps w's error, so write
+// failures can be told apart from read failures.
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	c.err = err
+	return n, err
+}
@@ -0,0 +1,175 @@
+package refyne
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestDownloadResultsResume(t *testing.T) {
+	payload := strings.Repeat("0123456789", 1000)
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/api/v1/jobs/job-1/results" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if requests == 1 {
+			// Promise the full body but cut the connection half way.
+			w.Header().Set("Content-Length", strconv.Itoa(len(payload)))
+			_, _ = w.Write([]byte(payload[:4000]))
+			w.(http.Flusher).Flush()
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			_ = conn.Close()
+			return
+		}
+		if got := r.Header.Get("Range"); got != "bytes=4000-" {
+			t.Errorf("expected Range 'bytes=4000-', got %q", got)
+		}
+		w.WriteHeader(http.StatusPartialContent)
+		_, _ = w.Write([]byte(payload[4000:]))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL))
+	var buf bytes.Buffer
+	n, err := client.Jobs.DownloadResults(context.Background(), "job-1", &buf, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != int64(len(payload)) || buf.String() != payload {
+		t.Errorf("expected %d bytes of payload, got %d", len(payload), n)
+	}
+	if requests != 2 {
+		t.Errorf("expected 2 requests, got %d", requests)
+	}
+}
+
+func TestDownloadResultsIgnoredRange(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept-Encoding") != "gzip" {
+			t.Errorf("expected gzip to be requested")
+		}
+		_, _ = w.Write([]byte("hello world"))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL))
+	var buf bytes.Buffer
+	n, err := client.Jobs.DownloadResults(context.Background(), "job-1", &buf, &DownloadOptions{Offset: 6, Gzip: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 11 || buf.String() != "world" {
+		t.Errorf("expected the remaining bytes, got %d %q", n, buf.String())
+	}
+}
+
+func TestDownloadResultsChecksum(t *testing.T) {
+	payload := []byte(`{"results":[]}`)
+	sum := sha256.Sum256(payload)
+	checksum := hex.EncodeToString(sum[:])
+
+	for _, tc := range []struct {
+		name   string
+		header string
+		value  string
+		ok     bool
+	}{
+		{"header", ContentSHA256Header, checksum, true},
+		{"etag", "ETag", `"` + checksum + `"`, true},
+		{"mismatch", ContentSHA256Header, strings.Repeat("0", 64), false},
+		{"weak etag", "ETag", `W/"` + strings.Repeat("0", 64) + `"`, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set(tc.header, tc.value)
+				_, _ = w.Write(payload)
+			}))
+			defer server.Close()
+
+			client := NewClient("test-key", WithBaseURL(server.URL))
+			_, err := client.Jobs.DownloadResults(context.Background(), "job-1", io.Discard, nil)
+
+			var integrity *IntegrityError
+			if tc.ok && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !tc.ok && !errors.As(err, &integrity) {
+				t.Errorf("expected IntegrityError, got %T: %v", err, err)
+			}
+		})
+	}
+}
+
+func TestExportArchive(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/jobs/job-1/export" || r.URL.Query().Get("format") != "tar.gz" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		w.Header().Set("Content-Type", "application/gzip")
+		_, _ = w.Write([]byte("archive"))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL))
+	var buf bytes.Buffer
+	if _, err := client.Jobs.ExportArchive(context.Background(), "job-1", ArchiveTarGz, &buf, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != "archive" {
+		t.Errorf("unexpected archive %q", buf.String())
+	}
+}
+
+func TestGetResultsReader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/jobs/missing/results" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"job not found"}`))
+			return
+		}
+		if r.URL.RawQuery != "merge=true" {
+			t.Errorf("expected merge query, got %q", r.URL.RawQuery)
+		}
+		_, _ = w.Write([]byte(`[{"name":"a"},{"name":"b"}]`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL), WithMaxResponseSize(8))
+	rc, err := client.Jobs.GetResultsReader(context.Background(), "job-1", &ResultsOptions{Merge: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer rc.Close()
+
+	dec := json.NewDecoder(rc)
+	if _, err := dec.Token(); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for dec.More() {
+		var item struct{ Name string }
+		if err := dec.Decode(&item); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, item.Name)
+	}
+	if strings.Join(names, ",") != "a,b" {
+		t.Errorf("expected streamed items a,b, got %v", names)
+	}
+
+	var notFound *NotFoundError
+	if _, err := client.Jobs.GetResultsReader(context.Background(), "missing", nil); !errors.As(err, &notFound) {
+		t.Errorf("expected NotFoundError, got %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package refyne
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// EnvelopeAlgorithm is the envelope encryption scheme used for provider
+// keys: the secret is sealed with a random AES-256-GCM data key, and the
+// data key is wrapped with the API's RSA public key using OAEP and SHA-256.
+const EnvelopeAlgorithm = "RSA-OAEP-256+A256GCM"
+
+// EncryptionKey is the API's published public key for provider secrets.
+type EncryptionKey struct {
+	KeyID string `json:"key_id"`
+	// PublicKey is a PEM-encoded PKIX RSA public key.
+	PublicKey string `json:"public_key"`
+}
+
+// EncryptedSecret is a secret sealed with envelope encryption.
+type EncryptedSecret struct {
+	KeyID        string `json:"key_id"`
+	Algorithm    string `json:"algorithm"`
+	EncryptedKey []byte `json:"encrypted_key"`
+	Nonce        []byte `json:"nonce"`
+	Ciphertext   []byte `json:"ciphertext"`
+}
+
+// GetEncryptionKey returns the public key used to encrypt provider keys.
+func (l *LLMClient) GetEncryptionKey(ctx context.Context, reqOpts ...RequestOption) (*EncryptionKey, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
+	var result EncryptionKey
+	if err := l.client.request(ctx, http.MethodGet, "/api/v1/llm/keys/encryption-key", nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Encrypt seals plaintext for the holder of the key's private half. The key
+// ID is bound to the result as the OAEP label and GCM additional data.
+func (k *EncryptionKey) Encrypt(plaintext []byte) (*EncryptedSecret, error) {
+	block, _ := pem.Decode([]byte(k.PublicKey))
+	if block == nil {
+		return nil, errors.New("encryption key is not PEM encoded")
+	}
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %w", err)
+	}
+	pub, ok := parsed.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("unsupported encryption key type %T", parsed)
+	}
+
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+	aesBlock, err := aes.NewCipher(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(aesBlock)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	wrapped, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, pub, dataKey, []byte(k.KeyID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to wrap data key: %w", err)
+	}
+	return &EncryptedSecret{
+		KeyID:        k.KeyID,
+		Algorithm:    EnvelopeAlgorithm,
+		EncryptedKey: wrapped,
+		Nonce:        nonce,
+		Ciphertext:   gcm.Seal(nil, nonce, plaintext, []byte(k.KeyID)),
+	}, nil
+}
@@ -0,0 +1,73 @@
+package refyne
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUpsertKeyEncrypted(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+
+	var received UpsertKeyInput
+	var raw map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/llm/keys/encryption-key":
+			_ = json.NewEncoder(w).Encode(EncryptionKey{KeyID: "k1", PublicKey: publicKey})
+		case "/api/v1/llm/keys":
+			var body json.RawMessage
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			_ = json.Unmarshal(body, &raw)
+			_ = json.Unmarshal(body, &received)
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "key-1"})
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL))
+	_, err = client.LLM.UpsertKey(context.Background(), UpsertKeyInput{
+		Provider: "openai",
+		APIKey:   "sk-secret",
+		Encrypt:  true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := raw["api_key"]; ok {
+		t.Error("expected no plaintext api_key in the request")
+	}
+
+	sealed := received.EncryptedAPIKey
+	if sealed == nil || sealed.KeyID != "k1" || sealed.Algorithm != EnvelopeAlgorithm {
+		t.Fatalf("unexpected encrypted key %+v", sealed)
+	}
+	dataKey, err := rsa.DecryptOAEP(sha256.New(), nil, priv, sealed.EncryptedKey, []byte("k1"))
+	if err != nil {
+		t.Fatalf("failed to unwrap data key: %v", err)
+	}
+	block, _ := aes.NewCipher(dataKey)
+	gcm, _ := cipher.NewGCM(block)
+	plaintext, err := gcm.Open(nil, sealed.Nonce, sealed.Ciphertext, []byte("k1"))
+	if err != nil || string(plaintext) != "sk-secret" {
+		t.Errorf("expected to decrypt sk-secret, got %q, %v", plaintext, err)
+	}
+}
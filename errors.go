@@ -1,6 +1,10 @@
 package refyne
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 // Logger is the interface for custom logging.
 type Logger interface {
@@ -23,6 +27,15 @@ type APIError struct {
 	Message string
 	Status  int
 	Detail  string
+	// RequestID is the server's X-Request-ID, or the one sent if the
+	// server did not echo it.
+	RequestID string
+}
+
+func (e *APIError) setRequestID(id string) {
+	if e.RequestID == "" {
+		e.RequestID = id
+	}
 }
 
 func (e *APIError) Error() string {
@@ -69,19 +82,207 @@ func (e *NotFoundError) Error() string {
 	return fmt.Sprintf("not found: %s", e.Message)
 }
 
-// RateLimitError is returned when rate limit is exceeded.
+// ConflictError is returned when a request conflicts with the current state
+// of a resource.
+type ConflictError struct {
+	APIError
+	// ResourceID is the conflicting resource, when the API names it.
+	ResourceID string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("conflict: %s", e.Message)
+}
+
+// PreconditionFailedError is returned when an If-Match precondition fails
+// because the resource changed since it was read. It unwraps to a
+// *ConflictError, so both can be handled together.
+type PreconditionFailedError struct {
+	ConflictError
+}
+
+func (e *PreconditionFailedError) Error() string {
+	return fmt.Sprintf("precondition failed: %s", e.Message)
+}
+
+func (e *PreconditionFailedError) Unwrap() error {
+	return &e.ConflictError
+}
+
+// SchemaInUseError is returned by SchemasClient.Delete when the schema is
+// still referenced. References lists what must be changed before the schema
+// can be deleted.
+type SchemaInUseError struct {
+	SchemaID   string
+	References *SchemaReferences
+	Err        *ConflictError
+}
+
+func (e *SchemaInUseError) Error() string {
+	r := e.References
+	return fmt.Sprintf("schema %s is in use by %d sites, %d schedules and %d recent jobs",
+		e.SchemaID, len(r.Sites), len(r.Schedules), len(r.Jobs))
+}
+
+func (e *SchemaInUseError) Unwrap() error {
+	return e.Err
+}
+
+// RateLimitError is returned when rate limit is exceeded. Its quota fields
+// come from the response's rate limit headers and are zero when the API did
+// not send them.
 type RateLimitError struct {
 	APIError
-	RetryAfter int
+	// RetryAfter is how long the API asked to wait before retrying.
+	RetryAfter time.Duration
+	// Limit is the number of requests allowed per rate limit window, and
+	// Remaining how many of them are left.
+	Limit     int64
+	Remaining int64
+	// ResetAt is when the rate limit window resets.
+	ResetAt time.Time
 }
 
 func (e *RateLimitError) Error() string {
 	return fmt.Sprintf("rate limit exceeded: %s", e.Message)
 }
 
+// ResponseTooLargeError is returned when a response body exceeds the limit
+// set by WithMaxResponseSize.
+type ResponseTooLargeError struct {
+	Path  string
+	Limit int64
+}
+
+func (e *ResponseTooLargeError) Error() string {
+	return fmt.Sprintf("response from %s exceeds %d bytes; use Jobs.DownloadResults to stream large results or raise WithMaxResponseSize", e.Path, e.Limit)
+}
+
+// IntegrityError is returned when downloaded content does not match the
+// checksum provided by the server.
+type IntegrityError struct {
+	Path     string
+	Expected string
+	Actual   string
+}
+
+func (e *IntegrityError) Error() string {
+	return fmt.Sprintf("checksum mismatch for %s: expected sha256 %s, got %s", e.Path, e.Expected, e.Actual)
+}
+
+// OfflineError is returned in offline mode for requests that cannot be
+// served from the cache.
+type OfflineError struct {
+	Method string
+	Path   string
+}
+
+func (e *OfflineError) Error() string {
+	return fmt.Sprintf("offline: %s %s is not in the cache", e.Method, e.Path)
+}
+
+// RetryBudgetError is returned when retrying a call would exceed the
+// budget set by WithMaxElapsedRetryTime. Err is the last attempt's error.
+type RetryBudgetError struct {
+	Attempts int
+	Elapsed  time.Duration
+	Budget   time.Duration
+	Err      error
+}
+
+func (e *RetryBudgetError) Error() string {
+	return fmt.Sprintf("retry budget of %v exhausted after %d attempts in %v: %v", e.Budget, e.Attempts, e.Elapsed.Round(time.Millisecond), e.Err)
+}
+
+func (e *RetryBudgetError) Unwrap() error {
+	return e.Err
+}
+
+// DeadlineWouldExceedError is returned instead of retrying when the
+// backoff plus a typical request would outlast the context's deadline, so
+// the remaining time is not spent sleeping. Err is the last attempt's error.
+type DeadlineWouldExceedError struct {
+	Attempts int
+	// Delay is the backoff the retry would have waited.
+	Delay time.Duration
+	// Typical is the client's average request duration.
+	Typical   time.Duration
+	Remaining time.Duration
+	Err       error
+}
+
+func (e *DeadlineWouldExceedError) Error() string {
+	return fmt.Sprintf("not retrying after %d attempts: %v backoff and %v request would exceed the %v left before the deadline: %v",
+		e.Attempts, e.Delay, e.Typical.Round(time.Millisecond), e.Remaining.Round(time.Millisecond), e.Err)
+}
+
+func (e *DeadlineWouldExceedError) Unwrap() error {
+	return e.Err
+}
+
+// CircuitOpenError is returned without contacting the API while the
+// circuit breaker set by WithCircuitBreaker is open.
+type CircuitOpenError struct {
+	// Until is when a trial request will next be allowed.
+	Until time.Time
+	// Failures is the number of consecutive failures seen.
+	Failures int
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit breaker open after %d consecutive failures; retry after %s", e.Failures, e.Until.Format(time.RFC3339))
+}
+
+// FeatureUnavailableError is returned without contacting the API when a
+// call needs a feature that GetCapabilities reports as turned off.
+type FeatureUnavailableError struct {
+	Feature Feature
+}
+
+func (e *FeatureUnavailableError) Error() string {
+	return fmt.Sprintf("feature %q is not enabled for this account or deployment", e.Feature)
+}
+
+// CrawlQueuedError is returned by Crawl when the API could not be reached
+// and the submission was queued by WithOfflineQueue. Err is the failure.
+type CrawlQueuedError struct {
+	// ID is the queued submission's Idempotency-Key.
+	ID  string
+	Err error
+}
+
+func (e *CrawlQueuedError) Error() string {
+	return fmt.Sprintf("crawl queued for replay as %s: %v", e.ID, e.Err)
+}
+
+func (e *CrawlQueuedError) Unwrap() error {
+	return e.Err
+}
+
+// ResultsNotReadyError is returned when fetching the results of a job that
+// has not finished, so it can be told apart from a job with no results.
+type ResultsNotReadyError struct {
+	// Status is the job's current status, e.g. "running".
+	Status string
+	// PageCount is the number of pages processed so far.
+	PageCount int64
+}
+
+func (e *ResultsNotReadyError) Error() string {
+	return fmt.Sprintf("job results not ready: job is %s with %d pages processed", e.Status, e.PageCount)
+}
+
 // NetworkError is returned when a network error occurs.
 type NetworkError struct {
 	Err error
+	// RequestID is the X-Request-ID of the failed call.
+	RequestID string
+}
+
+func (e *NetworkError) setRequestID(id string) {
+	if e.RequestID == "" {
+		e.RequestID = id
+	}
 }
 
 func (e *NetworkError) Error() string {
@@ -91,3 +292,35 @@ func (e *NetworkError) Error() string {
 func (e *NetworkError) Unwrap() error {
 	return e.Err
 }
+
+// requestIDSetter is implemented by errors that carry a request ID.
+type requestIDSetter interface {
+	setRequestID(id string)
+}
+
+// withRequestID records id on err, unless err already has a request ID.
+func withRequestID(err error, id string) error {
+	var e requestIDSetter
+	if id != "" && errors.As(err, &e) {
+		e.setRequestID(id)
+	}
+	return err
+}
+
+// ErrorRequestID returns the request ID carried by an error returned from
+// the SDK, or "" if it has none, for correlating failures with server logs.
+func ErrorRequestID(err error) string {
+	var apiErr interface{ requestID() string }
+	if errors.As(err, &apiErr) {
+		return apiErr.requestID()
+	}
+	return ""
+}
+
+func (e *APIError) requestID() string {
+	return e.RequestID
+}
+
+func (e *NetworkError) requestID() string {
+	return e.RequestID
+}
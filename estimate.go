@@ -0,0 +1,94 @@
+package refyne
+
+import (
+	"context"
+	"errors"
+)
+
+// InventorySampleSize is the number of URLs EstimateInventoryCost extracts.
+const InventorySampleSize = 10
+
+// InventoryEstimate is the projected cost of extracting a list of URLs.
+type InventoryEstimate struct {
+	// URLs is the size of the inventory.
+	URLs int
+	// Sampled is the number of sample pages extracted successfully, and
+	// Failed the number that could not be extracted.
+	Sampled int
+	Failed  int
+	// SampleCostUsd is what the sample extractions cost.
+	SampleCostUsd float64
+
+	// InputTokens and OutputTokens are the expected tokens for the whole
+	// inventory.
+	InputTokens  int64
+	OutputTokens int64
+
+	// MinCostUsd and MaxCostUsd bound the cost if every page cost as little
+	// or as much as the cheapest or most expensive sampled page.
+	MinCostUsd      float64
+	ExpectedCostUsd float64
+	MaxCostUsd      float64
+}
+
+// EstimateInventoryCost estimates what extracting every URL in urls with
+// schema would cost, for quoting extraction projects up front. It extracts
+// up to InventorySampleSize URLs spread evenly across the list, which are
+// charged as normal, and extrapolates their tokens and cost to the full
+// list. A nil model uses the account's fallback chain.
+//
+// Pages that fail to extract are left out of the projection. An error is
+// returned only if no sample page could be extracted.
+func (c *Client) EstimateInventoryCost(ctx context.Context, urls []string, schema any, model *LLMConfigInput, reqOpts ...RequestOption) (*InventoryEstimate, error) {
+	if len(urls) == 0 {
+		return nil, errors.New("no URLs to estimate")
+	}
+
+	estimate := &InventoryEstimate{URLs: len(urls)}
+	var usage UsageAccumulator
+	var minCost, maxCost float64
+	var lastErr error
+	for _, url := range sampleURLs(urls, InventorySampleSize) {
+		out, err := c.Extract(ctx, ExtractInput{URL: url, Schema: schema, LLMConfig: model}, reqOpts...)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			estimate.Failed++
+			lastErr = err
+			continue
+		}
+		usage.AddExtract(out)
+		cost := out.Usage.CostUsd
+		if estimate.Sampled == 0 {
+			minCost, maxCost = cost, cost
+		}
+		minCost, maxCost = min(minCost, cost), max(maxCost, cost)
+		estimate.Sampled++
+	}
+
+	if estimate.Sampled == 0 {
+		return nil, lastErr
+	}
+	total := usage.Total()
+	scale := float64(len(urls)) / float64(estimate.Sampled)
+	estimate.SampleCostUsd = total.CostUsd
+	estimate.InputTokens = int64(float64(total.InputTokens) * scale)
+	estimate.OutputTokens = int64(float64(total.OutputTokens) * scale)
+	estimate.MinCostUsd = minCost * float64(len(urls))
+	estimate.ExpectedCostUsd = total.CostUsd * scale
+	estimate.MaxCostUsd = maxCost * float64(len(urls))
+	return estimate, nil
+}
+
+// sampleURLs returns up to n URLs spread evenly across urls.
+func sampleURLs(urls []string, n int) []string {
+	if len(urls) <= n {
+		return urls
+	}
+	sample := make([]string, n)
+	for i := range sample {
+		sample[i] = urls[i*len(urls)/n]
+	}
+	return sample
+}
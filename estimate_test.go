@@ -0,0 +1,59 @@
+package refyne
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEstimateInventoryCost(t *testing.T) {
+	var extracted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var input ExtractInput
+		_ = json.NewDecoder(r.Body).Decode(&input)
+		extracted = append(extracted, input.URL)
+		if input.URL == "https://example.com/50" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = w.Write([]byte(`{"error":"page unreachable"}`))
+			return
+		}
+		cost := 0.01
+		if len(extracted)%2 == 0 {
+			cost = 0.03
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"job_id": "job-1",
+			"usage":  map[string]any{"input_tokens": 1000, "output_tokens": 100, "cost_usd": cost},
+		})
+	}))
+	defer server.Close()
+
+	urls := make([]string, 100)
+	for i := range urls {
+		urls[i] = fmt.Sprintf("https://example.com/%d", i)
+	}
+	client := NewClient("test-key", WithBaseURL(server.URL), WithMaxRetries(0))
+	estimate, err := client.EstimateInventoryCost(context.Background(), urls, map[string]any{"title": "string"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(extracted) != InventorySampleSize || extracted[0] != urls[0] || extracted[9] != urls[90] {
+		t.Errorf("expected an even sample of the inventory, got %v", extracted)
+	}
+	if estimate.Sampled != 9 || estimate.Failed != 1 {
+		t.Errorf("expected 9 sampled and 1 failed, got %d and %d", estimate.Sampled, estimate.Failed)
+	}
+	if estimate.InputTokens != 100000 || estimate.OutputTokens != 10000 {
+		t.Errorf("unexpected token projection: %d in, %d out", estimate.InputTokens, estimate.OutputTokens)
+	}
+	if FormatUSD(estimate.MinCostUsd) != "$1.00" || FormatUSD(estimate.MaxCostUsd) != "$3.00" {
+		t.Errorf("unexpected bounds: %v to %v", estimate.MinCostUsd, estimate.MaxCostUsd)
+	}
+	if estimate.ExpectedCostUsd <= estimate.MinCostUsd || estimate.ExpectedCostUsd >= estimate.MaxCostUsd {
+		t.Errorf("expected cost %v outside bounds", estimate.ExpectedCostUsd)
+	}
+}
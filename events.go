@@ -0,0 +1,116 @@
+package refyne
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// EventType identifies a job event delivered by webhooks.
+type EventType string
+
+// Job event types. EventAll subscribes a webhook to every event type.
+const (
+	EventAll          EventType = "*"
+	EventJobStarted   EventType = "job.started"
+	EventJobProgress  EventType = "job.progress"
+	EventJobResult    EventType = "job.result"
+	EventJobCompleted EventType = "job.completed"
+	EventJobFailed    EventType = "job.failed"
+)
+
+// Valid indicates whether the value is an event type known to this SDK.
+func (t EventType) Valid() bool {
+	switch t {
+	case EventAll, EventJobStarted, EventJobProgress, EventJobResult, EventJobCompleted, EventJobFailed:
+		return true
+	default:
+		return false
+	}
+}
+
+// Event is a job event. Use a type switch on the concrete types returned by
+// ParseEvent; event types added to the API after this SDK was released are
+// returned as *UnknownEvent.
+type Event interface {
+	EventType() EventType
+}
+
+// EventMeta contains the fields shared by all job events.
+type EventMeta struct {
+	Type      EventType `json:"event"`
+	JobID     string    `json:"job_id"`
+	Timestamp string    `json:"timestamp"`
+}
+
+// EventType implements Event.
+func (m EventMeta) EventType() EventType { return m.Type }
+
+// JobStatusEvent is sent for job.started and job.progress.
+type JobStatusEvent struct {
+	EventMeta
+	Data JobStatus `json:"data"`
+}
+
+// JobStatus is the data of a JobStatusEvent. The queue fields are set
+// while the job waits behind the account's concurrency limit.
+type JobStatus struct {
+	SSEStatusEvent
+	QueuePosition     int64      `json:"queue_position,omitempty"`
+	EstimatedStartAt  *time.Time `json:"estimated_start_at,omitempty"`
+	EstimatedFinishAt *time.Time `json:"estimated_finish_at,omitempty"`
+}
+
+// JobResultEvent is sent when a page has been extracted.
+type JobResultEvent struct {
+	EventMeta
+	Data PageResult `json:"data"`
+}
+
+// PageResult is the data of a JobResultEvent.
+type PageResult struct {
+	SSEResultEvent
+	// FailureCategory classifies why the page failed, if it did.
+	FailureCategory FailureCategory `json:"failure_category,omitempty"`
+}
+
+// JobCompletedEvent is sent for job.completed and job.failed.
+type JobCompletedEvent struct {
+	EventMeta
+	Data SSECompleteEvent `json:"data"`
+}
+
+// UnknownEvent is an event whose type this SDK does not recognise. Raw holds
+// the full payload so it can be decoded by the caller.
+type UnknownEvent struct {
+	Type EventType
+	Raw  json.RawMessage
+}
+
+// EventType implements Event.
+func (e *UnknownEvent) EventType() EventType { return e.Type }
+
+// ParseEvent decodes a webhook payload into its concrete event type.
+func ParseEvent(payload []byte) (Event, error) {
+	var meta EventMeta
+	if err := json.Unmarshal(payload, &meta); err != nil {
+		return nil, fmt.Errorf("failed to parse event: %w", err)
+	}
+
+	var event Event
+	switch meta.Type {
+	case EventJobStarted, EventJobProgress:
+		event = &JobStatusEvent{}
+	case EventJobResult:
+		event = &JobResultEvent{}
+	case EventJobCompleted, EventJobFailed:
+		event = &JobCompletedEvent{}
+	default:
+		return &UnknownEvent{Type: meta.Type, Raw: append(json.RawMessage(nil), payload...)}, nil
+	}
+
+	if err := json.Unmarshal(payload, event); err != nil {
+		return nil, fmt.Errorf("failed to parse %s event: %w", meta.Type, err)
+	}
+	return event, nil
+}
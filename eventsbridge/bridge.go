@@ -0,0 +1,182 @@
+// Package eventsbridge forwards Refyne job events to a message bus.
+//
+// Events can come from Jobs.Watch or from webhook deliveries. The package
+// does not depend on any particular NATS or Kafka client; adapt yours with
+// a Publisher:
+//
+//	nc, _ := nats.Connect(nats.DefaultURL)
+//	bridge := eventsbridge.New(eventsbridge.NATSPublisher(nc))
+//
+//	http.Handle("/webhooks/refyne", bridge.WebhookHandler(secret))
+//	err := bridge.WatchJob(ctx, client, jobID)
+package eventsbridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	refyne "github.com/jmylchreest/refyne-sdk-go"
+)
+
+// Publisher publishes a message to a topic (a NATS subject or Kafka topic).
+type Publisher interface {
+	Publish(ctx context.Context, topic string, key, value []byte) error
+}
+
+// PublisherFunc adapts a function to a Publisher. It is the simplest way to
+// wrap a Kafka producer:
+//
+//	eventsbridge.PublisherFunc(func(ctx context.Context, topic string, key, value []byte) error {
+//	    return writer.WriteMessages(ctx, kafka.Message{Topic: topic, Key: key, Value: value})
+//	})
+type PublisherFunc func(ctx context.Context, topic string, key, value []byte) error
+
+// Publish implements Publisher.
+func (f PublisherFunc) Publish(ctx context.Context, topic string, key, value []byte) error {
+	return f(ctx, topic, key, value)
+}
+
+// NATSConn is the subset of *nats.Conn used by NATSPublisher.
+type NATSConn interface {
+	Publish(subject string, data []byte) error
+}
+
+// NATSPublisher returns a Publisher that publishes to NATS subjects. NATS
+// has no message keys, so the key is dropped.
+func NATSPublisher(conn NATSConn) Publisher {
+	return PublisherFunc(func(_ context.Context, topic string, _, value []byte) error {
+		return conn.Publish(topic, value)
+	})
+}
+
+// Serializer encodes an event for publishing.
+type Serializer func(refyne.Event) ([]byte, error)
+
+// JSONSerializer encodes events as JSON. Unknown events are published with
+// their original payload.
+func JSONSerializer(event refyne.Event) ([]byte, error) {
+	if unknown, ok := event.(*refyne.UnknownEvent); ok {
+		return unknown.Raw, nil
+	}
+	return json.Marshal(event)
+}
+
+// TopicFunc chooses the topic for an event.
+type TopicFunc func(refyne.Event) string
+
+// Bridge publishes job events to a Publisher.
+type Bridge struct {
+	publisher  Publisher
+	topic      TopicFunc
+	serializer Serializer
+}
+
+// Option configures a Bridge.
+type Option func(*Bridge)
+
+// WithTopic publishes every event to a single topic.
+func WithTopic(topic string) Option {
+	return func(b *Bridge) {
+		b.topic = func(refyne.Event) string { return topic }
+	}
+}
+
+// WithTopicFunc chooses the topic per event.
+func WithTopicFunc(fn TopicFunc) Option {
+	return func(b *Bridge) {
+		b.topic = fn
+	}
+}
+
+// WithSerializer sets how events are encoded.
+func WithSerializer(s Serializer) Option {
+	return func(b *Bridge) {
+		b.serializer = s
+	}
+}
+
+// New creates a Bridge. By default events are JSON encoded and published to
+// "refyne.<event type>", e.g. "refyne.job.completed".
+func New(publisher Publisher, opts ...Option) *Bridge {
+	b := &Bridge{
+		publisher:  publisher,
+		topic:      DefaultTopic,
+		serializer: JSONSerializer,
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// DefaultTopic returns "refyne.<event type>".
+func DefaultTopic(event refyne.Event) string {
+	return "refyne." + string(event.EventType())
+}
+
+// Publish serializes and publishes a single event, keyed by job ID so that
+// partitioned buses keep a job's events in order.
+func (b *Bridge) Publish(ctx context.Context, event refyne.Event) error {
+	value, err := b.serializer(event)
+	if err != nil {
+		return fmt.Errorf("failed to serialize %s event: %w", event.EventType(), err)
+	}
+	if err := b.publisher.Publish(ctx, b.topic(event), []byte(jobID(event)), value); err != nil {
+		return fmt.Errorf("failed to publish %s event: %w", event.EventType(), err)
+	}
+	return nil
+}
+
+// WatchJob publishes the events of a job as they are streamed by
+// Jobs.Watch, returning once the job finishes.
+func (b *Bridge) WatchJob(ctx context.Context, client *refyne.Client, jobID string) error {
+	return client.Jobs.Watch(ctx, jobID, func(event refyne.Event) error {
+		return b.Publish(ctx, event)
+	})
+}
+
+// WebhookHandler returns an http.Handler that receives webhook deliveries,
+// verifies their signature against secrets (skipped when none are given)
+// and publishes the parsed event. Publishing failures are reported with a
+// 502 so the API retries the delivery.
+func (b *Bridge) WebhookHandler(secrets ...string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "failed to read body", http.StatusBadRequest)
+			return
+		}
+		if len(secrets) > 0 {
+			if err := refyne.VerifyWebhookSignature(payload, r.Header.Get(refyne.WebhookSignatureHeader), secrets...); err != nil {
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+		}
+		event, err := refyne.ParseEvent(payload)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := b.Publish(r.Context(), event); err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// jobID returns the job an event belongs to, if known.
+func jobID(event refyne.Event) string {
+	switch e := event.(type) {
+	case *refyne.JobStatusEvent:
+		return e.JobID
+	case *refyne.JobResultEvent:
+		return e.JobID
+	case *refyne.JobCompletedEvent:
+		return e.JobID
+	}
+	return ""
+}
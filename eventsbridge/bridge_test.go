@@ -0,0 +1,85 @@
+package eventsbridge
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	refyne "github.com/jmylchreest/refyne-sdk-go"
+)
+
+type message struct {
+	topic      string
+	key, value string
+}
+
+type recordingPublisher struct {
+	messages []message
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, topic string, key, value []byte) error {
+	p.messages = append(p.messages, message{topic, string(key), string(value)})
+	return nil
+}
+
+func TestWebhookHandler(t *testing.T) {
+	publisher := &recordingPublisher{}
+	bridge := New(publisher)
+
+	payload := `{"event":"job.completed","job_id":"job-1","data":{"status":"completed"}}`
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(payload))
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(payload))
+	req.Header.Set(refyne.WebhookSignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	rec := httptest.NewRecorder()
+	bridge.WebhookHandler("secret").ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected status 204, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if len(publisher.messages) != 1 {
+		t.Fatalf("expected 1 published message, got %d", len(publisher.messages))
+	}
+	msg := publisher.messages[0]
+	if msg.topic != "refyne.job.completed" || msg.key != "job-1" {
+		t.Errorf("unexpected message routing: %+v", msg)
+	}
+}
+
+func TestWebhookHandlerRejectsBadSignature(t *testing.T) {
+	publisher := &recordingPublisher{}
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"event":"job.completed"}`))
+	req.Header.Set(refyne.WebhookSignatureHeader, "sha256=00")
+	rec := httptest.NewRecorder()
+	New(publisher).WebhookHandler("secret").ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected status 401, got %d", rec.Code)
+	}
+	if len(publisher.messages) != 0 {
+		t.Errorf("expected nothing to be published, got %d messages", len(publisher.messages))
+	}
+}
+
+func TestUnknownEventsPublishedVerbatim(t *testing.T) {
+	publisher := &recordingPublisher{}
+	bridge := New(publisher, WithTopic("events"))
+
+	payload := `{"event":"job.archived","job_id":"job-1"}`
+	event, err := refyne.ParseEvent([]byte(payload))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := bridge.Publish(context.Background(), event); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if msg := publisher.messages[0]; msg.topic != "events" || msg.value != payload {
+		t.Errorf("unexpected message: %+v", msg)
+	}
+}
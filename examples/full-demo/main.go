@@ -30,64 +30,6 @@ const (
 	bgGreen      = "\033[42m"
 )
 
-// Spinner provides animated progress indication
-type Spinner struct {
-	frames  []string
-	current int
-	message string
-	done    chan bool
-}
-
-// NewSpinner creates a new spinner with a message
-func NewSpinner(message string) *Spinner {
-	return &Spinner{
-		frames:  []string{"|", "/", "-", "\\"},
-		message: message,
-		done:    make(chan bool),
-	}
-}
-
-// Start begins the spinner animation
-func (s *Spinner) Start() {
-	fmt.Print("\033[?25l") // Hide cursor
-	go func() {
-		ticker := time.NewTicker(80 * time.Millisecond)
-		defer ticker.Stop()
-		for {
-			select {
-			case <-s.done:
-				return
-			case <-ticker.C:
-				fmt.Printf("\r%s%s%s %s", colorCyan, s.frames[s.current], colorReset, s.message)
-				s.current = (s.current + 1) % len(s.frames)
-			}
-		}
-	}()
-}
-
-// Succeed stops the spinner with a success message
-func (s *Spinner) Succeed(message string) {
-	s.stop()
-	if message == "" {
-		message = s.message
-	}
-	fmt.Printf("\r\033[K%s[OK]%s %s\n", colorGreen, colorReset, message)
-}
-
-// Fail stops the spinner with a failure message
-func (s *Spinner) Fail(message string) {
-	s.stop()
-	if message == "" {
-		message = s.message
-	}
-	fmt.Printf("\r\033[K%s[FAIL]%s %s\n", colorRed, colorReset, message)
-}
-
-func (s *Spinner) stop() {
-	close(s.done)
-	fmt.Print("\033[?25h") // Show cursor
-}
-
 func header(text string) {
 	fmt.Println()
 	fmt.Printf("%s%s %s %s\n", bgBlue, colorBold, text, colorReset)
@@ -167,16 +109,15 @@ func main() {
 	// ========== Subscription Info ==========
 	header("Usage Information")
 
-	spinner := NewSpinner("Fetching usage details...")
-	spinner.Start()
+	bar := refyne.NewProgressBar(os.Stdout, "Fetching usage details")
+	bar.Start()
 
 	usage, err := client.GetUsage(ctx)
 	if err != nil {
-		spinner.Fail("Failed to fetch usage")
-		errorMsg(err.Error())
+		bar.Done(err)
 		os.Exit(1)
 	}
-	spinner.Succeed("Usage details retrieved")
+	bar.Done(nil)
 
 	info("Total Jobs", fmt.Sprintf("%d", usage.TotalJobs))
 	info("Total Charged", fmt.Sprintf("$%.2f USD", usage.TotalChargedUsd))
@@ -188,14 +129,13 @@ func main() {
 	subheader("Target")
 	info("URL", testURL)
 
-	spinner = NewSpinner("Analyzing website structure...")
-	spinner.Start()
+	bar = refyne.NewProgressBar(os.Stdout, "Analyzing website structure")
+	bar.Start()
 
 	var suggestedSchema map[string]any
 	analysis, err := client.Analyze(ctx, refyne.AnalyzeInput{URL: testURL})
 	if err != nil {
-		spinner.Fail("Analysis unavailable")
-		warn(err.Error())
+		bar.Done(err)
 		// Use a fallback schema
 		suggestedSchema = map[string]any{
 			"headline": "string",
@@ -204,7 +144,7 @@ func main() {
 		info("Using fallback schema", "")
 		printJSON(suggestedSchema)
 	} else {
-		spinner.Succeed("Website analysis complete")
+		bar.Done(nil)
 		// Parse suggested schema from YAML/JSON string
 		if err := json.Unmarshal([]byte(analysis.SuggestedSchema), &suggestedSchema); err != nil {
 			// Try parsing as simple schema
@@ -233,18 +173,17 @@ func main() {
 	info("URL", testURL)
 	info("Schema", "Using suggested schema from analysis")
 
-	spinner = NewSpinner("Extracting data from page...")
-	spinner.Start()
+	bar = refyne.NewProgressBar(os.Stdout, "Extracting data from page")
+	bar.Start()
 
 	extractResult, err := client.Extract(ctx, refyne.ExtractInput{
 		URL:    testURL,
 		Schema: suggestedSchema,
 	})
 	if err != nil {
-		spinner.Fail("Extraction failed")
-		warn(err.Error())
+		bar.Done(err)
 	} else {
-		spinner.Succeed("Extraction complete")
+		bar.Done(nil)
 
 		subheader("Result")
 		info("Fetched At", extractResult.FetchedAt)
@@ -265,8 +204,8 @@ func main() {
 	info("Max URLs", "5")
 	info("Schema", "Using suggested schema from analysis")
 
-	spinner = NewSpinner("Starting crawl job...")
-	spinner.Start()
+	bar = refyne.NewProgressBar(os.Stdout, "Starting crawl job")
+	bar.Start()
 
 	crawlResult, err := client.Crawl(ctx, refyne.CrawlInput{
 		URL:    testURL,
@@ -277,8 +216,7 @@ func main() {
 		},
 	})
 	if err != nil {
-		spinner.Fail("Failed to start crawl")
-		warn(err.Error())
+		bar.Done(err)
 
 		// Demo complete without crawl
 		fmt.Println()
@@ -286,7 +224,7 @@ func main() {
 		fmt.Println()
 		return
 	}
-	spinner.Succeed("Crawl job started")
+	bar.Done(nil)
 
 	jobID := crawlResult.JobId
 	info("Job ID", jobID)
@@ -341,16 +279,15 @@ func main() {
 	// ========== Fetch Job Results ==========
 	header("Job Results")
 
-	spinner = NewSpinner("Fetching job details and results...")
-	spinner.Start()
+	bar = refyne.NewProgressBar(os.Stdout, "Fetching job details and results")
+	bar.Start()
 
 	job, err := client.Jobs.Get(ctx, jobID)
 	if err != nil {
-		spinner.Fail("Failed to fetch job")
-		errorMsg(err.Error())
+		bar.Done(err)
 		os.Exit(1)
 	}
-	spinner.Succeed("Job details retrieved")
+	bar.Done(nil)
 
 	subheader("Job Details")
 	info("ID", job.Id)
@@ -368,16 +305,15 @@ func main() {
 	}
 
 	// Get results (merged)
-	spinner = NewSpinner("Fetching extraction results...")
-	spinner.Start()
+	bar = refyne.NewProgressBar(os.Stdout, "Fetching extraction results")
+	bar.Start()
 
 	results, err := client.Jobs.GetResults(ctx, jobID, &refyne.ResultsOptions{Merge: true})
 	if err != nil {
-		spinner.Fail("Failed to fetch results")
-		errorMsg(err.Error())
+		bar.Done(err)
 		os.Exit(1)
 	}
-	spinner.Succeed("Results retrieved")
+	bar.Done(nil)
 
 	subheader("Extracted Data (Merged)")
 	if len(results) > 0 {
@@ -0,0 +1,192 @@
+package refyne
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Failover defaults used by WithBaseURLs.
+const (
+	// DefaultFailoverThreshold is how many consecutive network errors or
+	// 5xx responses mark an endpoint unhealthy.
+	DefaultFailoverThreshold = 3
+	// DefaultProbeInterval is how often an unhealthy endpoint is probed
+	// for recovery.
+	DefaultProbeInterval = 30 * time.Second
+)
+
+// WithBaseURLs sets several API endpoints in order of preference, e.g. a
+// primary and a DR region. Requests go to the first healthy endpoint. An
+// endpoint is marked unhealthy after consecutive network errors or 5xx
+// responses, and its health check is probed in the background while the
+// client is in use until it recovers. When every endpoint is unhealthy,
+// requests go to the first one.
+func WithBaseURLs(urls []string) ClientOption {
+	return func(c *Client) {
+		if len(urls) == 0 {
+			return
+		}
+		c.baseURL = strings.TrimRight(urls[0], "/")
+		c.endpoints = newEndpointPool(urls, c.failover)
+	}
+}
+
+// WithFailoverPolicy sets how many consecutive failures mark an endpoint
+// unhealthy and how often it is then probed. It only has an effect with
+// WithBaseURLs.
+func WithFailoverPolicy(threshold int, probeInterval time.Duration) ClientOption {
+	return func(c *Client) {
+		c.failover = failoverPolicy{threshold: threshold, probeInterval: probeInterval}
+		if c.endpoints != nil {
+			c.endpoints = newEndpointPool(c.endpoints.urls(), c.failover)
+		}
+	}
+}
+
+// failoverPolicy holds the settings of WithFailoverPolicy.
+type failoverPolicy struct {
+	threshold     int
+	probeInterval time.Duration
+}
+
+// endpointPool tracks the health of the endpoints set by WithBaseURLs.
+type endpointPool struct {
+	policy failoverPolicy
+	now    func() time.Time
+
+	mu        sync.Mutex
+	endpoints []*endpoint
+}
+
+type endpoint struct {
+	url       string
+	failures  int
+	down      bool
+	probing   bool
+	nextProbe time.Time
+}
+
+func newEndpointPool(urls []string, policy failoverPolicy) *endpointPool {
+	if policy.threshold <= 0 {
+		policy.threshold = DefaultFailoverThreshold
+	}
+	if policy.probeInterval <= 0 {
+		policy.probeInterval = DefaultProbeInterval
+	}
+	p := &endpointPool{policy: policy, now: time.Now}
+	for _, u := range urls {
+		p.endpoints = append(p.endpoints, &endpoint{url: strings.TrimRight(u, "/")})
+	}
+	return p
+}
+
+func (p *endpointPool) urls() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	urls := make([]string, len(p.endpoints))
+	for i, e := range p.endpoints {
+		urls[i] = e.url
+	}
+	return urls
+}
+
+// pick returns the base URL to use, starting probes of unhealthy endpoints
+// that are due.
+func (p *endpointPool) pick(probe func(baseURL string) bool) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	for _, e := range p.endpoints {
+		if e.down && !e.probing && !now.Before(e.nextProbe) {
+			e.probing = true
+			go p.probe(e, probe)
+		}
+	}
+	for _, e := range p.endpoints {
+		if !e.down {
+			return e.url
+		}
+	}
+	return p.endpoints[0].url
+}
+
+func (p *endpointPool) probe(e *endpoint, probe func(baseURL string) bool) {
+	ok := probe(e.url)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	e.probing = false
+	if ok {
+		e.down, e.failures = false, 0
+	} else {
+		e.nextProbe = p.now().Add(p.policy.probeInterval)
+	}
+}
+
+// report records the outcome of a request to rawURL and reports whether it
+// marked the endpoint unhealthy.
+func (p *endpointPool) report(rawURL string, ok bool) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, e := range p.endpoints {
+		if !strings.HasPrefix(rawURL, e.url+"/") {
+			continue
+		}
+		if ok {
+			e.down, e.failures = false, 0
+			return false
+		}
+		e.failures++
+		if !e.down && e.failures >= p.policy.threshold {
+			e.down = true
+			e.nextProbe = p.now().Add(p.policy.probeInterval)
+			return true
+		}
+		return false
+	}
+	return false
+}
+
+// endpointURL returns the base URL for a request.
+func (c *Client) endpointURL(ctx context.Context) string {
+	s := c.settings(ctx)
+	if s.endpoints == nil {
+		return s.baseURL
+	}
+	return s.endpoints.pick(c.probeEndpoint)
+}
+
+// reportEndpoint records the outcome of req for failover.
+func (c *Client) reportEndpoint(ctx context.Context, req *http.Request, ok bool) {
+	pool := c.settings(ctx).endpoints
+	if pool == nil {
+		return
+	}
+	if pool.report(req.URL.String(), ok) {
+		c.logger.Warn("Endpoint unhealthy, failing over", map[string]any{
+			"url": req.URL.Scheme + "://" + req.URL.Host,
+		})
+	}
+}
+
+// probeEndpoint checks the health endpoint of baseURL, under the client's
+// API path prefix.
+func (c *Client) probeEndpoint(baseURL string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+c.apiPath(ctx, "/api/v1/health"), nil)
+	if err != nil {
+		return false
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return false
+	}
+	_ = resp.Body.Close()
+	return resp.StatusCode < 500
+}
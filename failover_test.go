@@ -0,0 +1,126 @@
+package refyne
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFailover(t *testing.T) {
+	var primaryUp atomic.Bool
+	var primaryHits, secondaryHits atomic.Int32
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !primaryUp.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.Header.Get("Authorization") != "" {
+			primaryHits.Add(1)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"status": "primary"})
+	}))
+	defer primary.Close()
+	secondary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		secondaryHits.Add(1)
+		_ = json.NewEncoder(w).Encode(map[string]any{"status": "secondary"})
+	}))
+	defer secondary.Close()
+
+	client := NewClient("test-key",
+		WithBaseURLs([]string{primary.URL, secondary.URL}),
+		WithFailoverPolicy(1, time.Minute),
+		WithMaxRetries(1),
+		WithCacheEnabled(false),
+	)
+	now := time.Now()
+	client.endpoints.now = func() time.Time { return now }
+
+	health, err := client.Health(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if health.Status != "secondary" {
+		t.Fatalf("expected failover to the secondary, got %q", health.Status)
+	}
+	if health, _ = client.Health(context.Background()); health.Status != "secondary" {
+		t.Errorf("expected to stay on the secondary, got %q", health.Status)
+	}
+
+	// Once the probe interval passes, a successful probe restores the primary.
+	primaryUp.Store(true)
+	now = now.Add(time.Minute)
+	_, _ = client.Health(context.Background())
+	deadline := time.Now().Add(5 * time.Second)
+	for client.endpoints.pick(func(string) bool { return false }) != primary.URL {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the primary to recover")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if health, _ = client.Health(context.Background()); health.Status != "primary" {
+		t.Errorf("expected requests to return to the primary, got %q", health.Status)
+	}
+	if secondaryHits.Load() != 3 || primaryHits.Load() != 1 {
+		t.Errorf("unexpected hits: primary %d, secondary %d", primaryHits.Load(), secondaryHits.Load())
+	}
+}
+
+func TestFailoverProbeAPIPathPrefix(t *testing.T) {
+	var primaryUp atomic.Bool
+	probes := make(chan string, 1)
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			select {
+			case probes <- r.URL.Path:
+			default:
+			}
+		}
+		if !primaryUp.Load() || r.URL.Path != "/gateway/v1/health" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"status": "primary"})
+	}))
+	defer primary.Close()
+	secondary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"status": "secondary"})
+	}))
+	defer secondary.Close()
+
+	client := NewClient("test-key",
+		WithBaseURLs([]string{primary.URL, secondary.URL}),
+		WithAPIPathPrefix("/gateway/v1"),
+		WithFailoverPolicy(1, time.Minute),
+		WithMaxRetries(1),
+		WithCacheEnabled(false),
+	)
+	now := time.Now()
+	client.endpoints.now = func() time.Time { return now }
+
+	if health, err := client.Health(context.Background()); err != nil || health.Status != "secondary" {
+		t.Fatalf("expected failover to the secondary, got %+v, %v", health, err)
+	}
+
+	primaryUp.Store(true)
+	now = now.Add(time.Minute)
+	_, _ = client.Health(context.Background())
+	select {
+	case path := <-probes:
+		if path != "/gateway/v1/health" {
+			t.Errorf("expected the probe under the API path prefix, got %q", path)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the primary to be probed")
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for client.endpoints.pick(func(string) bool { return false }) != primary.URL {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the primary to recover")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,58 @@
+package refyne
+
+// FailureCategory classifies why a page of a job failed, so remediation
+// such as switching fetch mode or adding a proxy can be driven by rules.
+type FailureCategory string
+
+// Page failure categories.
+const (
+	// FailureFetchBlocked means the site blocked the fetch, e.g. with a
+	// bot challenge or a 403.
+	FailureFetchBlocked FailureCategory = "fetch_blocked"
+	// FailureTimeout means fetching or extracting the page timed out.
+	FailureTimeout FailureCategory = "timeout"
+	// FailureRobotsDenied means robots.txt disallows the page.
+	FailureRobotsDenied FailureCategory = "robots_denied"
+	// FailureLLMError means the LLM provider failed to extract the page.
+	FailureLLMError FailureCategory = "llm_error"
+	// FailureSchemaMismatch means the extracted data did not match the
+	// schema.
+	FailureSchemaMismatch FailureCategory = "schema_mismatch"
+)
+
+// Valid indicates whether the value is a failure category known to this
+// SDK.
+func (c FailureCategory) Valid() bool {
+	switch c {
+	case FailureFetchBlocked, FailureTimeout, FailureRobotsDenied, FailureLLMError, FailureSchemaMismatch:
+		return true
+	default:
+		return false
+	}
+}
+
+// CrawlMap is the crawl map of a job: every page it discovered and how
+// processing each one went.
+type CrawlMap struct {
+	GetCrawlMapOutputBody
+	Entries []CrawlMapPage `json:"entries"`
+}
+
+// CrawlMapPage is a page in a CrawlMap.
+type CrawlMapPage struct {
+	CrawlMapEntry
+	// FailureCategory classifies why the page failed, if it did.
+	FailureCategory FailureCategory `json:"failure_category,omitempty"`
+}
+
+// FailuresByCategory counts the crawl map's failed pages by category.
+// Failed pages without a category are not counted.
+func (m *CrawlMap) FailuresByCategory() map[FailureCategory]int64 {
+	counts := make(map[FailureCategory]int64)
+	for _, page := range m.Entries {
+		if page.Status == "failed" && page.FailureCategory != "" {
+			counts[page.FailureCategory]++
+		}
+	}
+	return counts
+}
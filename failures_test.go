@@ -0,0 +1,56 @@
+package refyne
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFailureCategories(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/jobs/job-1":
+			_, _ = w.Write([]byte(`{"id":"job-1","status":"completed","failures_by_category":{"fetch_blocked":2,"timeout":1}}`))
+		case "/api/v1/jobs/job-1/crawl-map":
+			_, _ = w.Write([]byte(`{"job_id":"job-1","total":3,"entries":[
+				{"url":"https://example.com/a","status":"completed"},
+				{"url":"https://example.com/b","status":"failed","failure_category":"fetch_blocked"},
+				{"url":"https://example.com/c","status":"failed","failure_category":"robots_denied"}]}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL))
+	job, err := client.Jobs.Get(context.Background(), "job-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if job.FailuresByCategory[FailureFetchBlocked] != 2 || job.FailuresByCategory[FailureTimeout] != 1 {
+		t.Errorf("unexpected job failure counts: %v", job.FailuresByCategory)
+	}
+
+	crawlMap, err := client.Jobs.GetCrawlMap(context.Background(), "job-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if crawlMap.Total != 3 || len(crawlMap.Entries) != 3 || crawlMap.Entries[1].Url != "https://example.com/b" {
+		t.Fatalf("unexpected crawl map: %+v", crawlMap)
+	}
+	counts := crawlMap.FailuresByCategory()
+	if len(counts) != 2 || counts[FailureFetchBlocked] != 1 || counts[FailureRobotsDenied] != 1 {
+		t.Errorf("unexpected crawl map failure counts: %v", counts)
+	}
+
+	event, err := ParseEvent([]byte(`{"event":"job.result","job_id":"job-1","data":{"id":"r-1","url":"https://example.com/d","status":"failed","failure_category":"llm_error"}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result, ok := event.(*JobResultEvent)
+	if !ok || result.Data.FailureCategory != FailureLLMError || !result.Data.FailureCategory.Valid() || result.Data.Url != "https://example.com/d" {
+		t.Errorf("unexpected result event: %+v", event)
+	}
+	if FailureCategory("gremlins").Valid() {
+		t.Error("expected an unknown category to be invalid")
+	}
+}
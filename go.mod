@@ -2,7 +2,10 @@ module github.com/jmylchreest/refyne-sdk-go
 
 go 1.21
 
-require github.com/oapi-codegen/runtime v1.1.2
+require (
+	github.com/oapi-codegen/runtime v1.1.2
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/apapsch/go-jsonmerge/v2 v2.0.0 // indirect
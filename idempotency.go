@@ -0,0 +1,45 @@
+package refyne
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+)
+
+// IdempotencyKeyHeader lets the API recognise a retried request, so a POST
+// retried after a network error does not start a second job or charge twice.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// WithIdempotencyKey sends key as the call's Idempotency-Key. Use a key
+// derived from your own work item to make the call safe to repeat across
+// process restarts as well as retries.
+func WithIdempotencyKey(key string) RequestOption {
+	return WithHeader(IdempotencyKeyHeader, key)
+}
+
+// WithAutoIdempotencyKeys generates an Idempotency-Key for each Crawl,
+// Extract and Keys.Create call that does not already have one. The key is
+// reused by every retry of the call.
+func WithAutoIdempotencyKeys() ClientOption {
+	return func(c *Client) {
+		c.idempotency = true
+	}
+}
+
+// withIdempotencyKey returns ctx with a generated Idempotency-Key when
+// automatic keys are enabled and ctx does not carry one.
+func (c *Client) withIdempotencyKey(ctx context.Context) context.Context {
+	if !c.idempotency || headersFromContext(ctx).Get(IdempotencyKeyHeader) != "" {
+		return ctx
+	}
+	return withContextHeader(ctx, IdempotencyKeyHeader, newUUID())
+}
+
+// newUUID returns a random UUIDv4.
+func newUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,37 @@
+package refyne
+
+import (
+	"context"
+	"net/http"
+)
+
+// AccountLimits are the effective limits of the account's plan, including
+// any per-account overrides. Zero means unlimited.
+type AccountLimits struct {
+	// Tier is the plan name, e.g. "free" or "pro".
+	Tier string `json:"tier"`
+	// MaxConcurrentJobs is how many crawl jobs may run at once.
+	MaxConcurrentJobs int64 `json:"max_concurrent_jobs"`
+	// MaxPagesPerCrawl is the largest MaxPages a crawl may use.
+	MaxPagesPerCrawl int64 `json:"max_pages_per_crawl"`
+	// RequestsPerMinute is the API rate limit, and BurstRequests how many
+	// requests may be made at once above it.
+	RequestsPerMinute int64 `json:"requests_per_minute"`
+	BurstRequests     int64 `json:"burst_requests"`
+	// MaxSchemaBytes is the largest schema accepted, in bytes of JSON.
+	MaxSchemaBytes int64 `json:"max_schema_bytes"`
+	// MonthlyExtractions is the extraction allowance per billing period.
+	MonthlyExtractions int64 `json:"monthly_extractions"`
+	UnknownFields      `json:"-"`
+}
+
+// GetLimits returns the account's effective plan limits, so clients can
+// size their concurrency and batches instead of hard-coding guesses.
+func (c *Client) GetLimits(ctx context.Context, reqOpts ...RequestOption) (*AccountLimits, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
+	var result AccountLimits
+	if err := c.request(ctx, http.MethodGet, "/api/v1/limits", nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,82 @@
+package refyne
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGetLimits(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/limits" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		_, _ = w.Write([]byte(`{"tier":"pro","max_concurrent_jobs":5,"max_pages_per_crawl":1000,"requests_per_minute":120,"burst_requests":20,"max_schema_bytes":65536}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL))
+	limits, err := client.GetLimits(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := AccountLimits{Tier: "pro", MaxConcurrentJobs: 5, MaxPagesPerCrawl: 1000, RequestsPerMinute: 120, BurstRequests: 20, MaxSchemaBytes: 65536}
+	if !reflect.DeepEqual(*limits, want) {
+		t.Errorf("expected %+v, got %+v", want, limits)
+	}
+}
+
+func TestAutoTune(t *testing.T) {
+	var limitsLoads, requests int
+	var limitsHeader string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/limits":
+			limitsLoads++
+			limitsHeader = r.Header.Get("X-Tenant")
+			_, _ = w.Write([]byte(`{"requests_per_minute":600,"burst_requests":8}`))
+		case "/api/v1/jobs/stats":
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"error":"slow down"}`))
+		default:
+			requests++
+			_, _ = w.Write([]byte(`{"status":"ok"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL), WithCacheEnabled(false), WithMaxRetries(0), WithAutoTune(true))
+	if got := client.With(WithAutoTune(false)).Concurrency(context.Background()); got != DefaultConcurrency {
+		t.Errorf("expected DefaultConcurrency without auto-tuning, got %d", got)
+	}
+
+	// The burst of 8 is sent at once; the rest are paced at 10 per second.
+	ctx := ContextWithHeader(context.Background(), "X-Tenant", "acme")
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		if _, err := client.Health(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("expected requests to be paced, took %v", elapsed)
+	}
+	if limitsLoads != 1 || limitsHeader != "" {
+		t.Errorf("expected limits loaded once without call headers, got %d loads with %q", limitsLoads, limitsHeader)
+	}
+	if got := client.Concurrency(context.Background()); got != 8 {
+		t.Errorf("expected concurrency from burst limit, got %d", got)
+	}
+
+	var rateLimited *RateLimitError
+	if _, err := client.Jobs.Stats(context.Background()); !errors.As(err, &rateLimited) {
+		t.Fatalf("expected RateLimitError, got %v", err)
+	}
+	if got := client.Concurrency(context.Background()); got != 4 {
+		t.Errorf("expected concurrency halved after a 429, got %d", got)
+	}
+}
@@ -0,0 +1,81 @@
+package refyne
+
+import "time"
+
+// MetricsRecorder receives events from the client so they can be exported
+// to a metrics system. Implementations must be safe for concurrent use.
+//
+// Embed NoopMetricsRecorder to implement only the events you care about.
+type MetricsRecorder interface {
+	// RecordCacheEvent is called for every cache decision.
+	RecordCacheEvent(event CacheEvent)
+
+	// RecordRequestTiming is called after every HTTP attempt.
+	RecordRequestTiming(timing RequestTiming)
+}
+
+// NoopMetricsRecorder is a MetricsRecorder that discards all events.
+type NoopMetricsRecorder struct{}
+
+// RecordCacheEvent implements MetricsRecorder.
+func (NoopMetricsRecorder) RecordCacheEvent(CacheEvent) {}
+
+// RecordRequestTiming implements MetricsRecorder.
+func (NoopMetricsRecorder) RecordRequestTiming(RequestTiming) {}
+
+// RetryReason is why a request was retried.
+type RetryReason string
+
+// Retry reasons.
+const (
+	RetryNetworkError        RetryReason = "network_error"
+	RetryRateLimited         RetryReason = "rate_limited"
+	RetryServerError         RetryReason = "server_error"
+	RetryClassified          RetryReason = "classified"
+	RetryDownloadInterrupted RetryReason = "download_interrupted"
+)
+
+// RetryEvent describes a retry about to be made.
+type RetryEvent struct {
+	Method string
+	Path   string
+	// Attempt is the attempt that failed; the retry is Attempt+1.
+	Attempt int
+	// Delay is how long the client waits before retrying.
+	Delay  time.Duration
+	Reason RetryReason
+	// Status is the HTTP status of the failed attempt, or zero for
+	// network errors.
+	Status    int
+	Err       error
+	RequestID string
+}
+
+// RetryHook is told about every retry, so applications can count retries
+// in their own metrics and alert on elevated retry rates. Implementations
+// must be safe for concurrent use and return quickly.
+type RetryHook interface {
+	OnRetry(event RetryEvent)
+}
+
+// RetryHookFunc adapts a function to a RetryHook.
+type RetryHookFunc func(event RetryEvent)
+
+// OnRetry implements RetryHook.
+func (f RetryHookFunc) OnRetry(event RetryEvent) {
+	f(event)
+}
+
+// WithRetryHook reports every retry to hook.
+func WithRetryHook(hook RetryHook) ClientOption {
+	return func(c *Client) {
+		c.retryHook = hook
+	}
+}
+
+// reportRetry reports a retry to the retry hook, if any.
+func (c *Client) reportRetry(event RetryEvent) {
+	if c.retryHook != nil {
+		c.retryHook.OnRetry(event)
+	}
+}
@@ -0,0 +1,131 @@
+package refyne
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ModelCapability is a feature a model may support.
+type ModelCapability string
+
+// Model capabilities accepted by ModelListOptions.
+const (
+	ModelCapabilityJSONMode ModelCapability = "json_mode"
+	ModelCapabilityTools    ModelCapability = "tools"
+	ModelCapabilityVision   ModelCapability = "vision"
+)
+
+// ModelListOptions pages and filters SearchModels.
+type ModelListOptions struct {
+	Limit  int
+	Offset int
+	// Capability keeps only models supporting it.
+	Capability ModelCapability
+	// MinContextSize keeps only models with at least this many tokens of
+	// context.
+	MinContextSize int64
+}
+
+// SearchModels returns a page of a provider's models matching opts. Use it
+// instead of ListModels for providers with hundreds of models.
+func (l *LLMClient) SearchModels(ctx context.Context, provider string, opts *ModelListOptions, reqOpts ...RequestOption) (*UserListModelsOutputBody, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
+	path := "/api/v1/llm/models/" + provider
+	if opts != nil {
+		params := url.Values{}
+		if opts.Limit > 0 {
+			params.Set("limit", strconv.Itoa(opts.Limit))
+		}
+		if opts.Offset > 0 {
+			params.Set("offset", strconv.Itoa(opts.Offset))
+		}
+		if opts.Capability != "" {
+			params.Set("capability", string(opts.Capability))
+		}
+		if opts.MinContextSize > 0 {
+			params.Set("min_context_size", strconv.FormatInt(opts.MinContextSize, 10))
+		}
+		if len(params) > 0 {
+			path += "?" + params.Encode()
+		}
+	}
+
+	var result UserListModelsOutputBody
+	if err := l.client.request(ctx, http.MethodGet, path, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// FindModel returns the models whose ID or name fuzzily match pattern,
+// best match first. Case and separators are ignored, so "claude sonnet"
+// finds "claude-3-5-sonnet". Exact matches rank above prefixes, prefixes above
+// substrings, and substrings above other in-order matches.
+func FindModel(models []UserModelResponse, pattern string) []UserModelResponse {
+	type match struct {
+		model UserModelResponse
+		score int
+	}
+	var matches []match
+	for _, m := range models {
+		score := max(modelMatchScore(m.Id, pattern), modelMatchScore(m.Name, pattern))
+		if score > 0 {
+			matches = append(matches, match{model: m, score: score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return len(matches[i].model.Id) < len(matches[j].model.Id)
+	})
+
+	found := make([]UserModelResponse, len(matches))
+	for i, m := range matches {
+		found[i] = m.model
+	}
+	return found
+}
+
+// modelMatchScore scores how well candidate matches pattern; zero means no
+// match.
+func modelMatchScore(candidate, pattern string) int {
+	c, p := normalizeModelName(candidate), normalizeModelName(pattern)
+	switch {
+	case p == "" || c == "":
+		return 0
+	case c == p:
+		return 4
+	case strings.HasPrefix(c, p):
+		return 3
+	case strings.Contains(c, p):
+		return 2
+	}
+	// Every pattern character must appear in order.
+	want := []rune(p)
+	i := 0
+	for _, r := range c {
+		if i < len(want) && want[i] == r {
+			i++
+		}
+	}
+	if i == len(want) {
+		return 1
+	}
+	return 0
+}
+
+// normalizeModelName lowercases s and drops separators.
+func normalizeModelName(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '-', '_', '/', '.', ':', ' ':
+			return -1
+		}
+		return r
+	}, strings.ToLower(s))
+}
@@ -0,0 +1,67 @@
+package refyne
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSearchModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/llm/models/openrouter" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		q := r.URL.Query()
+		if q.Get("limit") != "20" || q.Get("capability") != "json_mode" || q.Get("min_context_size") != "128000" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"models": []map[string]any{{"id": "openai/gpt-4o", "name": "GPT-4o"}}})
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL))
+	result, err := client.LLM.SearchModels(context.Background(), "openrouter", &ModelListOptions{
+		Limit:          20,
+		Capability:     ModelCapabilityJSONMode,
+		MinContextSize: 128000,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(*result.Models) != 1 {
+		t.Errorf("expected 1 model, got %d", len(*result.Models))
+	}
+}
+
+func TestFindModel(t *testing.T) {
+	models := []UserModelResponse{
+		{Id: "anthropic/claude-3-5-sonnet-20241022", Name: "Claude 3.5 Sonnet"},
+		{Id: "anthropic/claude-3-haiku", Name: "Claude 3 Haiku"},
+		{Id: "openai/gpt-4o-mini", Name: "GPT-4o mini"},
+		{Id: "openai/gpt-4o", Name: "GPT-4o"},
+	}
+
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{"gpt-4o", []string{"openai/gpt-4o", "openai/gpt-4o-mini"}},
+		{"claude sonnet", []string{"anthropic/claude-3-5-sonnet-20241022"}},
+		{"HAIKU", []string{"anthropic/claude-3-haiku"}},
+		{"llama", nil},
+	}
+	for _, tt := range tests {
+		found := FindModel(models, tt.pattern)
+		if len(found) != len(tt.want) {
+			t.Errorf("FindModel(%q) returned %d models, want %d", tt.pattern, len(found), len(tt.want))
+			continue
+		}
+		for i, m := range found {
+			if m.Id != tt.want[i] {
+				t.Errorf("FindModel(%q)[%d] = %s, want %s", tt.pattern, i, m.Id, tt.want[i])
+			}
+		}
+	}
+}
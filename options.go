@@ -0,0 +1,85 @@
+package refyne
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// RequestOption configures a single API call, overriding the client-wide
+// configuration for that call only.
+type RequestOption func(*requestOptions)
+
+// requestOptions holds the overrides collected from RequestOptions.
+type requestOptions struct {
+	headers   http.Header
+	timeout   time.Duration
+	skipCache bool
+	untuned   bool
+	progress  ProgressReporter
+	// pathPrefix replaces the client's API path prefix.
+	pathPrefix string
+	// accept replaces the Accept header, for streamed responses.
+	accept string
+	// callStart is when the call began, for the retry budget.
+	callStart time.Time
+}
+
+// WithHeader sends an extra HTTP header with the call. Authorization,
+// Content-Type and Accept are set by the SDK and cannot be overridden.
+func WithHeader(key, value string) RequestOption {
+	return func(o *requestOptions) {
+		if o.headers == nil {
+			o.headers = http.Header{}
+		}
+		o.headers.Set(key, value)
+	}
+}
+
+// WithRequestTimeout overrides the client timeout for the call.
+func WithRequestTimeout(timeout time.Duration) RequestOption {
+	return func(o *requestOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithoutCache bypasses the response cache for the call. The response is
+// still stored, so later calls see the fresh value.
+func WithoutCache() RequestOption {
+	return func(o *requestOptions) {
+		o.skipCache = true
+	}
+}
+
+// withAccept sends accept as the call's Accept header.
+func withAccept(accept string) RequestOption {
+	return func(o *requestOptions) {
+		o.accept = accept
+	}
+}
+
+// withRequestOptions returns a copy of ctx carrying opts on top of any
+// options already in ctx.
+func withRequestOptions(ctx context.Context, opts []RequestOption) context.Context {
+	if len(opts) == 0 {
+		return ctx
+	}
+	o := requestOptionsFromContext(ctx)
+	for _, opt := range opts {
+		opt(&o)
+	}
+	for key, values := range o.headers {
+		for _, value := range values {
+			ctx = withContextHeader(ctx, key, value)
+		}
+	}
+	// Headers now live in ctx; only keep the other overrides.
+	o.headers = nil
+	return context.WithValue(ctx, requestOptionsContextKey, o)
+}
+
+// requestOptionsFromContext returns the options stored by withRequestOptions.
+func requestOptionsFromContext(ctx context.Context) requestOptions {
+	o, _ := ctx.Value(requestOptionsContextKey).(requestOptions)
+	return o
+}
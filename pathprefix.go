@@ -0,0 +1,43 @@
+package refyne
+
+import (
+	"context"
+	"strings"
+)
+
+// DefaultAPIPathPrefix is the path prefix of the API version the SDK
+// targets.
+const DefaultAPIPathPrefix = "/api/v1"
+
+// WithAPIPathPrefix sends requests under prefix instead of "/api/v1", e.g.
+// "/api/v2" to target a preview API version. Use WithRequestAPIPathPrefix
+// to switch individual calls while the rest stay on v1.
+func WithAPIPathPrefix(prefix string) ClientOption {
+	return func(c *Client) {
+		c.pathPrefix = strings.TrimRight(prefix, "/")
+	}
+}
+
+// WithRequestAPIPathPrefix sends the call under prefix instead of the
+// client's API path prefix, e.g. "/api/v2" for a preview endpoint.
+func WithRequestAPIPathPrefix(prefix string) RequestOption {
+	return func(o *requestOptions) {
+		o.pathPrefix = strings.TrimRight(prefix, "/")
+	}
+}
+
+// apiPath returns path, written against DefaultAPIPathPrefix, under the
+// prefix that applies to the call.
+func (c *Client) apiPath(ctx context.Context, path string) string {
+	prefix := requestOptionsFromContext(ctx).pathPrefix
+	if prefix == "" {
+		prefix = c.pathPrefix
+	}
+	if prefix == "" || prefix == DefaultAPIPathPrefix {
+		return path
+	}
+	if rest, ok := strings.CutPrefix(path, DefaultAPIPathPrefix); ok && (rest == "" || rest[0] == '/' || rest[0] == '?') {
+		return prefix + rest
+	}
+	return path
+}
@@ -0,0 +1,211 @@
+package refyne
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ProgressUnit is what a Progress counts.
+type ProgressUnit string
+
+// Progress units.
+const (
+	ProgressBytes ProgressUnit = "bytes"
+	ProgressPages ProgressUnit = "pages"
+)
+
+// Progress is a snapshot of a long-running operation.
+type Progress struct {
+	Current int64
+	// Total is zero when it is not known.
+	Total int64
+	Unit  ProgressUnit
+	// Status is the job's status, for operations that wait on a job.
+	Status string
+}
+
+// ProgressReporter receives progress from Jobs.Wait, Jobs.DownloadResults
+// and Jobs.ExportArchive when passed with WithProgress. Implementations
+// must be safe for concurrent use, as one reporter may serve several calls.
+type ProgressReporter interface {
+	// Progress is called as the operation advances.
+	Progress(p Progress)
+	// Done is called once when the operation ends, with its error.
+	Done(err error)
+}
+
+// WithProgress reports the call's progress to r.
+func WithProgress(r ProgressReporter) RequestOption {
+	return func(o *requestOptions) {
+		o.progress = r
+	}
+}
+
+// progressWriter reports bytes written to w.
+type progressWriter struct {
+	w        io.Writer
+	reporter ProgressReporter
+	progress Progress
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.progress.Current += int64(n)
+	p.reporter.Progress(p.progress)
+	return n, err
+}
+
+// progressFrames animate a ProgressBar whose total is unknown.
+var progressFrames = []string{"|", "/", "-", "\\"}
+
+// progressRedraw is the shortest interval between redraws.
+const progressRedraw = 100 * time.Millisecond
+
+// ProgressBar is a ProgressReporter that draws a single-line progress bar
+// on a terminal:
+//
+//	bar := refyne.NewProgressBar(os.Stderr, "Downloading results")
+//	_, err := client.Jobs.DownloadResults(ctx, id, f, nil, refyne.WithProgress(bar))
+//
+// Without a known total it shows a spinner and the count so far.
+type ProgressBar struct {
+	w     io.Writer
+	label string
+	width int
+	now   func() time.Time
+
+	mu       sync.Mutex
+	progress Progress
+	frame    int
+	drawn    time.Time
+	done     bool
+	stop     chan struct{}
+}
+
+// NewProgressBar creates a ProgressBar that draws on w.
+func NewProgressBar(w io.Writer, label string) *ProgressBar {
+	return &ProgressBar{w: w, label: label, width: 30, now: time.Now}
+}
+
+// Start animates the bar until Done, for operations that report no
+// progress of their own.
+func (b *ProgressBar) Start() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.stop != nil || b.done {
+		return
+	}
+	b.stop = make(chan struct{})
+	b.draw()
+	go func(stop chan struct{}) {
+		ticker := time.NewTicker(progressRedraw)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				b.mu.Lock()
+				b.draw()
+				b.mu.Unlock()
+			}
+		}
+	}(b.stop)
+}
+
+// Progress redraws the bar, at most every 100ms.
+func (b *ProgressBar) Progress(p Progress) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.done {
+		return
+	}
+	b.progress = p
+	if b.now().Sub(b.drawn) >= progressRedraw {
+		b.draw()
+	}
+}
+
+// Done replaces the bar with a final [OK] or [FAIL] line.
+func (b *ProgressBar) Done(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.done {
+		return
+	}
+	b.done = true
+	if b.stop != nil {
+		close(b.stop)
+	}
+	if err != nil {
+		fmt.Fprintf(b.w, "\r\033[K[FAIL] %s: %v\n", b.label, err)
+		return
+	}
+	line := "[OK] " + b.label
+	if count := b.count(); count != "" {
+		line += " (" + count + ")"
+	}
+	fmt.Fprintf(b.w, "\r\033[K%s\n", line)
+}
+
+// draw writes the current state. b.mu must be held.
+func (b *ProgressBar) draw() {
+	b.drawn = b.now()
+	p := b.progress
+	var line strings.Builder
+	if p.Total > 0 {
+		filled := int(min(p.Current, p.Total) * int64(b.width) / p.Total)
+		fmt.Fprintf(&line, "%s [%s%s] %3d%%", b.label, strings.Repeat("=", filled), strings.Repeat(" ", b.width-filled), p.Current*100/p.Total)
+	} else {
+		fmt.Fprintf(&line, "%s %s", progressFrames[b.frame], b.label)
+		b.frame = (b.frame + 1) % len(progressFrames)
+	}
+	if count := b.count(); count != "" {
+		line.WriteString(" " + count)
+	}
+	if p.Status != "" {
+		line.WriteString(" (" + p.Status + ")")
+	}
+	fmt.Fprintf(b.w, "\r\033[K%s", line.String())
+}
+
+// count formats the progress so far, e.g. "1.5 MB / 3.0 MB" or "12 pages".
+func (b *ProgressBar) count() string {
+	p := b.progress
+	format := func(n int64) string { return fmt.Sprint(n) }
+	if p.Unit == ProgressBytes {
+		format = formatBytes
+	}
+	switch {
+	case p.Current == 0 && p.Total == 0:
+		return ""
+	case p.Total > 0:
+		return format(p.Current) + " / " + format(p.Total) + unitSuffix(p.Unit)
+	default:
+		return format(p.Current) + unitSuffix(p.Unit)
+	}
+}
+
+func unitSuffix(unit ProgressUnit) string {
+	if unit == ProgressPages {
+		return " pages"
+	}
+	return ""
+}
+
+// formatBytes formats n bytes with a binary-scaled unit.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,110 @@
+package refyne
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingReporter records the progress it receives.
+type recordingReporter struct {
+	mu      sync.Mutex
+	updates []Progress
+	done    int
+	err     error
+}
+
+func (r *recordingReporter) Progress(p Progress) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.updates = append(r.updates, p)
+}
+
+func (r *recordingReporter) Done(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.done++
+	r.err = err
+}
+
+func TestDownloadProgress(t *testing.T) {
+	payload := strings.Repeat("x", 10000)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", strconv.Itoa(len(payload)))
+		_, _ = w.Write([]byte(payload))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL))
+	reporter := &recordingReporter{}
+	var buf bytes.Buffer
+	if _, err := client.Jobs.DownloadResults(context.Background(), "job-1", &buf, nil, WithProgress(reporter)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(reporter.updates) == 0 || reporter.done != 1 || reporter.err != nil {
+		t.Fatalf("expected progress and one Done, got %+v", reporter)
+	}
+	last := reporter.updates[len(reporter.updates)-1]
+	if last.Current != int64(len(payload)) || last.Total != int64(len(payload)) || last.Unit != ProgressBytes {
+		t.Errorf("unexpected final progress: %+v", last)
+	}
+}
+
+func TestWaitProgress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"id":"job-1","status":"completed","page_count":12}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL))
+	reporter := &recordingReporter{}
+	if _, err := client.Jobs.Wait(context.Background(), "job-1", nil, WithProgress(reporter)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(reporter.updates) != 1 || reporter.updates[0].Current != 12 || reporter.updates[0].Status != "completed" || reporter.done != 1 {
+		t.Errorf("unexpected progress: %+v", reporter)
+	}
+}
+
+func TestProgressBar(t *testing.T) {
+	var out bytes.Buffer
+	now := time.Now()
+	bar := NewProgressBar(&out, "Downloading")
+	bar.width = 10
+	bar.now = func() time.Time { return now }
+
+	bar.Progress(Progress{Current: 512, Total: 2048, Unit: ProgressBytes})
+	if got := out.String(); !strings.HasSuffix(got, "Downloading [==        ]  25% 512 B / 2.0 KiB") {
+		t.Errorf("unexpected bar: %q", got)
+	}
+
+	// Updates within the redraw interval are not drawn.
+	out.Reset()
+	bar.Progress(Progress{Current: 1024, Total: 2048, Unit: ProgressBytes})
+	if out.Len() != 0 {
+		t.Errorf("expected no redraw, got %q", out.String())
+	}
+
+	bar.Done(nil)
+	if got := out.String(); !strings.HasSuffix(got, "[OK] Downloading (1.0 KiB / 2.0 KiB)\n") {
+		t.Errorf("unexpected final line: %q", got)
+	}
+
+	out.Reset()
+	bar = NewProgressBar(&out, "Waiting")
+	bar.Progress(Progress{Current: 3, Unit: ProgressPages, Status: "running"})
+	if got := out.String(); !strings.HasSuffix(got, "| Waiting 3 pages (running)") {
+		t.Errorf("unexpected spinner: %q", got)
+	}
+	bar.Done(errors.New("boom"))
+	if got := out.String(); !strings.HasSuffix(got, "[FAIL] Waiting: boom\n") {
+		t.Errorf("unexpected failure line: %q", got)
+	}
+}
@@ -0,0 +1,103 @@
+package refyne
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// ProviderHealth is the operational state of an LLM provider.
+type ProviderHealth string
+
+// Provider health states.
+const (
+	ProviderOperational ProviderHealth = "operational"
+	ProviderDegraded    ProviderHealth = "degraded"
+	ProviderDown        ProviderHealth = "down"
+)
+
+// ProviderStatus is the current state of an LLM provider.
+type ProviderStatus struct {
+	Provider string         `json:"provider"`
+	Status   ProviderHealth `json:"status"`
+	// Message describes an ongoing incident, if any.
+	Message   string `json:"message,omitempty"`
+	UpdatedAt string `json:"updated_at,omitempty"`
+}
+
+// GetProviderStatus returns the current state of each LLM provider, to
+// decide whether to pause work or reorder the fallback chain during an
+// incident.
+func (l *LLMClient) GetProviderStatus(ctx context.Context, reqOpts ...RequestOption) ([]ProviderStatus, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
+	var result struct {
+		Providers []ProviderStatus `json:"providers"`
+	}
+	if err := l.client.request(ctx, http.MethodGet, "/api/v1/llm/providers/status", nil, &result); err != nil {
+		return nil, err
+	}
+	return result.Providers, nil
+}
+
+// ProviderWarning reports that a watched job is using a provider that is
+// not operational.
+type ProviderWarning struct {
+	JobID  string
+	Status ProviderStatus
+}
+
+// WithProviderWarnings makes Jobs.Watch check the provider of each result
+// against GetProviderStatus and warn, once per job and provider, when it is
+// degraded or down. Warnings are logged and passed to fn, which may be nil.
+func WithProviderWarnings(fn func(ProviderWarning)) ClientOption {
+	return func(c *Client) {
+		if fn == nil {
+			fn = func(ProviderWarning) {}
+		}
+		c.providerWarn = fn
+	}
+}
+
+// providerStatusTTL is how long Watch reuses provider statuses.
+const providerStatusTTL = time.Minute
+
+// providerWatcher checks the providers used by a watched job.
+type providerWatcher struct {
+	client    *Client
+	jobID     string
+	statuses  map[string]ProviderStatus
+	fetchedAt time.Time
+	warned    map[string]bool
+}
+
+// check warns if the provider of a result is not operational.
+func (w *providerWatcher) check(ctx context.Context, event *JobResultEvent) {
+	if event.Data.LlmProvider == nil || w.warned[*event.Data.LlmProvider] {
+		return
+	}
+	provider := *event.Data.LlmProvider
+	if w.statuses == nil || time.Since(w.fetchedAt) > providerStatusTTL {
+		statuses, err := w.client.LLM.GetProviderStatus(ctx)
+		if err != nil {
+			w.client.logger.Debug("Failed to get provider status", map[string]any{"error": err.Error()})
+			return
+		}
+		w.statuses, w.fetchedAt = make(map[string]ProviderStatus, len(statuses)), time.Now()
+		for _, s := range statuses {
+			w.statuses[s.Provider] = s
+		}
+	}
+
+	status, ok := w.statuses[provider]
+	if !ok || status.Status == ProviderOperational {
+		return
+	}
+	w.warned[provider] = true
+	w.client.logger.Warn("Job is using a degraded provider", map[string]any{
+		"job_id":   w.jobID,
+		"provider": provider,
+		"status":   string(status.Status),
+		"message":  status.Message,
+	})
+	w.client.providerWarn(ProviderWarning{JobID: w.jobID, Status: status})
+}
@@ -0,0 +1,229 @@
+package refyne
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// QueuedCrawl is a Crawl submission waiting to be replayed by FlushQueue.
+type QueuedCrawl struct {
+	// ID is the submission's Idempotency-Key, so a replay of a request that
+	// did reach the API does not start a second crawl.
+	ID       string     `json:"id"`
+	Input    CrawlInput `json:"input"`
+	QueuedAt time.Time  `json:"queued_at"`
+}
+
+// QueueStore persists queued Crawl submissions. Implementations must be
+// safe for concurrent use.
+type QueueStore interface {
+	Put(entry QueuedCrawl) error
+	List() ([]QueuedCrawl, error)
+	Delete(id string) error
+}
+
+// WithOfflineQueue queues Crawl submissions that cannot reach the API in
+// store instead of failing them, for deployments with flaky connectivity.
+// A submission is queued when it fails with a network error or an open
+// circuit breaker; Crawl then returns a *CrawlQueuedError. Every Crawl gets
+// an Idempotency-Key, which the replay reuses. Call FlushQueue once
+// connectivity returns. Only the input and the key are kept, not other
+// request options.
+func WithOfflineQueue(store QueueStore) ClientOption {
+	return func(c *Client) {
+		c.queue = store
+	}
+}
+
+// withQueueKey returns ctx with a generated Idempotency-Key when the
+// offline queue is enabled and ctx does not carry one.
+func (c *Client) withQueueKey(ctx context.Context) context.Context {
+	if c.queue == nil || headersFromContext(ctx).Get(IdempotencyKeyHeader) != "" {
+		return ctx
+	}
+	return withContextHeader(ctx, IdempotencyKeyHeader, newUUID())
+}
+
+// queueable reports whether a failed submission should be queued: the API
+// was unreachable and the caller did not give up.
+func queueable(ctx context.Context, err error) bool {
+	var netErr *NetworkError
+	var open *CircuitOpenError
+	return ctx.Err() == nil && (errors.As(err, &netErr) || errors.As(err, &open))
+}
+
+func (c *Client) enqueueCrawl(ctx context.Context, input CrawlInput, err error) error {
+	entry := QueuedCrawl{
+		ID:       headersFromContext(ctx).Get(IdempotencyKeyHeader),
+		Input:    input,
+		QueuedAt: time.Now(),
+	}
+	if putErr := c.queue.Put(entry); putErr != nil {
+		return fmt.Errorf("failed to queue crawl after %w: %w", err, putErr)
+	}
+	c.logger.Warn("Crawl queued for replay", map[string]any{"id": entry.ID, "error": err.Error()})
+	return &CrawlQueuedError{ID: entry.ID, Err: err}
+}
+
+// FlushResult is the outcome of FlushQueue.
+type FlushResult struct {
+	// Submitted maps the IDs of replayed submissions to their jobs.
+	Submitted map[string]*CrawlJobResponseBody
+	// Rejected maps the IDs of submissions the API rejected to its error.
+	// They are removed from the queue.
+	Rejected map[string]error
+	// Pending is the number of submissions still queued.
+	Pending int
+}
+
+// FlushQueue replays queued Crawl submissions, oldest first, with their
+// original Idempotency-Keys. It stops at the first submission that still
+// cannot reach the API and returns that error; the rest stay queued.
+func (c *Client) FlushQueue(ctx context.Context, reqOpts ...RequestOption) (*FlushResult, error) {
+	result := &FlushResult{Submitted: map[string]*CrawlJobResponseBody{}, Rejected: map[string]error{}}
+	if c.queue == nil {
+		return result, nil
+	}
+	entries, err := c.queue.List()
+	if err != nil {
+		return result, fmt.Errorf("failed to list queued crawls: %w", err)
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].QueuedAt.Before(entries[j].QueuedAt) })
+
+	ctx = withRequestOptions(ctx, reqOpts)
+	for i, entry := range entries {
+		var job CrawlJobResponseBody
+		err := c.request(withContextHeader(ctx, IdempotencyKeyHeader, entry.ID), http.MethodPost, "/api/v1/crawl", entry.Input, &job)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			result.Pending = len(entries) - i
+			return result, &NetworkError{Err: ctxErr}
+		}
+		if err != nil && queueable(ctx, err) {
+			result.Pending = len(entries) - i
+			return result, err
+		}
+		if err != nil {
+			result.Rejected[entry.ID] = err
+		} else {
+			result.Submitted[entry.ID] = &job
+		}
+		if err := c.queue.Delete(entry.ID); err != nil {
+			result.Pending = len(entries) - i
+			return result, fmt.Errorf("failed to remove queued crawl %s: %w", entry.ID, err)
+		}
+	}
+	return result, nil
+}
+
+// MemoryQueueStore is a QueueStore that keeps submissions in memory, so
+// they survive connectivity loss but not a restart.
+type MemoryQueueStore struct {
+	mu      sync.Mutex
+	entries map[string]QueuedCrawl
+}
+
+// NewMemoryQueueStore creates an empty MemoryQueueStore.
+func NewMemoryQueueStore() *MemoryQueueStore {
+	return &MemoryQueueStore{entries: map[string]QueuedCrawl{}}
+}
+
+// Put stores entry, replacing any with the same ID.
+func (m *MemoryQueueStore) Put(entry QueuedCrawl) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries[entry.ID] = entry
+	return nil
+}
+
+// List returns the stored entries.
+func (m *MemoryQueueStore) List() ([]QueuedCrawl, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entries := make([]QueuedCrawl, 0, len(m.entries))
+	for _, entry := range m.entries {
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// Delete removes the entry with id.
+func (m *MemoryQueueStore) Delete(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.entries, id)
+	return nil
+}
+
+// FileQueueStore is a durable QueueStore that keeps each submission in a
+// JSON file in a directory.
+type FileQueueStore struct {
+	dir string
+}
+
+// NewFileQueueStore creates a FileQueueStore in dir, creating the
+// directory if needed.
+func NewFileQueueStore(dir string) (*FileQueueStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create queue directory: %w", err)
+	}
+	return &FileQueueStore{dir: dir}, nil
+}
+
+// path returns the file for id; IDs are hashed as they may contain any
+// characters.
+func (f *FileQueueStore) path(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return filepath.Join(f.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// Put writes entry atomically, replacing any with the same ID.
+func (f *FileQueueStore) Put(entry QueuedCrawl) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return writeFileDurable(f.path(entry.ID), data)
+}
+
+// List reads the stored entries.
+func (f *FileQueueStore) List() ([]QueuedCrawl, error) {
+	files, err := os.ReadDir(f.dir)
+	if err != nil {
+		return nil, err
+	}
+	var entries []QueuedCrawl
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(f.dir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var entry QueuedCrawl
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return nil, fmt.Errorf("corrupt queue entry %s: %w", file.Name(), err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// Delete removes the entry with id.
+func (f *FileQueueStore) Delete(id string) error {
+	if err := os.Remove(f.path(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package refyne
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestOfflineQueue(t *testing.T) {
+	var online atomic.Bool
+	var mu sync.Mutex
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !online.Load() {
+			// Drop the connection to simulate lost connectivity.
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			_ = conn.Close()
+			return
+		}
+		var input CrawlInput
+		_ = json.NewDecoder(r.Body).Decode(&input)
+		mu.Lock()
+		keys = append(keys, r.Header.Get(IdempotencyKeyHeader))
+		mu.Unlock()
+		if input.URL == "https://bad.example.com" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid url"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"job_id":"job-1","status":"pending"}`))
+	}))
+	defer server.Close()
+
+	store, err := NewFileQueueStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewClient("test-key", WithBaseURL(server.URL), WithMaxRetries(0), WithOfflineQueue(store))
+	ctx := context.Background()
+
+	var queued *CrawlQueuedError
+	_, err = client.Crawl(ctx, CrawlInput{URL: "https://shop.example.com"}, WithIdempotencyKey("crawl-1"))
+	if !errors.As(err, &queued) || queued.ID != "crawl-1" {
+		t.Fatalf("expected CrawlQueuedError, got %v", err)
+	}
+	var netErr *NetworkError
+	if !errors.As(err, &netErr) {
+		t.Errorf("expected the network error to be wrapped, got %v", err)
+	}
+	if _, err := client.Crawl(ctx, CrawlInput{URL: "https://bad.example.com"}); !errors.As(err, &queued) || queued.ID == "" {
+		t.Fatalf("expected CrawlQueuedError with a generated key, got %v", err)
+	}
+	badID := queued.ID
+
+	result, err := client.FlushQueue(ctx)
+	if !errors.As(err, &netErr) || result.Pending != 2 {
+		t.Fatalf("expected the flush to stop while offline, got %+v, %v", result, err)
+	}
+
+	online.Store(true)
+	result, err = client.FlushQueue(ctx)
+	if err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+	if result.Submitted["crawl-1"] == nil || result.Rejected[badID] == nil || result.Pending != 0 {
+		t.Errorf("unexpected flush result: %+v", result)
+	}
+	if len(keys) != 2 || keys[0] != "crawl-1" || keys[1] != badID {
+		t.Errorf("expected replays with the original keys, got %v", keys)
+	}
+	if entries, _ := store.List(); len(entries) != 0 {
+		t.Errorf("expected an empty queue, got %v", entries)
+	}
+}
@@ -0,0 +1,185 @@
+package refyne
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Rate limit headers sent by the API.
+const (
+	RateLimitLimitHeader     = "X-RateLimit-Limit"
+	RateLimitRemainingHeader = "X-RateLimit-Remaining"
+	RateLimitResetHeader     = "X-RateLimit-Reset"
+)
+
+// WithRateLimit paces requests with a token bucket of rps requests per
+// second and bursts of up to burst requests, so bulk runs stay under the
+// API's rate limit rather than running into 429s. The API's
+// X-RateLimit-Remaining and X-RateLimit-Reset headers are honored too:
+// requests slow down to spread the remaining quota until the reset and
+// pause when it is used up. Clients derived with With share the limiter.
+// An rps of zero or less disables it.
+func WithRateLimit(rps float64, burst int) ClientOption {
+	return func(c *Client) {
+		c.limiter = nil
+		if rps > 0 {
+			c.limiter = newRateLimiter(rps, burst)
+		}
+	}
+}
+
+// rateLimiter is a token bucket that also follows the server's rate limit
+// headers.
+type rateLimiter struct {
+	now func() time.Time
+
+	mu sync.Mutex
+	// rate is in requests per second; zero means unlimited.
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	// hintRate spreads the server's remaining quota until hintUntil, the
+	// server's reset time.
+	hintRate  float64
+	hintUntil time.Time
+}
+
+func newRateLimiter(rps float64, burst int) *rateLimiter {
+	l := &rateLimiter{now: time.Now}
+	l.setRate(rps, burst)
+	l.fill()
+	return l
+}
+
+// setRate changes the rate and burst, keeping accumulated tokens up to the
+// new burst.
+func (l *rateLimiter) setRate(rps float64, burst int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rate = math.Max(rps, 0)
+	l.burst = math.Max(float64(burst), 1)
+	l.tokens = math.Min(l.tokens, l.burst)
+}
+
+// fill allows a full burst.
+func (l *rateLimiter) fill() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens = l.burst
+}
+
+// drain discards any accumulated burst, e.g. after a 429.
+func (l *rateLimiter) drain() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens = math.Min(l.tokens, 0)
+}
+
+// wait blocks until a request may be sent.
+func (l *rateLimiter) wait(ctx context.Context, sleep func(context.Context, time.Duration) error) error {
+	for {
+		d := l.reserve()
+		if d == 0 {
+			return nil
+		}
+		if err := sleep(ctx, d); err != nil {
+			return err
+		}
+	}
+}
+
+// reserve takes a token and returns zero, or returns how long until one is
+// available.
+func (l *rateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	rate := l.rate
+	if now.Before(l.hintUntil) && (rate == 0 || l.hintRate < rate) {
+		rate = l.hintRate
+		if rate == 0 {
+			return l.hintUntil.Sub(now)
+		}
+	}
+	if rate == 0 {
+		return 0
+	}
+	if !l.last.IsZero() {
+		l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*rate)
+	}
+	l.last = now
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+	return time.Duration((1 - l.tokens) / rate * float64(time.Second))
+}
+
+// observe reads the server's rate limit headers from a response.
+func (l *rateLimiter) observe(h http.Header) {
+	remaining, err := strconv.ParseFloat(h.Get(RateLimitRemainingHeader), 64)
+	if err != nil {
+		return
+	}
+	reset, ok := parseRateLimitReset(h.Get(RateLimitResetHeader), l.now())
+	if !ok {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	if !reset.After(now) {
+		l.hintUntil = time.Time{}
+		return
+	}
+	l.hintUntil = reset
+	l.hintRate = math.Max(remaining, 0) / reset.Sub(now).Seconds()
+	if remaining <= 0 {
+		l.tokens = math.Min(l.tokens, 0)
+	}
+}
+
+// parseRateLimitReset parses X-RateLimit-Reset, which is either seconds
+// until the reset or, for large values, a Unix timestamp.
+func parseRateLimitReset(value string, now time.Time) (time.Time, bool) {
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || n < 0 {
+		return time.Time{}, false
+	}
+	if n > 1e9 {
+		return time.Unix(0, int64(n*float64(time.Second))), true
+	}
+	return now.Add(time.Duration(n * float64(time.Second))), true
+}
+
+// setQuota fills in e's quota fields from the headers of a 429 response.
+func (e *RateLimitError) setQuota(h http.Header, now time.Time) {
+	if d, ok := parseRetryAfterHeader(h.Get("Retry-After"), now); ok {
+		e.RetryAfter = d
+	}
+	e.Limit, _ = strconv.ParseInt(h.Get(RateLimitLimitHeader), 10, 64)
+	e.Remaining, _ = strconv.ParseInt(h.Get(RateLimitRemainingHeader), 10, 64)
+	if reset, ok := parseRateLimitReset(h.Get(RateLimitResetHeader), now); ok {
+		e.ResetAt = reset
+	}
+}
+
+// parseRetryAfterHeader parses Retry-After, which is either seconds to wait
+// or an HTTP date.
+func parseRetryAfterHeader(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(t.Sub(now), 0), true
+	}
+	return 0, false
+}
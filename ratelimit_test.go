@@ -0,0 +1,111 @@
+package refyne
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	l := newRateLimiter(2, 2)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if d := l.reserve(); d != 0 {
+			t.Fatalf("expected burst request %d to pass, got wait %v", i, d)
+		}
+	}
+	if d := l.reserve(); d != 500*time.Millisecond {
+		t.Errorf("expected to wait 500ms, got %v", d)
+	}
+	now = now.Add(500 * time.Millisecond)
+	if d := l.reserve(); d != 0 {
+		t.Errorf("expected a token after 500ms, got wait %v", d)
+	}
+
+	// Ten requests left for the next 100 seconds slows down to one every 10s.
+	l.observe(rateLimitHeaders("10", "100"))
+	if d := l.reserve(); d != 10*time.Second {
+		t.Errorf("expected the server's quota to be spread, got wait %v", d)
+	}
+
+	// An exhausted quota pauses until the reset, given as a Unix time.
+	reset := time.Unix(1700000030, 0)
+	l.observe(rateLimitHeaders("0", "1700000030"))
+	if d := l.reserve(); d != reset.Sub(now) {
+		t.Errorf("expected to pause until the reset, got wait %v", d)
+	}
+	now = reset
+	if d := l.reserve(); d != 0 {
+		t.Errorf("expected requests to resume after the reset, got wait %v", d)
+	}
+}
+
+func rateLimitHeaders(remaining, reset string) http.Header {
+	h := http.Header{}
+	h.Set(RateLimitRemainingHeader, remaining)
+	h.Set(RateLimitResetHeader, reset)
+	return h
+}
+
+func TestWithRateLimit(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Header().Set(RateLimitRemainingHeader, "0")
+			w.Header().Set(RateLimitResetHeader, "0.2")
+		}
+		_, _ = w.Write([]byte(`{"status":"ok"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL), WithCacheEnabled(false), WithRateLimit(1000, 10))
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		if _, err := client.Health(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("expected to wait for the rate limit reset, took %v", elapsed)
+	}
+}
+
+func TestRateLimitErrorQuota(t *testing.T) {
+	reset := time.Now().Add(time.Minute).Truncate(time.Second)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "7")
+		w.Header().Set(RateLimitLimitHeader, "60")
+		w.Header().Set(RateLimitRemainingHeader, "0")
+		w.Header().Set(RateLimitResetHeader, strconv.FormatInt(reset.Unix(), 10))
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"error":"slow down"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL), WithMaxRetries(0))
+	_, err := client.GetUsage(context.Background())
+	var rateErr *RateLimitError
+	if !errors.As(err, &rateErr) {
+		t.Fatalf("expected RateLimitError, got %v", err)
+	}
+	if rateErr.RetryAfter != 7*time.Second || rateErr.Limit != 60 || rateErr.Remaining != 0 || !rateErr.ResetAt.Equal(reset) {
+		t.Errorf("unexpected quota: retry after %v, limit %d, remaining %d, reset %v", rateErr.RetryAfter, rateErr.Limit, rateErr.Remaining, rateErr.ResetAt)
+	}
+}
+
+func TestParseRetryAfterHeader(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	if d, ok := parseRetryAfterHeader(now.Add(30*time.Second).Format(http.TimeFormat), now); !ok || d != 30*time.Second {
+		t.Errorf("expected an HTTP date 30s ahead, got %v, %v", d, ok)
+	}
+	if _, ok := parseRetryAfterHeader("soon", now); ok {
+		t.Error("expected an invalid value to be rejected")
+	}
+}
@@ -0,0 +1,42 @@
+package refyne
+
+// RegionHeader carries the processing region requested with WithRegion.
+const RegionHeader = "X-Refyne-Region"
+
+// Region is a Refyne processing region.
+type Region string
+
+// Supported regions.
+const (
+	RegionEU Region = "eu"
+	RegionUS Region = "us"
+	RegionAP Region = "ap"
+)
+
+// regionBaseURLs are the API endpoints of each region.
+var regionBaseURLs = map[Region]string{
+	RegionEU: "https://eu.api.refyne.uk",
+	RegionUS: "https://us.api.refyne.uk",
+	RegionAP: "https://ap.api.refyne.uk",
+}
+
+// BaseURL returns the API endpoint of the region, or "" if the region is
+// unknown.
+func (r Region) BaseURL() string {
+	return regionBaseURLs[r]
+}
+
+// WithRegion pins processing to a region for data residency. It sets the
+// base URL to the region's endpoint and sends the region in the
+// X-Refyne-Region header, so the API can refuse work it would otherwise
+// route elsewhere. For an unknown region only the header is sent. A later
+// WithBaseURL overrides the endpoint but keeps the header.
+func WithRegion(region Region) ClientOption {
+	return func(c *Client) {
+		c.region = region
+		if url := region.BaseURL(); url != "" {
+			c.baseURL = url
+			c.endpoints = nil
+		}
+	}
+}
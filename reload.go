@@ -0,0 +1,70 @@
+package refyne
+
+import (
+	"context"
+	"time"
+)
+
+// clientSettings are the client settings that Reload can change. Calls
+// take a snapshot when they start, so a reload never changes the settings
+// of a call in flight.
+type clientSettings struct {
+	apiKey         Secret
+	baseURL        string
+	timeout        time.Duration
+	overallTimeout time.Duration
+	maxRetries     int
+	crawlOpts      *CrawlOptions
+	endpoints      *endpointPool
+}
+
+// Reload atomically replaces the API key, base URL, timeouts, retries and
+// default crawl options with those set in p, e.g. after a SIGHUP or a
+// secret rotation. Empty fields in p keep their current value. Calls
+// already in flight finish with the previous settings. Clients derived
+// with With are not affected.
+func (c *Client) Reload(p *Profile) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !p.APIKey.IsEmpty() {
+		c.apiKey = p.APIKey.clone()
+	}
+	for _, opt := range p.ClientOptions() {
+		opt(c)
+	}
+}
+
+// ZeroAPIKey overwrites c's API key in memory, e.g. when a worker is done
+// with it. Requests made afterwards fail authentication until Reload sets
+// a new key. Clients derived with With hold their own copies and are not
+// affected.
+func (c *Client) ZeroAPIKey() {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	c.apiKey.Zero()
+}
+
+// snapshot returns the current reloadable settings.
+func (c *Client) snapshot() clientSettings {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return clientSettings{
+		apiKey:         c.apiKey,
+		baseURL:        c.baseURL,
+		timeout:        c.timeout,
+		overallTimeout: c.overallTimeout,
+		maxRetries:     c.maxRetries,
+		crawlOpts:      c.crawlOpts,
+		endpoints:      c.endpoints,
+	}
+}
+
+// settings returns the settings snapshot of the call ctx belongs to, or the
+// current settings outside a call.
+func (c *Client) settings(ctx context.Context) clientSettings {
+	if s, ok := ctx.Value(settingsContextKey).(clientSettings); ok {
+		return s
+	}
+	return c.snapshot()
+}
@@ -0,0 +1,55 @@
+package refyne
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestReplayProtection(t *testing.T) {
+	nonces := NewMemoryNonceStore()
+	var captured *http.Request
+	var verifyErr error
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			if r.Header.Get(NonceHeader) != "" {
+				t.Error("expected GET requests to be unsigned")
+			}
+		} else {
+			verifyErr = VerifyRequestSignature(r, "test-key", 0, nonces)
+			captured = r.Clone(context.Background())
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"job_id": "job-1", "status": "ok"})
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL), WithReplayProtection())
+	if _, err := client.Crawl(context.Background(), CrawlInput{URL: "https://example.com"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if verifyErr != nil {
+		t.Fatalf("expected a valid signature, got %v", verifyErr)
+	}
+	if _, err := client.Health(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := VerifyRequestSignature(captured, "test-key", 0, nonces); !errors.Is(err, ErrReplayedRequest) {
+		t.Errorf("expected ErrReplayedRequest for a replay, got %v", err)
+	}
+	if err := VerifyRequestSignature(captured, "other-key", 0, nil); !errors.Is(err, ErrInvalidRequestSignature) {
+		t.Errorf("expected ErrInvalidRequestSignature for the wrong key, got %v", err)
+	}
+
+	stale := captured.Clone(context.Background())
+	stale.Header.Set(TimestampHeader, strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10))
+	if err := VerifyRequestSignature(stale, "test-key", 0, nil); !errors.Is(err, ErrInvalidRequestSignature) {
+		t.Errorf("expected ErrInvalidRequestSignature for a stale request, got %v", err)
+	}
+}
@@ -0,0 +1,138 @@
+package refyne
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Chunk sizes used when results are fetched in chunks.
+const (
+	// DefaultResultsChunkSize is the size of the first chunk.
+	DefaultResultsChunkSize = 100
+
+	minResultsChunkSize = 10
+	maxResultsChunkSize = 5000
+)
+
+// resultsChunk is one page of results fetched with a cursor.
+type resultsChunk struct {
+	Results    []json.RawMessage `json:"results"`
+	NextCursor string            `json:"next_cursor"`
+	jobProgress
+}
+
+// jobProgress is the job status the API sends in place of results while a
+// job is still running.
+type jobProgress struct {
+	Status    string `json:"status"`
+	PageCount *int64 `json:"page_count"`
+}
+
+// unfinishedJobStatuses are the job statuses whose results are not ready.
+var unfinishedJobStatuses = map[string]bool{"pending": true, "queued": true, "running": true}
+
+// notReady returns a *ResultsNotReadyError if p describes an unfinished job.
+func (p jobProgress) notReady() error {
+	if p.PageCount == nil || !unfinishedJobStatuses[p.Status] {
+		return nil
+	}
+	return &ResultsNotReadyError{Status: p.Status, PageCount: *p.PageCount}
+}
+
+// resultsNotReady returns a *ResultsNotReadyError if body is the status of
+// an unfinished job rather than its results.
+func resultsNotReady(body json.RawMessage) error {
+	var p jobProgress
+	if len(body) == 0 || body[0] != '{' || json.Unmarshal(body, &p) != nil {
+		return nil
+	}
+	return p.notReady()
+}
+
+// StreamResults fetches the results of a job in chunks and calls fn with
+// each result in order, so very large result sets neither time out in a
+// single request nor have to be held in memory. Chunks are sized from the
+// observed throughput so each round trip takes about half the per-attempt
+// timeout, and a chunk that times out is retried at half the size.
+// opts.ChunkSize sets the size of the first chunk. If the job has not
+// finished it returns a *ResultsNotReadyError.
+func (j *JobsClient) StreamResults(ctx context.Context, id string, opts *ResultsOptions, fn func(result json.RawMessage) error, reqOpts ...RequestOption) error {
+	ctx = withRequestOptions(ctx, reqOpts)
+	if opts == nil {
+		opts = &ResultsOptions{}
+	}
+	size := DefaultResultsChunkSize
+	if opts.ChunkSize > 0 {
+		size = opts.ChunkSize
+	}
+	budget := requestOptionsFromContext(ctx).timeout
+	if budget == 0 {
+		budget = j.client.snapshot().timeout
+	}
+
+	cursor := ""
+	for {
+		start := time.Now()
+		chunk, err := j.resultsChunk(ctx, id, opts.Merge, cursor, size)
+		if err != nil {
+			if isTimeout(err) && ctx.Err() == nil && size > minResultsChunkSize {
+				size = max(minResultsChunkSize, size/2)
+				j.client.logger.Warn("Results chunk timed out, retrying smaller", map[string]any{"chunk_size": size})
+				continue
+			}
+			return err
+		}
+		elapsed := time.Since(start)
+
+		for _, result := range chunk.Results {
+			if err := fn(result); err != nil {
+				return err
+			}
+		}
+		if chunk.NextCursor == "" {
+			return nil
+		}
+		cursor = chunk.NextCursor
+		size = nextChunkSize(size, len(chunk.Results), elapsed, budget)
+	}
+}
+
+func (j *JobsClient) resultsChunk(ctx context.Context, id string, merge bool, cursor string, size int) (*resultsChunk, error) {
+	query := url.Values{"limit": {strconv.Itoa(size)}}
+	if cursor != "" {
+		query.Set("cursor", cursor)
+	}
+	if merge {
+		query.Set("merge", "true")
+	}
+	var chunk resultsChunk
+	if err := j.client.request(ctx, http.MethodGet, "/api/v1/jobs/"+id+"/results?"+query.Encode(), nil, &chunk); err != nil {
+		return nil, err
+	}
+	if err := chunk.notReady(); err != nil {
+		return nil, err
+	}
+	return &chunk, nil
+}
+
+// nextChunkSize sizes the next chunk so it takes about half of budget at
+// the throughput of the last one, growing at most twofold per chunk.
+func nextChunkSize(size, n int, elapsed, budget time.Duration) int {
+	if budget <= 0 || n == 0 || elapsed <= 0 {
+		return size
+	}
+	target := int(float64(n) / elapsed.Seconds() * budget.Seconds() / 2)
+	return min(max(target, minResultsChunkSize), 2*size, maxResultsChunkSize)
+}
+
+// isTimeout reports whether err is a request timing out.
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())
+}
@@ -0,0 +1,120 @@
+package refyne
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStreamResults(t *testing.T) {
+	const total = 120
+	var mu sync.Mutex
+	var limits []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("cursor"))
+		mu.Lock()
+		limits = append(limits, limit)
+		mu.Unlock()
+		if r.URL.Query().Get("merge") != "true" {
+			t.Errorf("expected merge to be passed, got %s", r.URL.RawQuery)
+		}
+		// Large chunks are too slow for the client timeout.
+		if limit > 40 {
+			time.Sleep(100 * time.Millisecond)
+		}
+		chunk := resultsChunk{}
+		for i := offset; i < total && i < offset+limit; i++ {
+			chunk.Results = append(chunk.Results, json.RawMessage(strconv.Itoa(i)))
+		}
+		if offset+limit < total {
+			chunk.NextCursor = strconv.Itoa(offset + limit)
+		}
+		_ = json.NewEncoder(w).Encode(chunk)
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL), WithCacheEnabled(false), WithMaxRetries(0), WithTimeout(50*time.Millisecond))
+	var got []int
+	err := client.Jobs.StreamResults(context.Background(), "job-1", &ResultsOptions{Merge: true, ChunkSize: 160}, func(result json.RawMessage) error {
+		n, _ := strconv.Atoi(string(result))
+		got = append(got, n)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != total || got[0] != 0 || got[total-1] != total-1 {
+		t.Errorf("expected %d results in order, got %v", total, got)
+	}
+	if limits[0] != 160 || limits[1] != 80 || limits[2] != 40 {
+		t.Errorf("expected timed-out chunks to be halved, got limits %v", limits)
+	}
+
+	results, err := client.Jobs.GetResults(context.Background(), "job-1", &ResultsOptions{Merge: true, Chunked: true, ChunkSize: 20})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var all []int
+	if err := json.Unmarshal(results, &all); err != nil || len(all) != total {
+		t.Errorf("expected a JSON array of %d results, got %d (%v)", total, len(all), err)
+	}
+}
+
+func TestNextChunkSize(t *testing.T) {
+	tests := []struct {
+		size, n         int
+		elapsed, budget time.Duration
+		want            int
+	}{
+		{100, 100, time.Second, 10 * time.Second, 200},
+		{100, 100, time.Second, 3 * time.Second, 150},
+		{100, 100, time.Second, time.Second, 50},
+		{100, 100, 10 * time.Second, time.Second, minResultsChunkSize},
+		{100, 100, time.Second, 0, 100},
+	}
+	for _, tt := range tests {
+		if got := nextChunkSize(tt.size, tt.n, tt.elapsed, tt.budget); got != tt.want {
+			t.Errorf("nextChunkSize(%d, %d, %v, %v) = %d, want %d", tt.size, tt.n, tt.elapsed, tt.budget, got, tt.want)
+		}
+	}
+}
+
+func TestResultsNotReady(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/jobs/running/results":
+			_, _ = w.Write([]byte(`{"id":"running","status":"running","page_count":7}`))
+		case "/api/v1/jobs/product/results":
+			// A result that happens to have a status field is not a job.
+			_, _ = w.Write([]byte(`{"name":"Widget","status":"pending"}`))
+		default:
+			_, _ = w.Write([]byte(`[]`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL), WithCacheEnabled(false))
+	ctx := context.Background()
+
+	var notReady *ResultsNotReadyError
+	if _, err := client.Jobs.GetResults(ctx, "running", nil); !errors.As(err, &notReady) || notReady.Status != "running" || notReady.PageCount != 7 {
+		t.Fatalf("expected ResultsNotReadyError, got %v", err)
+	}
+	err := client.Jobs.StreamResults(ctx, "running", nil, func(json.RawMessage) error { return nil })
+	if !errors.As(err, &notReady) {
+		t.Errorf("expected StreamResults to return ResultsNotReadyError, got %v", err)
+	}
+	if _, err := client.Jobs.GetResults(ctx, "product", nil); err != nil {
+		t.Errorf("expected results, got %v", err)
+	}
+	if results, err := client.Jobs.GetResults(ctx, "done", nil); err != nil || string(results) != "[]" {
+		t.Errorf("expected empty results, got %s, %v", results, err)
+	}
+}
@@ -0,0 +1,165 @@
+package refyne
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BackoffHintHeader is sent by the API, e.g. during maintenance, with how
+// long clients should wait before retrying: seconds, possibly fractional,
+// or a duration such as "1500ms".
+const BackoffHintHeader = "X-Backoff-Hint"
+
+// Operation identifies an API call, or a class of calls, for per-operation
+// retry settings.
+type Operation string
+
+// Operation classes. Calls without a more specific Operation fall into
+// OperationRead if they are GET requests and OperationWrite otherwise.
+const (
+	OperationRead  Operation = "read"
+	OperationWrite Operation = "write"
+)
+
+// Operations with their own retry settings.
+const (
+	OperationExtract        Operation = "Extract"
+	OperationCrawl          Operation = "Crawl"
+	OperationAnalyze        Operation = "Analyze"
+	OperationSearch         Operation = "Search"
+	OperationJobsList       Operation = "Jobs.List"
+	OperationJobsGet        Operation = "Jobs.Get"
+	OperationJobsGetResults Operation = "Jobs.GetResults"
+	OperationJobsStats      Operation = "Jobs.Stats"
+	OperationKeysList       Operation = "Keys.List"
+	OperationKeysCreate     Operation = "Keys.Create"
+	OperationKeysRevoke     Operation = "Keys.Revoke"
+)
+
+// operationRoutes maps requests to operations. Paths are relative to
+// /api/v1 and * matches one segment; the first match wins.
+var operationRoutes = []struct {
+	method string
+	path   string
+	op     Operation
+}{
+	{http.MethodPost, "extract", OperationExtract},
+	{http.MethodPost, "crawl", OperationCrawl},
+	{http.MethodPost, "analyze", OperationAnalyze},
+	{http.MethodPost, "search", OperationSearch},
+	{http.MethodGet, "jobs", OperationJobsList},
+	{http.MethodGet, "jobs/stats", OperationJobsStats},
+	{http.MethodGet, "jobs/*", OperationJobsGet},
+	{http.MethodGet, "jobs/*/results", OperationJobsGetResults},
+	{http.MethodGet, "keys", OperationKeysList},
+	{http.MethodPost, "keys", OperationKeysCreate},
+	{http.MethodDelete, "keys/*", OperationKeysRevoke},
+}
+
+// RetryConfig is the retry policy for an Operation.
+type RetryConfig struct {
+	// MaxRetries is the number of retries after the first attempt. Zero
+	// never retries.
+	MaxRetries int
+	// Backoff scales the default backoff, which starts at one second and
+	// doubles per retry, e.g. 100ms makes every wait a tenth as long. Zero
+	// keeps the default.
+	Backoff time.Duration
+}
+
+// WithEndpointRetryOverrides replaces the client's retry settings for the
+// given operations, since a blanket policy suits read-only calls and
+// mutations differently:
+//
+//	refyne.WithEndpointRetryOverrides(map[refyne.Operation]refyne.RetryConfig{
+//	    refyne.OperationKeysCreate: {MaxRetries: 0},
+//	    refyne.OperationJobsGet:    {MaxRetries: 10, Backoff: 200 * time.Millisecond},
+//	})
+//
+// A specific operation takes precedence over its class.
+func WithEndpointRetryOverrides(overrides map[Operation]RetryConfig) ClientOption {
+	return func(c *Client) {
+		c.retryOverrides = make(map[Operation]RetryConfig, len(overrides))
+		for op, cfg := range overrides {
+			c.retryOverrides[op] = cfg
+		}
+	}
+}
+
+// operationOf returns the Operation of a request, or "" if it has none.
+func operationOf(method, path string) Operation {
+	path, _, _ = strings.Cut(path, "?")
+	segments := strings.Split(strings.TrimPrefix(path, "/api/v1/"), "/")
+	for _, route := range operationRoutes {
+		if route.method == method && matchSegments(strings.Split(route.path, "/"), segments) {
+			return route.op
+		}
+	}
+	return ""
+}
+
+func matchSegments(pattern, segments []string) bool {
+	if len(pattern) != len(segments) {
+		return false
+	}
+	for i, p := range pattern {
+		if p != "*" && p != segments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// retryConfig returns the retry policy for a request.
+func (c *Client) retryConfig(ctx context.Context, method, path string) RetryConfig {
+	if len(c.retryOverrides) > 0 {
+		if cfg, ok := c.retryOverrides[operationOf(method, path)]; ok {
+			return cfg
+		}
+		class := OperationWrite
+		if method == http.MethodGet || method == http.MethodHead {
+			class = OperationRead
+		}
+		if cfg, ok := c.retryOverrides[class]; ok {
+			return cfg
+		}
+	}
+	return RetryConfig{MaxRetries: c.settings(ctx).maxRetries}
+}
+
+// retryBackoff returns the wait before retry attempt under cfg.
+func (c *Client) retryBackoff(cfg RetryConfig, attempt int) time.Duration {
+	backoff := c.calculateBackoff(attempt)
+	if cfg.Backoff > 0 {
+		backoff = time.Duration(float64(backoff) * cfg.Backoff.Seconds())
+	}
+	return backoff
+}
+
+// backoffHint returns the wait the server asked for in X-Backoff-Hint.
+func backoffHint(h http.Header) (time.Duration, bool) {
+	hint := h.Get(BackoffHintHeader)
+	if hint == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.ParseFloat(hint, 64); err == nil && seconds >= 0 {
+		return time.Duration(seconds * float64(time.Second)), true
+	}
+	if d, err := time.ParseDuration(hint); err == nil && d >= 0 {
+		return d, true
+	}
+	return 0, false
+}
+
+// serverBackoff returns the wait the server asked for before retrying a
+// failed response, in X-Backoff-Hint or Retry-After, in place of the
+// client's own backoff.
+func serverBackoff(h http.Header) (time.Duration, bool) {
+	if d, ok := backoffHint(h); ok {
+		return d, true
+	}
+	return parseRetryAfterHeader(h.Get("Retry-After"), time.Now())
+}
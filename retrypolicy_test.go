@@ -0,0 +1,108 @@
+package refyne
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestOperationOf(t *testing.T) {
+	tests := []struct {
+		method, path string
+		want         Operation
+	}{
+		{http.MethodPost, "/api/v1/keys", OperationKeysCreate},
+		{http.MethodGet, "/api/v1/keys", OperationKeysList},
+		{http.MethodGet, "/api/v1/jobs/job-1?wait=30", OperationJobsGet},
+		{http.MethodGet, "/api/v1/jobs/stats", OperationJobsStats},
+		{http.MethodGet, "/api/v1/jobs/job-1/results?merge=true", OperationJobsGetResults},
+		{http.MethodGet, "/api/v1/jobs/job-1/quality", ""},
+		{http.MethodPut, "/api/v1/schemas/s-1", ""},
+	}
+	for _, tt := range tests {
+		if got := operationOf(tt.method, tt.path); got != tt.want {
+			t.Errorf("operationOf(%s %s) = %q, want %q", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestEndpointRetryOverrides(t *testing.T) {
+	var mu sync.Mutex
+	hits := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits[r.Method+" "+r.URL.Path]++
+		mu.Unlock()
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL), WithCacheEnabled(false), WithMaxRetries(3),
+		WithEndpointRetryOverrides(map[Operation]RetryConfig{
+			OperationKeysCreate: {MaxRetries: 0},
+			OperationJobsGet:    {MaxRetries: 2, Backoff: 10 * time.Millisecond},
+			OperationRead:       {MaxRetries: 1, Backoff: 10 * time.Millisecond},
+		}))
+	ctx := context.Background()
+
+	start := time.Now()
+	_, _ = client.Keys.Create(ctx, "ci")
+	_, _ = client.Jobs.Get(ctx, "job-1")
+	_, _ = client.Schemas.List(ctx)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected scaled backoff, took %v", elapsed)
+	}
+
+	want := map[string]int{
+		"POST /api/v1/keys":      1,
+		"GET /api/v1/jobs/job-1": 3,
+		"GET /api/v1/schemas":    2,
+	}
+	for call, n := range want {
+		if hits[call] != n {
+			t.Errorf("%s: expected %d attempts, got %d", call, n, hits[call])
+		}
+	}
+}
+
+func TestServerBackoffHints(t *testing.T) {
+	tests := []struct {
+		name   string
+		header http.Header
+		want   time.Duration
+	}{
+		{"retry after on 503", http.Header{"Retry-After": {"0"}}, 0},
+		{"backoff hint in seconds", http.Header{BackoffHintHeader: {"0.05"}, "Retry-After": {"30"}}, 50 * time.Millisecond},
+		{"backoff hint as duration", http.Header{BackoffHintHeader: {"20ms"}}, 20 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if calls == 1 {
+					for k, v := range tt.header {
+						w.Header()[k] = v
+					}
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				_, _ = w.Write([]byte(`{"total":1}`))
+			}))
+			defer server.Close()
+
+			var delays []time.Duration
+			client := NewClient("test-key", WithBaseURL(server.URL), WithCacheEnabled(false), WithMaxRetries(1),
+				WithRetryHook(RetryHookFunc(func(e RetryEvent) { delays = append(delays, e.Delay) })))
+			if _, err := client.Jobs.Stats(context.Background()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(delays) != 1 || delays[0] != tt.want {
+				t.Errorf("expected one retry after %v, got %v", tt.want, delays)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package refyne
+
+import (
+	"bytes"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ParseSchemaYAML parses a schema in the YAML form used by SchemasClient into
+// the map form accepted by Extract and Crawl. Nested mappings are returned
+// as map[string]any so the result can be marshalled as JSON.
+func ParseSchemaYAML(schemaYAML string) (map[string]any, error) {
+	var raw any
+	if err := yaml.Unmarshal([]byte(schemaYAML), &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse schema YAML: %w", err)
+	}
+	if raw == nil {
+		return map[string]any{}, nil
+	}
+	schema, ok := normalizeYAML(raw).(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("schema YAML must be a mapping, got %T", raw)
+	}
+	return schema, nil
+}
+
+// SchemaToYAML renders a schema map as YAML for SchemasClient. Keys are
+// sorted so the output is stable and diffs cleanly.
+func SchemaToYAML(schema map[string]any) (string, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(schema); err != nil {
+		return "", fmt.Errorf("failed to encode schema YAML: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return "", fmt.Errorf("failed to encode schema YAML: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// normalizeYAML converts mappings with non-string keys, which encoding/json
+// cannot marshal, into map[string]any.
+func normalizeYAML(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, item := range v {
+			v[k] = normalizeYAML(item)
+		}
+		return v
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for k, item := range v {
+			m[fmt.Sprint(k)] = normalizeYAML(item)
+		}
+		return m
+	case []any:
+		for i, item := range v {
+			v[i] = normalizeYAML(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
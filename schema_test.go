@@ -0,0 +1,247 @@
+package refyne
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseSchemaYAML(t *testing.T) {
+	schema, err := ParseSchemaYAML(`
+name: string
+price: number
+variants:
+  - sku: string
+    1: number
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if schema["name"] != "string" || schema["price"] != "number" {
+		t.Errorf("unexpected top-level fields: %v", schema)
+	}
+	if _, err := json.Marshal(schema); err != nil {
+		t.Errorf("expected parsed schema to be JSON-marshalable: %v", err)
+	}
+
+	if _, err := ParseSchemaYAML("- a\n- b\n"); err == nil {
+		t.Error("expected error for non-mapping schema")
+	}
+	if _, err := ParseSchemaYAML("name: [unterminated"); err == nil {
+		t.Error("expected error for malformed YAML")
+	}
+}
+
+func TestSchemaToYAML(t *testing.T) {
+	out, err := SchemaToYAML(map[string]any{
+		"title": "string",
+		"price": "number",
+		"author": map[string]any{
+			"name": "string",
+			"bio":  "string",
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "author:\n  bio: string\n  name: string\nprice: number\ntitle: string\n"
+	if out != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, out)
+	}
+
+	roundTrip, err := ParseSchemaYAML(out)
+	if err != nil {
+		t.Fatalf("unexpected error parsing output: %v", err)
+	}
+	if roundTrip["title"] != "string" {
+		t.Errorf("unexpected round trip result: %v", roundTrip)
+	}
+}
+
+func TestCreateSchemaInputValidate(t *testing.T) {
+	valid := CreateSchemaInput{Name: "products", SchemaYAML: "name: string\n", Visibility: "private"}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("unexpected error for valid input: %v", err)
+	}
+
+	err := CreateSchemaInput{SchemaYAML: "name: [unterminated", Visibility: "secret"}.Validate()
+	verr, ok := err.(*ValidationError)
+	if !ok {
+		t.Fatalf("expected *ValidationError, got %T: %v", err, err)
+	}
+	for _, field := range []string{"name", "schema_yaml", "visibility"} {
+		if verr.Fields[field] == "" {
+			t.Errorf("expected error for field %q, got %v", field, verr.Fields)
+		}
+	}
+}
+
+func TestSchemasCreateValidatesBeforeSending(t *testing.T) {
+	client := NewClient("test-key", WithBaseURL("http://127.0.0.1:0"))
+	_, err := client.Schemas.Create(context.Background(), CreateSchemaInput{Name: "broken", SchemaYAML: ": :"})
+	if _, ok := err.(*ValidationError); !ok {
+		t.Errorf("expected *ValidationError without a request, got %T: %v", err, err)
+	}
+}
+
+func TestSchemasUpdateValidatesSetFields(t *testing.T) {
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "s-1", "name": "products", "visibility": "public"})
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL))
+	if _, err := client.Schemas.Update(context.Background(), "s-1", CreateSchemaInput{Visibility: "public"}); err != nil {
+		t.Fatalf("expected a partial update to be sent, got %v", err)
+	}
+	if len(body) != 1 || body["visibility"] != "public" {
+		t.Errorf("expected only the set field to be sent, got %v", body)
+	}
+
+	_, err := client.Schemas.Update(context.Background(), "s-1", CreateSchemaInput{Name: " ", SchemaYAML: ": :"})
+	verr, ok := err.(*ValidationError)
+	if !ok || verr.Fields["name"] == "" || verr.Fields["schema_yaml"] == "" {
+		t.Errorf("expected the set fields to be validated, got %v", err)
+	}
+}
+
+func TestLintSchema(t *testing.T) {
+	schema, err := ParseSchemaYAML(`
+title:
+  type: string
+  description: The product's name as shown in the heading
+price: number
+data:
+  type: string
+  description: Anything else
+variants:
+  - sku: string
+    value2:
+      type: number
+      description: Variant price
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []string
+	for _, issue := range LintSchema(schema) {
+		got = append(got, issue.Field+" "+string(issue.Kind))
+	}
+	want := []string{
+		"data ambiguous_name",
+		"price missing_description",
+		"variants[].sku missing_description",
+		"variants[].value2 ambiguous_name",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("issue %d: expected %s, got %s", i, want[i], got[i])
+		}
+	}
+}
+
+func TestSchemasLintCritique(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/schemas/critique" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var body struct {
+			Schema map[string]any `json:"schema"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body.Schema["price"] != "number" {
+			t.Errorf("unexpected schema %v", body.Schema)
+		}
+		_, _ = w.Write([]byte(`{"score":0.4,"summary":"Add descriptions","suggestions":[{"field":"price","message":"Specify the currency","description":"Price in USD"}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL))
+	schema := map[string]any{"price": "number"}
+
+	lint, err := client.Schemas.Lint(context.Background(), schema, false)
+	if err != nil || len(lint.Issues) != 1 || lint.Critique != nil {
+		t.Fatalf("expected a local lint only, got %+v, %v", lint, err)
+	}
+	lint, err = client.Schemas.Lint(context.Background(), schema, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if lint.Critique == nil || lint.Critique.Score != 0.4 || lint.Critique.Suggestions[0].Description != "Price in USD" {
+		t.Errorf("unexpected critique: %+v", lint.Critique)
+	}
+}
+
+func TestMergeSchemas(t *testing.T) {
+	listing := map[string]any{
+		"title": "string",
+		"price": "number",
+		"variants": []any{map[string]any{
+			"sku": "string",
+		}},
+	}
+	detail := map[string]any{
+		"title": map[string]any{"type": "string", "description": "Product name"},
+		"price": "string",
+		"seller": map[string]any{
+			"name": "string",
+		},
+		"variants": []any{map[string]any{
+			"sku":   "string",
+			"stock": "integer",
+		}},
+	}
+	sale := map[string]any{
+		"price": "boolean",
+		"seller": map[string]any{
+			"rating": "number",
+		},
+	}
+
+	merged, conflicts := MergeSchemas(listing, detail, sale)
+
+	if title, _ := merged["title"].(map[string]any); title["type"] != "string" || title["description"] != "Product name" {
+		t.Errorf("expected title to gain its description, got %v", merged["title"])
+	}
+	if merged["price"] != "number" {
+		t.Errorf("expected the first price type to be kept, got %v", merged["price"])
+	}
+	seller, _ := merged["seller"].(map[string]any)
+	if seller["name"] != "string" || seller["rating"] != "number" {
+		t.Errorf("expected seller fields to be unioned, got %v", seller)
+	}
+	variant, _ := merged["variants"].([]any)[0].(map[string]any)
+	if variant["sku"] != "string" || variant["stock"] != "integer" {
+		t.Errorf("expected variant fields to be unioned, got %v", variant)
+	}
+
+	if len(conflicts) != 1 || conflicts[0].Field != "price" || len(conflicts[0].Types) != 3 {
+		t.Fatalf("expected one price conflict, got %v", conflicts)
+	}
+	if got := conflicts[0].String(); got != "price: number vs string vs boolean" {
+		t.Errorf("unexpected conflict %q", got)
+	}
+	if _, ok := listing["variants"].([]any)[0].(map[string]any)["stock"]; ok {
+		t.Error("expected the inputs to be left unmodified")
+	}
+}
+
+func TestAnalyzeOutputSchema(t *testing.T) {
+	out := &AnalyzeOutput{AnalyzeResponseBody: AnalyzeResponseBody{SuggestedSchema: `{"title": "string", "tags": ["string"]}`}}
+	schema, err := out.Schema()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if schema["title"] != "string" {
+		t.Errorf("unexpected schema: %v", schema)
+	}
+}
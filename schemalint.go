@@ -0,0 +1,156 @@
+package refyne
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// SchemaIssueKind is the kind of problem LintSchema found.
+type SchemaIssueKind string
+
+// Schema issue kinds.
+const (
+	// SchemaIssueMissingDescription is a field without a description. The
+	// description is what the model extracts by, so it matters more than
+	// anything else in the schema.
+	SchemaIssueMissingDescription SchemaIssueKind = "missing_description"
+	// SchemaIssueAmbiguousName is a field whose name says little about
+	// what it holds, such as "data" or "value2".
+	SchemaIssueAmbiguousName SchemaIssueKind = "ambiguous_name"
+)
+
+// SchemaIssue is a problem with one field of a schema.
+type SchemaIssue struct {
+	// Field is the dotted path of the field, with [] for array items, e.g.
+	// "variants[].sku".
+	Field   string          `json:"field"`
+	Kind    SchemaIssueKind `json:"kind"`
+	Message string          `json:"message"`
+}
+
+// ambiguousNames are field names too vague to guide extraction on their own.
+var ambiguousNames = map[string]bool{
+	"data": true, "value": true, "values": true, "val": true, "info": true,
+	"details": true, "item": true, "items": true, "text": true, "content": true,
+	"field": true, "misc": true, "other": true, "stuff": true, "thing": true,
+	"result": true, "results": true, "obj": true, "object": true, "temp": true,
+	"tmp": true, "attr": true, "property": true, "prop": true, "element": true,
+}
+
+// numberedName matches names like field1 or value_2.
+var numberedName = regexp.MustCompile(`^[a-z]+_?\d+$`)
+
+// LintSchema checks a schema, in the map form accepted by Extract, for
+// fields lacking descriptions or with ambiguous names. Issues are sorted by
+// field. It runs locally; see SchemasClient.Lint for a critique by the API.
+func LintSchema(schema map[string]any) []SchemaIssue {
+	var issues []SchemaIssue
+	lintFields(schema, "", &issues)
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Field < issues[j].Field })
+	return issues
+}
+
+func lintFields(fields map[string]any, prefix string, issues *[]SchemaIssue) {
+	for name, def := range fields {
+		path := prefix + name
+		if ambiguousName(name) {
+			*issues = append(*issues, SchemaIssue{
+				Field:   path,
+				Kind:    SchemaIssueAmbiguousName,
+				Message: fmt.Sprintf("field name %q is ambiguous; name it after what it holds", name),
+			})
+		}
+		lintField(def, path, issues)
+	}
+}
+
+// lintField checks a field definition: a type name, a mapping with a type
+// or description, a nested object or a single-item list describing array
+// items.
+func lintField(def any, path string, issues *[]SchemaIssue) {
+	switch def := def.(type) {
+	case string:
+		missingDescription(path, issues)
+	case []any:
+		if len(def) > 0 {
+			lintField(def[0], path+"[]", issues)
+		}
+	case map[string]any:
+		typ, typed := def["type"].(string)
+		if _, described := def["description"].(string); !typed && !described {
+			lintFields(def, path+".", issues)
+			return
+		}
+		if desc, _ := def["description"].(string); strings.TrimSpace(desc) == "" {
+			missingDescription(path, issues)
+		}
+		switch typ {
+		case "object":
+			if props, ok := def["properties"].(map[string]any); ok {
+				lintFields(props, path+".", issues)
+			}
+		case "array":
+			if items, ok := def["items"]; ok {
+				if _, shorthand := items.(string); !shorthand {
+					lintField(items, path+"[]", issues)
+				}
+			}
+		}
+	}
+}
+
+func missingDescription(path string, issues *[]SchemaIssue) {
+	*issues = append(*issues, SchemaIssue{
+		Field:   path,
+		Kind:    SchemaIssueMissingDescription,
+		Message: "field has no description; describe what to extract and its format",
+	})
+}
+
+func ambiguousName(name string) bool {
+	lower := strings.ToLower(name)
+	return len(lower) == 1 || ambiguousNames[lower] || numberedName.MatchString(lower)
+}
+
+// SchemaCritique is the API's review of a schema.
+type SchemaCritique struct {
+	// Score rates how well the schema is likely to extract, from 0 to 1.
+	Score       float64            `json:"score"`
+	Summary     string             `json:"summary"`
+	Suggestions []SchemaSuggestion `json:"suggestions"`
+}
+
+// SchemaSuggestion is an improvement the API suggests for a field.
+type SchemaSuggestion struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+	// Description is a suggested description for the field, if any.
+	Description string `json:"description,omitempty"`
+}
+
+// SchemaLint is the result of SchemasClient.Lint.
+type SchemaLint struct {
+	Issues []SchemaIssue
+	// Critique is the API's review, if requested.
+	Critique *SchemaCritique
+}
+
+// Lint checks a schema with LintSchema and, if critique is true, also asks
+// the API for an LLM-powered review of its field names and descriptions.
+func (s *SchemasClient) Lint(ctx context.Context, schema map[string]any, critique bool, reqOpts ...RequestOption) (*SchemaLint, error) {
+	result := &SchemaLint{Issues: LintSchema(schema)}
+	if !critique {
+		return result, nil
+	}
+	ctx = withRequestOptions(ctx, reqOpts)
+	var review SchemaCritique
+	if err := s.client.request(ctx, http.MethodPost, "/api/v1/schemas/critique", map[string]any{"schema": schema}, &review); err != nil {
+		return nil, err
+	}
+	result.Critique = &review
+	return result, nil
+}
@@ -0,0 +1,222 @@
+package refyne
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Conflict is a field that the schemas passed to MergeSchemas define with
+// different types.
+type Conflict struct {
+	// Field is the dotted path of the field, with [] for array items, e.g.
+	// "variants[].price".
+	Field string `json:"field"`
+	// Types are the types the field was given, in the order of the schemas
+	// that first gave them. The merged schema uses the first.
+	Types []string `json:"types"`
+}
+
+func (c Conflict) String() string {
+	return fmt.Sprintf("%s: %s", c.Field, strings.Join(c.Types, " vs "))
+}
+
+// Schema parses the suggested schema into the map form accepted by Extract
+// and MergeSchemas.
+func (o *AnalyzeOutput) Schema() (map[string]any, error) {
+	return ParseSchemaYAML(o.SuggestedSchema)
+}
+
+// MergeSchemas unions schemas, in the map form accepted by Extract, such as
+// those suggested by Analyze for several variants of a page, so no variant's
+// fields are lost. Nested objects and array items are merged field by
+// field. Where schemas give a field different types the first schema's
+// definition is kept and a Conflict is reported; conflicts are sorted by
+// field. The inputs are not modified.
+//
+//	var schemas []map[string]any
+//	for _, u := range urls {
+//	    out, err := client.Analyze(ctx, refyne.AnalyzeInput{URL: u})
+//	    ...
+//	    schema, err := out.Schema()
+//	    ...
+//	    schemas = append(schemas, schema)
+//	}
+//	schema, conflicts := refyne.MergeSchemas(schemas...)
+func MergeSchemas(schemas ...map[string]any) (map[string]any, []Conflict) {
+	m := &schemaMerger{conflicts: map[string]*Conflict{}}
+	merged := map[string]any{}
+	for _, schema := range schemas {
+		m.mergeFields(merged, schema, "")
+	}
+
+	conflicts := make([]Conflict, 0, len(m.conflicts))
+	for _, c := range m.conflicts {
+		conflicts = append(conflicts, *c)
+	}
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].Field < conflicts[j].Field })
+	return merged, conflicts
+}
+
+// schemaMerger collects conflicts by field while schemas are merged.
+type schemaMerger struct {
+	conflicts map[string]*Conflict
+}
+
+// mergeFields merges the fields of src into dst, which it owns.
+func (m *schemaMerger) mergeFields(dst, src map[string]any, prefix string) {
+	for name, def := range src {
+		if existing, ok := dst[name]; ok {
+			dst[name] = m.mergeField(existing, def, prefix+name)
+		} else {
+			dst[name] = copySchemaValue(def)
+		}
+	}
+}
+
+// mergeField merges def into the merged definition existing of the field at
+// path and returns the result.
+func (m *schemaMerger) mergeField(existing, def any, path string) any {
+	have, want := fieldType(existing), fieldType(def)
+	if have != want {
+		m.conflict(path, have, want)
+		return existing
+	}
+
+	switch have {
+	case "object":
+		dstFields, srcFields := objectFields(existing), objectFields(def)
+		if dstFields != nil && srcFields != nil {
+			m.mergeFields(dstFields, srcFields, path+".")
+		}
+	case "array":
+		dstItems, ok := arrayItems(existing)
+		srcItems, srcOK := arrayItems(def)
+		if ok && srcOK {
+			setArrayItems(existing, m.mergeField(dstItems, srcItems, path+"[]"))
+		}
+	}
+	return fillDescription(existing, def)
+}
+
+func (m *schemaMerger) conflict(path, have, want string) {
+	c, ok := m.conflicts[path]
+	if !ok {
+		c = &Conflict{Field: path, Types: []string{have}}
+		m.conflicts[path] = c
+	}
+	for _, t := range c.Types {
+		if t == want {
+			return
+		}
+	}
+	c.Types = append(c.Types, want)
+}
+
+// fieldType returns the type of a field definition: a type name, a mapping
+// with a type, a nested object or a single-item list describing array
+// items. A mapping with only a description is a string.
+func fieldType(def any) string {
+	switch def := def.(type) {
+	case string:
+		return def
+	case []any:
+		return "array"
+	case map[string]any:
+		if typ, ok := def["type"].(string); ok {
+			return typ
+		}
+		if _, described := def["description"].(string); described {
+			return "string"
+		}
+		return "object"
+	}
+	return fmt.Sprintf("%T", def)
+}
+
+// objectFields returns the fields of an object definition, either a nested
+// mapping or a mapping with properties.
+func objectFields(def any) map[string]any {
+	m, ok := def.(map[string]any)
+	if !ok {
+		return nil
+	}
+	if _, typed := m["type"]; !typed {
+		return m
+	}
+	props, _ := m["properties"].(map[string]any)
+	return props
+}
+
+// arrayItems returns the item definition of an array definition, if it
+// describes its items.
+func arrayItems(def any) (any, bool) {
+	switch def := def.(type) {
+	case []any:
+		if len(def) > 0 {
+			return def[0], true
+		}
+	case map[string]any:
+		items, ok := def["items"]
+		return items, ok
+	}
+	return nil, false
+}
+
+// setArrayItems replaces the item definition of an array definition that
+// describes its items.
+func setArrayItems(def, items any) {
+	switch def := def.(type) {
+	case []any:
+		def[0] = items
+	case map[string]any:
+		def["items"] = items
+	}
+}
+
+// fillDescription gives existing the description of def if it has none,
+// expanding a bare type name into a mapping to hold it.
+func fillDescription(existing, def any) any {
+	src, ok := def.(map[string]any)
+	if !ok {
+		return existing
+	}
+	desc, _ := src["description"].(string)
+	if strings.TrimSpace(desc) == "" {
+		return existing
+	}
+	switch dst := existing.(type) {
+	case string:
+		return map[string]any{"type": dst, "description": desc}
+	case map[string]any:
+		if _, typed := dst["type"]; !typed {
+			if _, described := dst["description"]; !described {
+				// A nested object; a description would read as a field.
+				return existing
+			}
+		}
+		if d, _ := dst["description"].(string); strings.TrimSpace(d) == "" {
+			dst["description"] = desc
+		}
+	}
+	return existing
+}
+
+// copySchemaValue deep-copies the maps and lists of a schema value.
+func copySchemaValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, item := range v {
+			m[k] = copySchemaValue(item)
+		}
+		return m
+	case []any:
+		s := make([]any, len(v))
+		for i, item := range v {
+			s[i] = copySchemaValue(item)
+		}
+		return s
+	}
+	return v
+}
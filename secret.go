@@ -0,0 +1,101 @@
+package refyne
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// redacted replaces secret values in output.
+const redacted = "[REDACTED]"
+
+// Secret holds key material such as an API key. It prints, logs and
+// marshals as "[REDACTED]" whatever the format verb, so a key cannot leak
+// through %v logging, debug dumps or error messages. Copies of a Secret
+// share its value, and Zero wipes it for all of them; each Client holds
+// its own copy of its key. A Secret is safe for concurrent use.
+type Secret struct {
+	v *secretValue
+}
+
+type secretValue struct {
+	mu sync.RWMutex
+	b  []byte
+}
+
+// NewSecret returns a Secret holding value.
+func NewSecret(value string) Secret {
+	return Secret{v: &secretValue{b: []byte(value)}}
+}
+
+// Value returns the secret value. Keep the result out of logs.
+func (s Secret) Value() string {
+	if s.v == nil {
+		return ""
+	}
+	s.v.mu.RLock()
+	defer s.v.mu.RUnlock()
+	return string(s.v.b)
+}
+
+// IsEmpty reports whether the secret has no value, or has been zeroed.
+func (s Secret) IsEmpty() bool {
+	if s.v == nil {
+		return true
+	}
+	s.v.mu.RLock()
+	defer s.v.mu.RUnlock()
+	return len(s.v.b) == 0
+}
+
+// Zero overwrites the secret's bytes and empties it. Strings previously
+// returned by Value are not affected.
+func (s Secret) Zero() {
+	if s.v == nil {
+		return
+	}
+	s.v.mu.Lock()
+	defer s.v.mu.Unlock()
+	for i := range s.v.b {
+		s.v.b[i] = 0
+	}
+	s.v.b = nil
+}
+
+// String implements fmt.Stringer.
+func (s Secret) String() string {
+	return redacted
+}
+
+// GoString implements fmt.GoStringer.
+func (s Secret) GoString() string {
+	return redacted
+}
+
+// Format implements fmt.Formatter, redacting the value for every verb.
+func (s Secret) Format(f fmt.State, _ rune) {
+	_, _ = io.WriteString(f, redacted)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return json.Marshal(redacted)
+}
+
+// MarshalYAML implements yaml.Marshaler.
+func (s Secret) MarshalYAML() (any, error) {
+	return redacted, nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, so secrets can be read
+// from JSON and YAML config.
+func (s *Secret) UnmarshalText(text []byte) error {
+	*s = NewSecret(string(text))
+	return nil
+}
+
+// clone returns a Secret with its own copy of the value.
+func (s Secret) clone() Secret {
+	return NewSecret(s.Value())
+}
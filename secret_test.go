@@ -0,0 +1,83 @@
+package refyne
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSecretRedaction(t *testing.T) {
+	secret := NewSecret("rf_live_secret")
+	profile := Profile{APIKey: secret}
+	client := NewClient("rf_live_secret")
+
+	data, err := json.Marshal(profile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	outputs := []string{
+		fmt.Sprint(secret),
+		fmt.Sprintf("%s %v %+v %#v %q %x %d", secret, secret, profile, profile, secret, secret, secret),
+		fmt.Sprintf("%+v", *client),
+		fmt.Errorf("bad key %v", secret).Error(),
+		string(data),
+	}
+	for _, out := range outputs {
+		if strings.Contains(out, "rf_live") || strings.Contains(out, fmt.Sprintf("%x", "rf_live")) {
+			t.Errorf("secret leaked in %q", out)
+		}
+	}
+	if secret.Value() != "rf_live_secret" {
+		t.Errorf("expected Value to return the key, got %q", secret.Value())
+	}
+}
+
+func TestSecretZero(t *testing.T) {
+	secret := NewSecret("rf_live_secret")
+	buf := secret.v.b
+	copied := secret
+
+	secret.Zero()
+	if !copied.IsEmpty() || copied.Value() != "" {
+		t.Errorf("expected copies to be zeroed, got %q", copied.Value())
+	}
+	for _, b := range buf {
+		if b != 0 {
+			t.Fatal("expected the key bytes to be overwritten")
+		}
+	}
+}
+
+func TestSecretZeroConcurrent(t *testing.T) {
+	secret := NewSecret("rf_live_secret")
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if v := secret.Value(); v != "rf_live_secret" && v != "" {
+					t.Errorf("read a partly zeroed key %q", v)
+					return
+				}
+			}
+		}()
+	}
+	secret.Zero()
+	wg.Wait()
+}
+
+func TestClientZeroAPIKey(t *testing.T) {
+	client := NewClient("rf_live_secret")
+	derived := client.With()
+
+	client.ZeroAPIKey()
+	if !client.snapshot().apiKey.IsEmpty() {
+		t.Error("expected the client's key to be zeroed")
+	}
+	if got := derived.snapshot().apiKey.Value(); got != "rf_live_secret" {
+		t.Errorf("expected the derived client to keep its key, got %q", got)
+	}
+}
@@ -0,0 +1,40 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// GetSecretStringFunc returns the SecretString of an AWS Secrets Manager
+// secret. Wrap your AWS SDK client:
+//
+//	func(ctx context.Context, id string) (string, error) {
+//	    out, err := sm.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: &id})
+//	    if err != nil {
+//	        return "", err
+//	    }
+//	    return aws.ToString(out.SecretString), nil
+//	}
+type GetSecretStringFunc func(ctx context.Context, secretID string) (string, error)
+
+// AWSSecretsManager returns a Source that reads secretID from AWS Secrets
+// Manager. A secret holding a JSON object provides one field per key; any
+// other secret is returned as DefaultAPIKeyField.
+func AWSSecretsManager(get GetSecretStringFunc, secretID string) Source {
+	return SourceFunc(func(ctx context.Context) (map[string]string, error) {
+		secret, err := get(ctx, secretID)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(strings.TrimSpace(secret), "{") {
+			return map[string]string{DefaultAPIKeyField: secret}, nil
+		}
+		var fields map[string]any
+		if err := json.Unmarshal([]byte(secret), &fields); err != nil {
+			return nil, fmt.Errorf("failed to parse secret %s: %w", secretID, err)
+		}
+		return stringFields(fields), nil
+	})
+}
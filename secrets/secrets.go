@@ -0,0 +1,154 @@
+// Package secrets provides credential providers that read the Refyne API
+// key, and optionally BYOK LLM provider keys, from a secret store and keep
+// them fresh for long-running workers.
+//
+// HashiCorp Vault is supported over its HTTP API. AWS Secrets Manager is
+// supported through a small adapter around your AWS SDK client, so this
+// package adds no dependencies:
+//
+//	provider := secrets.New(&secrets.Vault{Path: "refyne/prod"},
+//	    secrets.WithRefreshInterval(10*time.Minute))
+//	client := refyne.NewClient("", refyne.WithCredentialProvider(provider))
+//	go provider.Run(ctx)
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultRefreshInterval is how long fetched secrets are used before they
+// are fetched again.
+const DefaultRefreshInterval = 5 * time.Minute
+
+// DefaultAPIKeyField is the secret field holding the Refyne API key.
+const DefaultAPIKeyField = "api_key"
+
+// Source fetches the fields of a secret.
+type Source interface {
+	Fetch(ctx context.Context) (map[string]string, error)
+}
+
+// SourceFunc adapts a function to a Source.
+type SourceFunc func(ctx context.Context) (map[string]string, error)
+
+// Fetch implements Source.
+func (f SourceFunc) Fetch(ctx context.Context) (map[string]string, error) {
+	return f(ctx)
+}
+
+// Provider caches the fields of a secret and refreshes them once they are
+// older than the refresh interval. It implements refyne.CredentialProvider.
+type Provider struct {
+	source      Source
+	interval    time.Duration
+	apiKeyField string
+	now         func() time.Time
+
+	mu        sync.Mutex
+	values    map[string]string
+	fetchedAt time.Time
+}
+
+// Option configures a Provider.
+type Option func(*Provider)
+
+// WithRefreshInterval sets how long fetched secrets are used.
+func WithRefreshInterval(d time.Duration) Option {
+	return func(p *Provider) {
+		p.interval = d
+	}
+}
+
+// WithAPIKeyField sets the secret field holding the Refyne API key.
+func WithAPIKeyField(field string) Option {
+	return func(p *Provider) {
+		p.apiKeyField = field
+	}
+}
+
+// New creates a Provider for source.
+func New(source Source, opts ...Option) *Provider {
+	p := &Provider{
+		source:      source,
+		interval:    DefaultRefreshInterval,
+		apiKeyField: DefaultAPIKeyField,
+		now:         time.Now,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Token returns the Refyne API key.
+func (p *Provider) Token(ctx context.Context) (string, error) {
+	return p.Get(ctx, p.apiKeyField)
+}
+
+// Get returns a field of the secret, such as a BYOK provider key to pass in
+// an LLMConfigInput. Stale values are refreshed first; if the refresh fails
+// the previous values are used until the source recovers.
+func (p *Provider) Get(ctx context.Context, field string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.values == nil || p.now().Sub(p.fetchedAt) >= p.interval {
+		if err := p.refreshLocked(ctx); err != nil && p.values == nil {
+			return "", err
+		}
+	}
+	value, ok := p.values[field]
+	if !ok || value == "" {
+		return "", fmt.Errorf("secret has no %q field", field)
+	}
+	return value, nil
+}
+
+// Refresh fetches the secret now.
+func (p *Provider) Refresh(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.refreshLocked(ctx)
+}
+
+// Run refreshes the secret every refresh interval until ctx is cancelled,
+// so calls never wait on the secret store. Refresh errors are ignored; the
+// previous values stay in use.
+func (p *Provider) Run(ctx context.Context) error {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		_ = p.Refresh(ctx)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *Provider) refreshLocked(ctx context.Context) error {
+	values, err := p.source.Fetch(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch secret: %w", err)
+	}
+	p.values, p.fetchedAt = values, p.now()
+	return nil
+}
+
+// stringFields converts decoded JSON fields to strings.
+func stringFields(fields map[string]any) map[string]string {
+	values := make(map[string]string, len(fields))
+	for field, value := range fields {
+		if s, ok := value.(string); ok {
+			values[field] = s
+		} else {
+			values[field] = fmt.Sprint(value)
+		}
+	}
+	return values
+}
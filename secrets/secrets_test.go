@@ -0,0 +1,104 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	refyne "github.com/jmylchreest/refyne-sdk-go"
+)
+
+func TestVault(t *testing.T) {
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/secret/data/refyne/prod" || r.Header.Get("X-Vault-Token") != "vault-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"data": map[string]any{"data": map[string]any{"api_key": "rf_live_1", "openai_api_key": "sk-1"}},
+		})
+	}))
+	defer vault.Close()
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer rf_live_1" {
+			t.Errorf("unexpected Authorization %q", got)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"status": "ok"})
+	}))
+	defer api.Close()
+
+	provider := New(&Vault{Address: vault.URL, Token: "vault-token", Path: "refyne/prod"})
+	client := refyne.NewClient("", refyne.WithBaseURL(api.URL), refyne.WithCredentialProvider(provider))
+	if _, err := client.Health(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if key, err := provider.Get(context.Background(), "openai_api_key"); err != nil || key != "sk-1" {
+		t.Errorf("expected BYOK key, got %q, %v", key, err)
+	}
+
+	denied := New(&Vault{Address: vault.URL, Token: "wrong", Path: "refyne/prod"})
+	if _, err := denied.Token(context.Background()); err == nil {
+		t.Error("expected error for a denied Vault request")
+	}
+}
+
+func TestAWSSecretsManager(t *testing.T) {
+	secret := `{"api_key": "rf_live_1", "anthropic_api_key": "sk-ant"}`
+	source := AWSSecretsManager(func(_ context.Context, id string) (string, error) {
+		if id != "refyne/prod" {
+			t.Errorf("unexpected secret ID %q", id)
+		}
+		return secret, nil
+	}, "refyne/prod")
+
+	provider := New(source)
+	if key, err := provider.Get(context.Background(), "anthropic_api_key"); err != nil || key != "sk-ant" {
+		t.Errorf("expected field from JSON secret, got %q, %v", key, err)
+	}
+
+	secret = "rf_plain"
+	if err := provider.Refresh(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if key, err := provider.Token(context.Background()); err != nil || key != "rf_plain" {
+		t.Errorf("expected plain secret as API key, got %q, %v", key, err)
+	}
+}
+
+func TestProviderRefresh(t *testing.T) {
+	now := time.Now()
+	fetches := 0
+	var fetchErr error
+	provider := New(SourceFunc(func(context.Context) (map[string]string, error) {
+		if fetchErr != nil {
+			return nil, fetchErr
+		}
+		fetches++
+		return map[string]string{"key": "v" + string(rune('0'+fetches))}, nil
+	}), WithRefreshInterval(time.Minute), WithAPIKeyField("key"))
+	provider.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	if key, _ := provider.Token(ctx); key != "v1" {
+		t.Errorf("expected v1, got %q", key)
+	}
+	if key, _ := provider.Token(ctx); key != "v1" || fetches != 1 {
+		t.Errorf("expected cached v1, got %q after %d fetches", key, fetches)
+	}
+
+	now = now.Add(time.Minute)
+	if key, _ := provider.Token(ctx); key != "v2" {
+		t.Errorf("expected refreshed v2, got %q", key)
+	}
+
+	now = now.Add(time.Minute)
+	fetchErr = errors.New("unavailable")
+	if key, err := provider.Token(ctx); err != nil || key != "v2" {
+		t.Errorf("expected stale v2 while the source is down, got %q, %v", key, err)
+	}
+}
@@ -0,0 +1,73 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Vault reads a secret from a HashiCorp Vault KV version 2 engine.
+type Vault struct {
+	// Address is the Vault server URL. Defaults to $VAULT_ADDR.
+	Address string
+	// Token authenticates to Vault. Defaults to $VAULT_TOKEN.
+	Token string
+	// Mount is the KV engine mount. Defaults to "secret".
+	Mount string
+	// Path is the secret path within the mount.
+	Path string
+	// HTTPClient defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// Fetch implements Source.
+func (v *Vault) Fetch(ctx context.Context) (map[string]string, error) {
+	address, token, mount := v.Address, v.Token, v.Mount
+	if address == "" {
+		address = os.Getenv("VAULT_ADDR")
+	}
+	if token == "" {
+		token = os.Getenv("VAULT_TOKEN")
+	}
+	if mount == "" {
+		mount = "secret"
+	}
+	httpClient := v.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	url := strings.TrimRight(address, "/") + "/v1/" + strings.Trim(mount, "/") + "/data/" + strings.TrimLeft(v.Path, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Vault request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", token)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach Vault: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Vault response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault returned %d for %s: %s", resp.StatusCode, v.Path, strings.TrimSpace(string(body)))
+	}
+
+	var secret struct {
+		Data struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return nil, fmt.Errorf("failed to parse Vault response: %w", err)
+	}
+	return stringFields(secret.Data.Data), nil
+}
@@ -3,8 +3,13 @@ package refyne
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // JobsClient handles job-related operations.
@@ -19,7 +24,8 @@ type ListOptions struct {
 }
 
 // List returns all jobs.
-func (j *JobsClient) List(ctx context.Context, opts *ListOptions) (*ListJobsOutputBody, error) {
+func (j *JobsClient) List(ctx context.Context, opts *ListOptions, reqOpts ...RequestOption) (*ListJobsOutputBody, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
 	path := "/api/v1/jobs"
 	if opts != nil {
 		params := ""
@@ -44,22 +50,78 @@ func (j *JobsClient) List(ctx context.Context, opts *ListOptions) (*ListJobsOutp
 	return &result, nil
 }
 
+// Job is a crawl or extraction job. A job waiting behind the account's
+// concurrency limit has a QueuePosition and, when the API can estimate
+// them, the times it is expected to start and finish.
+type Job struct {
+	JobResponse
+	EstimatedStartAt  *time.Time `json:"estimated_start_at,omitempty"`
+	EstimatedFinishAt *time.Time `json:"estimated_finish_at,omitempty"`
+	// FailuresByCategory counts the job's failed pages by why they failed.
+	FailuresByCategory map[FailureCategory]int64 `json:"failures_by_category,omitempty"`
+}
+
+// Queued reports whether the job is waiting for a free slot.
+func (j *Job) Queued() bool {
+	return j.QueuePosition > 0
+}
+
 // Get returns a job by ID.
-func (j *JobsClient) Get(ctx context.Context, id string) (*JobResponse, error) {
-	var result JobResponse
+func (j *JobsClient) Get(ctx context.Context, id string, reqOpts ...RequestOption) (*Job, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
+	var result Job
 	if err := j.client.request(ctx, http.MethodGet, "/api/v1/jobs/"+id, nil, &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
 }
 
+// longPollGrace is added to the wait of a long poll to allow for the
+// server's response time.
+const longPollGrace = 10 * time.Second
+
+// GetWait returns a job once its status changes, or after maxWait if it
+// does not. The server holds the request open meanwhile, so waiting on
+// many jobs takes far fewer requests than polling Get. maxWait is rounded
+// up to whole seconds, and the per-attempt timeout is extended to cover it.
+func (j *JobsClient) GetWait(ctx context.Context, id string, maxWait time.Duration, reqOpts ...RequestOption) (*Job, error) {
+	seconds := int64((maxWait + time.Second - 1) / time.Second)
+	reqOpts = append([]RequestOption{WithRequestTimeout(time.Duration(seconds)*time.Second + longPollGrace), WithoutCache()}, reqOpts...)
+	ctx = withRequestOptions(ctx, reqOpts)
+	var result Job
+	path := "/api/v1/jobs/" + id + "?wait=" + strconv.FormatInt(seconds, 10)
+	if err := j.client.request(ctx, http.MethodGet, path, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
 // ResultsOptions contains options for getting job results.
 type ResultsOptions struct {
 	Merge bool
+	// Chunked makes GetResults fetch the results in chunks, as
+	// StreamResults does, and return them as a JSON array.
+	Chunked bool
+	// ChunkSize is the size of the first chunk. Zero uses
+	// DefaultResultsChunkSize.
+	ChunkSize int
 }
 
-// GetResults returns job results.
-func (j *JobsClient) GetResults(ctx context.Context, id string, opts *ResultsOptions) (json.RawMessage, error) {
+// GetResults returns job results. If the job has not finished it returns a
+// *ResultsNotReadyError rather than the partial response.
+func (j *JobsClient) GetResults(ctx context.Context, id string, opts *ResultsOptions, reqOpts ...RequestOption) (json.RawMessage, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
+	if opts != nil && opts.Chunked {
+		results := []json.RawMessage{}
+		err := j.StreamResults(ctx, id, opts, func(result json.RawMessage) error {
+			results = append(results, result)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(results)
+	}
 	path := "/api/v1/jobs/" + id + "/results"
 	if opts != nil && opts.Merge {
 		path += "?merge=true"
@@ -69,11 +131,15 @@ func (j *JobsClient) GetResults(ctx context.Context, id string, opts *ResultsOpt
 	if err := j.client.request(ctx, http.MethodGet, path, nil, &result); err != nil {
 		return nil, err
 	}
+	if err := resultsNotReady(result); err != nil {
+		return nil, err
+	}
 	return result, nil
 }
 
 // Download gets a presigned download URL for job results.
-func (j *JobsClient) Download(ctx context.Context, id string) (*GetJobResultsDownloadOutputBody, error) {
+func (j *JobsClient) Download(ctx context.Context, id string, reqOpts ...RequestOption) (*GetJobResultsDownloadOutputBody, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
 	var result GetJobResultsDownloadOutputBody
 	if err := j.client.request(ctx, http.MethodGet, "/api/v1/jobs/"+id+"/download", nil, &result); err != nil {
 		return nil, err
@@ -81,9 +147,164 @@ func (j *JobsClient) Download(ctx context.Context, id string) (*GetJobResultsDow
 	return &result, nil
 }
 
+// QualityReport summarizes how well a job's extractions matched its schema.
+type QualityReport struct {
+	JobID string `json:"job_id"`
+	Pages int64  `json:"pages"`
+	// EmptyPages lists the URLs of pages where nothing was extracted.
+	EmptyPages    []string       `json:"empty_pages"`
+	Fields        []FieldQuality `json:"fields"`
+	UnknownFields `json:"-"`
+}
+
+// FieldQuality contains extraction statistics for one schema field.
+type FieldQuality struct {
+	Field string `json:"field"`
+	// FillRate is the fraction of records with a non-null value, from 0 to 1.
+	FillRate float64 `json:"fill_rate"`
+	// NullPercent is the percentage of records where the field was null.
+	NullPercent float64 `json:"null_percent"`
+	// TypeMismatches counts values that did not match the schema type.
+	TypeMismatches int64 `json:"type_mismatches"`
+}
+
+// GetQualityReport returns field fill rates, null percentages, type
+// mismatches and empty pages for a job, to spot schema drift on a site.
+func (j *JobsClient) GetQualityReport(ctx context.Context, id string, reqOpts ...RequestOption) (*QualityReport, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
+	var result QualityReport
+	if err := j.client.request(ctx, http.MethodGet, "/api/v1/jobs/"+id+"/quality", nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// AlertCondition is the job quality metric an AlertRule checks.
+type AlertCondition string
+
+// Alert conditions. Each is a rate from 0 to 1 compared with the rule's
+// threshold.
+const (
+	AlertNullRate         AlertCondition = "null_rate"
+	AlertTypeMismatchRate AlertCondition = "type_mismatch_rate"
+	AlertEmptyPageRate    AlertCondition = "empty_page_rate"
+)
+
+// AlertAction is what happens when an AlertRule triggers.
+type AlertAction string
+
+// Alert actions.
+const (
+	AlertActionWarn AlertAction = "warn"
+	AlertActionFail AlertAction = "fail"
+)
+
+// AlertRule flags a job whose quality crosses a threshold, e.g. failing the
+// job when more than 20% of pages have a null price:
+//
+//	refyne.AlertRule{Name: "price missing", Field: "price",
+//	    Condition: refyne.AlertNullRate, Threshold: 0.2, Action: refyne.AlertActionFail}
+type AlertRule struct {
+	Name string `json:"name"`
+	// Field is the schema field checked; it is ignored by AlertEmptyPageRate.
+	Field     string         `json:"field,omitempty"`
+	Condition AlertCondition `json:"condition"`
+	Threshold float64        `json:"threshold"`
+	Action    AlertAction    `json:"action"`
+}
+
+// AlertResult is the evaluation of one AlertRule against a job.
+type AlertResult struct {
+	Rule      AlertRule `json:"rule"`
+	Value     float64   `json:"value"`
+	Triggered bool      `json:"triggered"`
+}
+
+// AlertEvaluation is the outcome of a job's alert rules.
+type AlertEvaluation struct {
+	JobID string `json:"job_id"`
+	// Failed reports whether a rule with AlertActionFail triggered.
+	Failed  bool          `json:"failed"`
+	Results []AlertResult `json:"results"`
+}
+
+// Triggered returns the results of the rules that triggered.
+func (e *AlertEvaluation) Triggered() []AlertResult {
+	var triggered []AlertResult
+	for _, r := range e.Results {
+		if r.Triggered {
+			triggered = append(triggered, r)
+		}
+	}
+	return triggered
+}
+
+// GetAlertEvaluation returns how a job fared against the alert rules of
+// its site and schedule.
+func (j *JobsClient) GetAlertEvaluation(ctx context.Context, id string, reqOpts ...RequestOption) (*AlertEvaluation, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
+	var result AlertEvaluation
+	if err := j.client.request(ctx, http.MethodGet, "/api/v1/jobs/"+id+"/alerts", nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Feedback is a human review of an extracted record.
+type Feedback struct {
+	// FieldCorrections maps field names to their correct values.
+	FieldCorrections map[string]any `json:"field_corrections,omitempty"`
+	// Rating scores the extraction from 1 (wrong) to 5 (perfect); zero
+	// leaves it unrated.
+	Rating  int    `json:"rating,omitempty"`
+	Comment string `json:"comment,omitempty"`
+}
+
+// AnnotateResult submits feedback on a single extracted record of a job,
+// recording it for audit and to improve future extractions.
+func (j *JobsClient) AnnotateResult(ctx context.Context, jobID, recordID string, feedback Feedback, reqOpts ...RequestOption) error {
+	ctx = withRequestOptions(ctx, reqOpts)
+	return j.client.request(ctx, http.MethodPost, "/api/v1/jobs/"+jobID+"/results/"+recordID+"/feedback", feedback, nil)
+}
+
+// ContentFormat is the format of stored page content.
+type ContentFormat string
+
+// Content formats accepted by GetPageContent.
+const (
+	ContentHTML     ContentFormat = "html"
+	ContentMarkdown ContentFormat = "markdown"
+)
+
+// PageContent is the stored content of a crawled page.
+type PageContent struct {
+	PageID    string        `json:"page_id"`
+	URL       string        `json:"url"`
+	Format    ContentFormat `json:"format"`
+	Content   string        `json:"content"`
+	FetchedAt string        `json:"fetched_at"`
+}
+
+// GetPageContent returns the stored content of a page crawled by a job.
+// Content is only stored for jobs started with CrawlInput.IncludeContent.
+func (j *JobsClient) GetPageContent(ctx context.Context, jobID, pageID string, format ContentFormat, reqOpts ...RequestOption) (*PageContent, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
+	path := "/api/v1/jobs/" + jobID + "/pages/" + pageID + "/content"
+	if format != "" {
+		path += "?format=" + url.QueryEscape(string(format))
+	}
+
+	var result PageContent
+	if err := j.client.request(ctx, http.MethodGet, path, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
 // GetCrawlMap retrieves the crawl map for a job.
-func (j *JobsClient) GetCrawlMap(ctx context.Context, id string) (*GetCrawlMapOutputBody, error) {
-	var result GetCrawlMapOutputBody
+func (j *JobsClient) GetCrawlMap(ctx context.Context, id string, reqOpts ...RequestOption) (*CrawlMap, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
+	var result CrawlMap
 	if err := j.client.request(ctx, http.MethodGet, "/api/v1/jobs/"+id+"/crawl-map", nil, &result); err != nil {
 		return nil, err
 	}
@@ -91,7 +312,8 @@ func (j *JobsClient) GetCrawlMap(ctx context.Context, id string) (*GetCrawlMapOu
 }
 
 // GetDebugCapture retrieves debug capture data for a job.
-func (j *JobsClient) GetDebugCapture(ctx context.Context, id string) (*GetJobDebugCaptureOutputBody, error) {
+func (j *JobsClient) GetDebugCapture(ctx context.Context, id string, reqOpts ...RequestOption) (*GetJobDebugCaptureOutputBody, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
 	var result GetJobDebugCaptureOutputBody
 	if err := j.client.request(ctx, http.MethodGet, "/api/v1/jobs/"+id+"/debug-capture", nil, &result); err != nil {
 		return nil, err
@@ -100,7 +322,8 @@ func (j *JobsClient) GetDebugCapture(ctx context.Context, id string) (*GetJobDeb
 }
 
 // GetWebhookDeliveries retrieves webhook deliveries for a job.
-func (j *JobsClient) GetWebhookDeliveries(ctx context.Context, id string) (*GetJobWebhookDeliveriesOutputBody, error) {
+func (j *JobsClient) GetWebhookDeliveries(ctx context.Context, id string, reqOpts ...RequestOption) (*GetJobWebhookDeliveriesOutputBody, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
 	var result GetJobWebhookDeliveriesOutputBody
 	if err := j.client.request(ctx, http.MethodGet, "/api/v1/jobs/"+id+"/webhooks", nil, &result); err != nil {
 		return nil, err
@@ -108,13 +331,31 @@ func (j *JobsClient) GetWebhookDeliveries(ctx context.Context, id string) (*GetJ
 	return &result, nil
 }
 
+// JobStats contains aggregate job counts for the account.
+type JobStats struct {
+	Total         int64            `json:"total"`
+	ByStatus      map[string]int64 `json:"by_status"`
+	UnknownFields `json:"-"`
+}
+
+// Stats returns job counts by status.
+func (j *JobsClient) Stats(ctx context.Context, reqOpts ...RequestOption) (*JobStats, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
+	var result JobStats
+	if err := j.client.request(ctx, http.MethodGet, "/api/v1/jobs/stats", nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
 // SchemasClient handles schema operations.
 type SchemasClient struct {
 	client *Client
 }
 
 // List returns all schemas.
-func (s *SchemasClient) List(ctx context.Context) (*ListSchemasOutputBody, error) {
+func (s *SchemasClient) List(ctx context.Context, reqOpts ...RequestOption) (*ListSchemasOutputBody, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
 	var result ListSchemasOutputBody
 	if err := s.client.request(ctx, http.MethodGet, "/api/v1/schemas", nil, &result); err != nil {
 		return nil, err
@@ -123,7 +364,8 @@ func (s *SchemasClient) List(ctx context.Context) (*ListSchemasOutputBody, error
 }
 
 // Get returns a schema by ID.
-func (s *SchemasClient) Get(ctx context.Context, id string) (*SchemaOutput, error) {
+func (s *SchemasClient) Get(ctx context.Context, id string, reqOpts ...RequestOption) (*SchemaOutput, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
 	var result SchemaOutput
 	if err := s.client.request(ctx, http.MethodGet, "/api/v1/schemas/"+id, nil, &result); err != nil {
 		return nil, err
@@ -131,15 +373,54 @@ func (s *SchemasClient) Get(ctx context.Context, id string) (*SchemaOutput, erro
 	return &result, nil
 }
 
-// CreateInput contains parameters for creating a schema.
+// CreateInput contains parameters for creating a schema. Update sends
+// only the fields that are set.
 type CreateSchemaInput struct {
-	Name       string `json:"name"`
-	SchemaYAML string `json:"schema_yaml"`
+	Name       string `json:"name,omitempty"`
+	SchemaYAML string `json:"schema_yaml,omitempty"`
 	Visibility string `json:"visibility,omitempty"`
+	// IfMatch makes Update conditional on the schema still being at this
+	// version (its UpdatedAt from a previous read). If it has changed,
+	// Update returns a *PreconditionFailedError.
+	IfMatch string `json:"-"`
+}
+
+// Validate checks the input before it is sent, so malformed schemas fail
+// fast without a round trip. It returns a *ValidationError whose Fields are
+// keyed by the JSON field name.
+func (in CreateSchemaInput) Validate() error {
+	return in.validate(false)
+}
+
+// validate checks the input, or for a partial update only the fields that
+// are set.
+func (in CreateSchemaInput) validate(partial bool) error {
+	fields := map[string]string{}
+	if strings.TrimSpace(in.Name) == "" && (!partial || in.Name != "") {
+		fields["name"] = "is required"
+	}
+	if strings.TrimSpace(in.SchemaYAML) == "" {
+		if !partial || in.SchemaYAML != "" {
+			fields["schema_yaml"] = "is required"
+		}
+	} else if _, err := ParseSchemaYAML(in.SchemaYAML); err != nil {
+		fields["schema_yaml"] = err.Error()
+	}
+	if in.Visibility != "" && !CreateSchemaInputBodyVisibility(in.Visibility).Valid() {
+		fields["visibility"] = fmt.Sprintf("must be %q or %q", CreateSchemaInputBodyVisibilityPrivate, CreateSchemaInputBodyVisibilityPublic)
+	}
+	if len(fields) > 0 {
+		return &ValidationError{APIError: APIError{Message: "invalid schema input"}, Fields: fields}
+	}
+	return nil
 }
 
 // Create creates a new schema.
-func (s *SchemasClient) Create(ctx context.Context, input CreateSchemaInput) (*SchemaOutput, error) {
+func (s *SchemasClient) Create(ctx context.Context, input CreateSchemaInput, reqOpts ...RequestOption) (*SchemaOutput, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
 	var result SchemaOutput
 	if err := s.client.request(ctx, http.MethodPost, "/api/v1/schemas", input, &result); err != nil {
 		return nil, err
@@ -147,8 +428,17 @@ func (s *SchemasClient) Create(ctx context.Context, input CreateSchemaInput) (*S
 	return &result, nil
 }
 
-// Update updates a schema.
-func (s *SchemasClient) Update(ctx context.Context, id string, input CreateSchemaInput) (*SchemaOutput, error) {
+// Update updates the fields of a schema that are set in input, validating
+// only those. Set input.IfMatch to guard against overwriting concurrent
+// changes.
+func (s *SchemasClient) Update(ctx context.Context, id string, input CreateSchemaInput, reqOpts ...RequestOption) (*SchemaOutput, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
+	if err := input.validate(true); err != nil {
+		return nil, err
+	}
+	if input.IfMatch != "" {
+		ctx = ContextWithHeader(ctx, "If-Match", input.IfMatch)
+	}
 	var result SchemaOutput
 	if err := s.client.request(ctx, http.MethodPut, "/api/v1/schemas/"+id, input, &result); err != nil {
 		return nil, err
@@ -156,9 +446,125 @@ func (s *SchemasClient) Update(ctx context.Context, id string, input CreateSchem
 	return &result, nil
 }
 
-// Delete deletes a schema.
-func (s *SchemasClient) Delete(ctx context.Context, id string) error {
-	return s.client.request(ctx, http.MethodDelete, "/api/v1/schemas/"+id, nil, nil)
+// Delete deletes a schema. If the schema is still used by sites, schedules
+// or recent jobs the API refuses, and Delete returns a *SchemaInUseError
+// listing them.
+func (s *SchemasClient) Delete(ctx context.Context, id string, reqOpts ...RequestOption) error {
+	ctx = withRequestOptions(ctx, reqOpts)
+	err := s.client.request(ctx, http.MethodDelete, "/api/v1/schemas/"+id, nil, nil)
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) {
+		return err
+	}
+	refs, refErr := s.ListReferences(ctx, id)
+	if refErr != nil {
+		return err
+	}
+	return &SchemaInUseError{SchemaID: id, References: refs, Err: conflict}
+}
+
+// SchemaReferences lists the resources that use a schema.
+type SchemaReferences struct {
+	Sites     []SavedSiteOutput `json:"sites"`
+	Schedules []Schedule        `json:"schedules"`
+	// Jobs contains recent jobs that ran with the schema.
+	Jobs []JobResponse `json:"jobs"`
+}
+
+// InUse reports whether anything references the schema.
+func (r *SchemaReferences) InUse() bool {
+	return len(r.Sites) > 0 || len(r.Schedules) > 0 || len(r.Jobs) > 0
+}
+
+// ListReferences returns the saved sites, schedules and recent jobs that use
+// a schema.
+func (s *SchemasClient) ListReferences(ctx context.Context, id string, reqOpts ...RequestOption) (*SchemaReferences, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
+	var result SchemaReferences
+	if err := s.client.request(ctx, http.MethodGet, "/api/v1/schemas/"+id+"/references", nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Publish makes a schema public so it appears in the schema gallery.
+func (s *SchemasClient) Publish(ctx context.Context, id string, reqOpts ...RequestOption) (*SchemaOutput, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
+	var result SchemaOutput
+	if err := s.client.request(ctx, http.MethodPost, "/api/v1/schemas/"+id+"/publish", nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Unpublish makes a public schema private again.
+func (s *SchemasClient) Unpublish(ctx context.Context, id string, reqOpts ...RequestOption) (*SchemaOutput, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
+	var result SchemaOutput
+	if err := s.client.request(ctx, http.MethodPost, "/api/v1/schemas/"+id+"/unpublish", nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GalleryFilter contains options for browsing the schema gallery.
+type GalleryFilter struct {
+	// Category filters by category, e.g. "jobs", "ecommerce" or "real-estate".
+	Category string
+	// Tag filters by tag.
+	Tag string
+	// Query searches schema names and descriptions.
+	Query  string
+	Limit  int
+	Offset int
+}
+
+// Gallery returns public and platform schema templates.
+func (s *SchemasClient) Gallery(ctx context.Context, filter *GalleryFilter, reqOpts ...RequestOption) (*ListSchemasOutputBody, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
+	path := "/api/v1/schemas/gallery"
+	if filter != nil {
+		params := url.Values{}
+		if filter.Category != "" {
+			params.Set("category", filter.Category)
+		}
+		if filter.Tag != "" {
+			params.Set("tag", filter.Tag)
+		}
+		if filter.Query != "" {
+			params.Set("q", filter.Query)
+		}
+		if filter.Limit > 0 {
+			params.Set("limit", strconv.Itoa(filter.Limit))
+		}
+		if filter.Offset > 0 {
+			params.Set("offset", strconv.Itoa(filter.Offset))
+		}
+		if len(params) > 0 {
+			path += "?" + params.Encode()
+		}
+	}
+
+	var result ListSchemasOutputBody
+	if err := s.client.request(ctx, http.MethodGet, path, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Fork copies a gallery schema into the account as a private schema. An
+// empty name keeps the original name.
+func (s *SchemasClient) Fork(ctx context.Context, id, name string, reqOpts ...RequestOption) (*SchemaOutput, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
+	var body map[string]string
+	if name != "" {
+		body = map[string]string{"name": name}
+	}
+	var result SchemaOutput
+	if err := s.client.request(ctx, http.MethodPost, "/api/v1/schemas/"+id+"/fork", body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
 }
 
 // SitesClient handles site operations.
@@ -167,7 +573,8 @@ type SitesClient struct {
 }
 
 // List returns all sites.
-func (s *SitesClient) List(ctx context.Context) (*ListSavedSitesOutputBody, error) {
+func (s *SitesClient) List(ctx context.Context, reqOpts ...RequestOption) (*ListSavedSitesOutputBody, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
 	var result ListSavedSitesOutputBody
 	if err := s.client.request(ctx, http.MethodGet, "/api/v1/sites", nil, &result); err != nil {
 		return nil, err
@@ -176,7 +583,8 @@ func (s *SitesClient) List(ctx context.Context) (*ListSavedSitesOutputBody, erro
 }
 
 // Get returns a site by ID.
-func (s *SitesClient) Get(ctx context.Context, id string) (*SavedSiteOutput, error) {
+func (s *SitesClient) Get(ctx context.Context, id string, reqOpts ...RequestOption) (*SavedSiteOutput, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
 	var result SavedSiteOutput
 	if err := s.client.request(ctx, http.MethodGet, "/api/v1/sites/"+id, nil, &result); err != nil {
 		return nil, err
@@ -190,10 +598,17 @@ type CreateSiteInput struct {
 	URL             string `json:"url"`
 	DefaultSchemaID string `json:"default_schema_id,omitempty"`
 	FetchMode       string `json:"fetch_mode,omitempty"`
+	// AlertRules are evaluated against every job run for the site.
+	AlertRules []AlertRule `json:"alert_rules,omitempty"`
+	// IfMatch makes Update conditional on the site still being at this
+	// version (its UpdatedAt from a previous read). If it has changed,
+	// Update returns a *PreconditionFailedError.
+	IfMatch string `json:"-"`
 }
 
 // Create creates a new site.
-func (s *SitesClient) Create(ctx context.Context, input CreateSiteInput) (*SavedSiteOutput, error) {
+func (s *SitesClient) Create(ctx context.Context, input CreateSiteInput, reqOpts ...RequestOption) (*SavedSiteOutput, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
 	var result SavedSiteOutput
 	if err := s.client.request(ctx, http.MethodPost, "/api/v1/sites", input, &result); err != nil {
 		return nil, err
@@ -201,8 +616,13 @@ func (s *SitesClient) Create(ctx context.Context, input CreateSiteInput) (*Saved
 	return &result, nil
 }
 
-// Update updates a site.
-func (s *SitesClient) Update(ctx context.Context, id string, input CreateSiteInput) (*SavedSiteOutput, error) {
+// Update updates a site. Set input.IfMatch to guard against overwriting
+// concurrent changes.
+func (s *SitesClient) Update(ctx context.Context, id string, input CreateSiteInput, reqOpts ...RequestOption) (*SavedSiteOutput, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
+	if input.IfMatch != "" {
+		ctx = ContextWithHeader(ctx, "If-Match", input.IfMatch)
+	}
 	var result SavedSiteOutput
 	if err := s.client.request(ctx, http.MethodPut, "/api/v1/sites/"+id, input, &result); err != nil {
 		return nil, err
@@ -211,17 +631,141 @@ func (s *SitesClient) Update(ctx context.Context, id string, input CreateSiteInp
 }
 
 // Delete deletes a site.
-func (s *SitesClient) Delete(ctx context.Context, id string) error {
+func (s *SitesClient) Delete(ctx context.Context, id string, reqOpts ...RequestOption) error {
+	ctx = withRequestOptions(ctx, reqOpts)
 	return s.client.request(ctx, http.MethodDelete, "/api/v1/sites/"+id, nil, nil)
 }
 
+// TrendPeriod is the time window of a FieldTrends query.
+type TrendPeriod string
+
+// Trend periods accepted by FieldTrends.
+const (
+	TrendPeriod7Days  TrendPeriod = "7d"
+	TrendPeriod30Days TrendPeriod = "30d"
+	TrendPeriod90Days TrendPeriod = "90d"
+)
+
+// TrendPoint is a field's statistics for one crawl of a site.
+type TrendPoint struct {
+	JobID     string    `json:"job_id"`
+	Timestamp time.Time `json:"timestamp"`
+	// FillRate is the fraction of records with a non-null value.
+	FillRate float64 `json:"fill_rate"`
+	// Average is the mean value for numeric fields, otherwise nil.
+	Average *float64 `json:"average,omitempty"`
+}
+
+// FieldTrends is a time series of a field's quality across crawls of a site.
+type FieldTrends struct {
+	SiteID string       `json:"site_id"`
+	Field  string       `json:"field"`
+	Points []TrendPoint `json:"points"`
+}
+
+// FieldTrends returns the fill rate and average value of a field across the
+// site's crawls in period, oldest first.
+func (s *SitesClient) FieldTrends(ctx context.Context, siteID, field string, period TrendPeriod, reqOpts ...RequestOption) (*FieldTrends, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
+	params := url.Values{}
+	params.Set("field", field)
+	if period != "" {
+		params.Set("period", string(period))
+	}
+
+	var result FieldTrends
+	if err := s.client.request(ctx, http.MethodGet, "/api/v1/sites/"+siteID+"/trends?"+params.Encode(), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SchedulesClient handles scheduled crawl operations.
+type SchedulesClient struct {
+	client *Client
+}
+
+// Schedule is a recurring crawl of a saved site.
+type Schedule struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	SiteID    string `json:"site_id"`
+	SchemaID  string `json:"schema_id,omitempty"`
+	Cron      string `json:"cron"`
+	IsActive  bool   `json:"is_active"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// ListSchedulesOutput contains the schedules returned by List.
+type ListSchedulesOutput struct {
+	Schedules []Schedule `json:"schedules"`
+}
+
+// List returns all schedules.
+func (s *SchedulesClient) List(ctx context.Context, reqOpts ...RequestOption) (*ListSchedulesOutput, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
+	var result ListSchedulesOutput
+	if err := s.client.request(ctx, http.MethodGet, "/api/v1/schedules", nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Get returns a schedule by ID.
+func (s *SchedulesClient) Get(ctx context.Context, id string, reqOpts ...RequestOption) (*Schedule, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
+	var result Schedule
+	if err := s.client.request(ctx, http.MethodGet, "/api/v1/schedules/"+id, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// CreateScheduleInput contains parameters for creating a schedule.
+type CreateScheduleInput struct {
+	Name       string      `json:"name"`
+	SiteID     string      `json:"site_id"`
+	SchemaID   string      `json:"schema_id,omitempty"`
+	Cron       string      `json:"cron"`
+	IsActive   bool        `json:"is_active"`
+	AlertRules []AlertRule `json:"alert_rules,omitempty"`
+}
+
+// Create creates a new schedule.
+func (s *SchedulesClient) Create(ctx context.Context, input CreateScheduleInput, reqOpts ...RequestOption) (*Schedule, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
+	var result Schedule
+	if err := s.client.request(ctx, http.MethodPost, "/api/v1/schedules", input, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Update updates a schedule.
+func (s *SchedulesClient) Update(ctx context.Context, id string, input CreateScheduleInput, reqOpts ...RequestOption) (*Schedule, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
+	var result Schedule
+	if err := s.client.request(ctx, http.MethodPut, "/api/v1/schedules/"+id, input, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Delete deletes a schedule.
+func (s *SchedulesClient) Delete(ctx context.Context, id string, reqOpts ...RequestOption) error {
+	ctx = withRequestOptions(ctx, reqOpts)
+	return s.client.request(ctx, http.MethodDelete, "/api/v1/schedules/"+id, nil, nil)
+}
+
 // KeysClient handles API key operations.
 type KeysClient struct {
 	client *Client
 }
 
 // List returns all API keys.
-func (k *KeysClient) List(ctx context.Context) (*ListKeysOutputBody, error) {
+func (k *KeysClient) List(ctx context.Context, reqOpts ...RequestOption) (*ListKeysOutputBody, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
 	var result ListKeysOutputBody
 	if err := k.client.request(ctx, http.MethodGet, "/api/v1/keys", nil, &result); err != nil {
 		return nil, err
@@ -230,7 +774,9 @@ func (k *KeysClient) List(ctx context.Context) (*ListKeysOutputBody, error) {
 }
 
 // Create creates a new API key.
-func (k *KeysClient) Create(ctx context.Context, name string) (*CreateKeyOutputBody, error) {
+func (k *KeysClient) Create(ctx context.Context, name string, reqOpts ...RequestOption) (*CreateKeyOutputBody, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
+	ctx = k.client.withIdempotencyKey(ctx)
 	var result CreateKeyOutputBody
 	if err := k.client.request(ctx, http.MethodPost, "/api/v1/keys", map[string]string{"name": name}, &result); err != nil {
 		return nil, err
@@ -239,7 +785,8 @@ func (k *KeysClient) Create(ctx context.Context, name string) (*CreateKeyOutputB
 }
 
 // Revoke revokes an API key.
-func (k *KeysClient) Revoke(ctx context.Context, id string) error {
+func (k *KeysClient) Revoke(ctx context.Context, id string, reqOpts ...RequestOption) error {
+	ctx = withRequestOptions(ctx, reqOpts)
 	return k.client.request(ctx, http.MethodDelete, "/api/v1/keys/"+id, nil, nil)
 }
 
@@ -249,7 +796,8 @@ type LLMClient struct {
 }
 
 // ListProviders returns available LLM providers.
-func (l *LLMClient) ListProviders(ctx context.Context) (*ListProvidersOutputBody, error) {
+func (l *LLMClient) ListProviders(ctx context.Context, reqOpts ...RequestOption) (*ListProvidersOutputBody, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
 	var result ListProvidersOutputBody
 	if err := l.client.request(ctx, http.MethodGet, "/api/v1/llm/providers", nil, &result); err != nil {
 		return nil, err
@@ -258,7 +806,8 @@ func (l *LLMClient) ListProviders(ctx context.Context) (*ListProvidersOutputBody
 }
 
 // ListModels returns available models for a provider.
-func (l *LLMClient) ListModels(ctx context.Context, provider string) (*UserListModelsOutputBody, error) {
+func (l *LLMClient) ListModels(ctx context.Context, provider string, reqOpts ...RequestOption) (*UserListModelsOutputBody, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
 	var result UserListModelsOutputBody
 	if err := l.client.request(ctx, http.MethodGet, "/api/v1/llm/models/"+provider, nil, &result); err != nil {
 		return nil, err
@@ -267,7 +816,8 @@ func (l *LLMClient) ListModels(ctx context.Context, provider string) (*UserListM
 }
 
 // ListKeys returns configured LLM provider keys.
-func (l *LLMClient) ListKeys(ctx context.Context) (*ListUserServiceKeysOutputBody, error) {
+func (l *LLMClient) ListKeys(ctx context.Context, reqOpts ...RequestOption) (*ListUserServiceKeysOutputBody, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
 	var result ListUserServiceKeysOutputBody
 	if err := l.client.request(ctx, http.MethodGet, "/api/v1/llm/keys", nil, &result); err != nil {
 		return nil, err
@@ -278,13 +828,29 @@ func (l *LLMClient) ListKeys(ctx context.Context) (*ListUserServiceKeysOutputBod
 // UpsertKeyInput contains parameters for upserting an LLM key.
 type UpsertKeyInput struct {
 	Provider     string `json:"provider"`
-	APIKey       string `json:"api_key"`
+	APIKey       string `json:"api_key,omitempty"`
 	DefaultModel string `json:"default_model"`
 	BaseURL      string `json:"base_url,omitempty"`
+	// EncryptedAPIKey replaces APIKey when the key is encrypted.
+	EncryptedAPIKey *EncryptedSecret `json:"encrypted_api_key,omitempty"`
+	// Encrypt encrypts APIKey against the API's public key before it is
+	// sent, so the raw key never appears in request bodies or proxy logs.
+	Encrypt bool `json:"-"`
 }
 
 // UpsertKey adds or updates an LLM provider key.
-func (l *LLMClient) UpsertKey(ctx context.Context, input UpsertKeyInput) (*UserServiceKeyResponse, error) {
+func (l *LLMClient) UpsertKey(ctx context.Context, input UpsertKeyInput, reqOpts ...RequestOption) (*UserServiceKeyResponse, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
+	if input.Encrypt && input.APIKey != "" {
+		key, err := l.GetEncryptionKey(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if input.EncryptedAPIKey, err = key.Encrypt([]byte(input.APIKey)); err != nil {
+			return nil, err
+		}
+		input.APIKey = ""
+	}
 	var result UserServiceKeyResponse
 	if err := l.client.request(ctx, http.MethodPut, "/api/v1/llm/keys", input, &result); err != nil {
 		return nil, err
@@ -293,12 +859,14 @@ func (l *LLMClient) UpsertKey(ctx context.Context, input UpsertKeyInput) (*UserS
 }
 
 // DeleteKey deletes an LLM provider key.
-func (l *LLMClient) DeleteKey(ctx context.Context, id string) error {
+func (l *LLMClient) DeleteKey(ctx context.Context, id string, reqOpts ...RequestOption) error {
+	ctx = withRequestOptions(ctx, reqOpts)
 	return l.client.request(ctx, http.MethodDelete, "/api/v1/llm/keys/"+id, nil, nil)
 }
 
 // GetChain returns the LLM fallback chain configuration.
-func (l *LLMClient) GetChain(ctx context.Context) (*GetUserFallbackChainOutputBody, error) {
+func (l *LLMClient) GetChain(ctx context.Context, reqOpts ...RequestOption) (*GetUserFallbackChainOutputBody, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
 	var result GetUserFallbackChainOutputBody
 	if err := l.client.request(ctx, http.MethodGet, "/api/v1/llm/chain", nil, &result); err != nil {
 		return nil, err
@@ -314,17 +882,58 @@ type ChainEntry struct {
 }
 
 // SetChain sets the LLM fallback chain configuration.
-func (l *LLMClient) SetChain(ctx context.Context, entries []ChainEntry) error {
+func (l *LLMClient) SetChain(ctx context.Context, entries []ChainEntry, reqOpts ...RequestOption) error {
+	ctx = withRequestOptions(ctx, reqOpts)
 	return l.client.request(ctx, http.MethodPut, "/api/v1/llm/chain", map[string]any{"chain": entries}, nil)
 }
 
+// SimulationInput describes a hypothetical request for SimulateChain.
+type SimulationInput struct {
+	EstimatedTokens      int               `json:"estimated_tokens"`
+	RequiredCapabilities []ModelCapability `json:"required_capabilities,omitempty"`
+}
+
+// SkippedChainEntry is a chain entry passed over during a simulation.
+type SkippedChainEntry struct {
+	ChainEntry
+	Position int    `json:"position"`
+	Reason   string `json:"reason"`
+}
+
+// ChainSimulation is the outcome of SimulateChain. Selected is nil when no
+// entry can handle the request.
+type ChainSimulation struct {
+	Selected *ChainEntry `json:"selected,omitempty"`
+	// Position is the index of Selected in the chain.
+	Position int `json:"position"`
+	// Reason explains why Selected was chosen, or why nothing was.
+	Reason           string              `json:"reason"`
+	Skipped          []SkippedChainEntry `json:"skipped,omitempty"`
+	EstimatedCostUsd float64             `json:"estimated_cost_usd"`
+	UnknownFields    `json:"-"`
+}
+
+// SimulateChain reports which chain entry would handle a request with the
+// given size and capabilities, and why earlier entries would be skipped,
+// without running a job. Use it to catch chain misconfigurations before
+// production jobs pick an unexpected model.
+func (l *LLMClient) SimulateChain(ctx context.Context, input SimulationInput, reqOpts ...RequestOption) (*ChainSimulation, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
+	var result ChainSimulation
+	if err := l.client.request(ctx, http.MethodPost, "/api/v1/llm/chain/simulate", input, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
 // WebhooksClient handles webhook management operations.
 type WebhooksClient struct {
 	client *Client
 }
 
 // List returns all webhooks.
-func (w *WebhooksClient) List(ctx context.Context) (*ListWebhooksOutputBody, error) {
+func (w *WebhooksClient) List(ctx context.Context, reqOpts ...RequestOption) (*ListWebhooksOutputBody, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
 	var result ListWebhooksOutputBody
 	if err := w.client.request(ctx, http.MethodGet, "/api/v1/webhooks", nil, &result); err != nil {
 		return nil, err
@@ -333,7 +942,8 @@ func (w *WebhooksClient) List(ctx context.Context) (*ListWebhooksOutputBody, err
 }
 
 // Get returns a webhook by ID.
-func (w *WebhooksClient) Get(ctx context.Context, id string) (*WebhookResponse, error) {
+func (w *WebhooksClient) Get(ctx context.Context, id string, reqOpts ...RequestOption) (*WebhookResponse, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
 	var result WebhookResponse
 	if err := w.client.request(ctx, http.MethodGet, "/api/v1/webhooks/"+id, nil, &result); err != nil {
 		return nil, err
@@ -341,17 +951,57 @@ func (w *WebhooksClient) Get(ctx context.Context, id string) (*WebhookResponse,
 	return &result, nil
 }
 
+// ChannelType identifies how job event notifications are delivered.
+type ChannelType string
+
+// Notification channel types.
+const (
+	ChannelWebhook ChannelType = "webhook"
+	ChannelSlack   ChannelType = "slack"
+	ChannelEmail   ChannelType = "email"
+)
+
 // CreateWebhookInput contains parameters for creating a webhook.
+//
+// Type selects the delivery channel and defaults to a plain webhook. For
+// Slack channels URL is the Slack incoming webhook URL; for email channels
+// URL is unused and Recipients lists the addresses to notify. Events filters
+// which job events are delivered, for all channel types.
 type CreateWebhookInput struct {
-	Name     string   `json:"name"`
-	URL      string   `json:"url"`
-	Events   []string `json:"events,omitempty"`
-	IsActive bool     `json:"is_active"`
-	Secret   string   `json:"secret,omitempty"`
+	Name       string      `json:"name"`
+	Type       ChannelType `json:"type,omitempty"`
+	URL        string      `json:"url,omitempty"`
+	Recipients []string    `json:"recipients,omitempty"`
+	Events     []string    `json:"events,omitempty"`
+	IsActive   bool        `json:"is_active"`
+	Secret     string      `json:"secret,omitempty"`
+}
+
+// SlackChannel returns input for an active Slack notification channel.
+func SlackChannel(name, slackWebhookURL string, events ...string) CreateWebhookInput {
+	return CreateWebhookInput{
+		Name:     name,
+		Type:     ChannelSlack,
+		URL:      slackWebhookURL,
+		Events:   events,
+		IsActive: true,
+	}
+}
+
+// EmailChannel returns input for an active email notification channel.
+func EmailChannel(name string, recipients []string, events ...string) CreateWebhookInput {
+	return CreateWebhookInput{
+		Name:       name,
+		Type:       ChannelEmail,
+		Recipients: recipients,
+		Events:     events,
+		IsActive:   true,
+	}
 }
 
 // Create creates a new webhook.
-func (w *WebhooksClient) Create(ctx context.Context, input CreateWebhookInput) (*WebhookResponse, error) {
+func (w *WebhooksClient) Create(ctx context.Context, input CreateWebhookInput, reqOpts ...RequestOption) (*WebhookResponse, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
 	var result WebhookResponse
 	if err := w.client.request(ctx, http.MethodPost, "/api/v1/webhooks", input, &result); err != nil {
 		return nil, err
@@ -360,7 +1010,8 @@ func (w *WebhooksClient) Create(ctx context.Context, input CreateWebhookInput) (
 }
 
 // Update updates a webhook.
-func (w *WebhooksClient) Update(ctx context.Context, id string, input CreateWebhookInput) (*WebhookResponse, error) {
+func (w *WebhooksClient) Update(ctx context.Context, id string, input CreateWebhookInput, reqOpts ...RequestOption) (*WebhookResponse, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
 	var result WebhookResponse
 	if err := w.client.request(ctx, http.MethodPut, "/api/v1/webhooks/"+id, input, &result); err != nil {
 		return nil, err
@@ -369,7 +1020,8 @@ func (w *WebhooksClient) Update(ctx context.Context, id string, input CreateWebh
 }
 
 // Delete deletes a webhook.
-func (w *WebhooksClient) Delete(ctx context.Context, id string) error {
+func (w *WebhooksClient) Delete(ctx context.Context, id string, reqOpts ...RequestOption) error {
+	ctx = withRequestOptions(ctx, reqOpts)
 	return w.client.request(ctx, http.MethodDelete, "/api/v1/webhooks/"+id, nil, nil)
 }
 
@@ -377,24 +1029,44 @@ func (w *WebhooksClient) Delete(ctx context.Context, id string) error {
 type ListDeliveriesOptions struct {
 	Limit  int
 	Offset int
+
+	// Status filters by delivery status (pending, success, failed, retrying).
+	Status string
+	// EventType filters by the event that triggered the delivery.
+	EventType string
+	// JobID filters by the job the delivery belongs to.
+	JobID string
+	// Since excludes deliveries created before this time.
+	Since time.Time
 }
 
-// ListDeliveries returns webhook deliveries with optional pagination.
-func (w *WebhooksClient) ListDeliveries(ctx context.Context, id string, opts *ListDeliveriesOptions) (*ListWebhookDeliveriesOutputBody, error) {
+// ListDeliveries returns webhook deliveries with optional pagination and
+// filtering, for example to find events missed while a receiver was down.
+func (w *WebhooksClient) ListDeliveries(ctx context.Context, id string, opts *ListDeliveriesOptions, reqOpts ...RequestOption) (*ListWebhookDeliveriesOutputBody, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
 	path := "/api/v1/webhooks/" + id + "/deliveries"
 	if opts != nil {
-		params := ""
+		params := url.Values{}
 		if opts.Limit > 0 {
-			params += fmt.Sprintf("limit=%d", opts.Limit)
+			params.Set("limit", strconv.Itoa(opts.Limit))
 		}
 		if opts.Offset > 0 {
-			if params != "" {
-				params += "&"
-			}
-			params += fmt.Sprintf("offset=%d", opts.Offset)
+			params.Set("offset", strconv.Itoa(opts.Offset))
 		}
-		if params != "" {
-			path += "?" + params
+		if opts.Status != "" {
+			params.Set("status", opts.Status)
+		}
+		if opts.EventType != "" {
+			params.Set("event_type", opts.EventType)
+		}
+		if opts.JobID != "" {
+			params.Set("job_id", opts.JobID)
+		}
+		if !opts.Since.IsZero() {
+			params.Set("since", opts.Since.UTC().Format(time.RFC3339))
+		}
+		if len(params) > 0 {
+			path += "?" + params.Encode()
 		}
 	}
 
@@ -404,3 +1076,37 @@ func (w *WebhooksClient) ListDeliveries(ctx context.Context, id string, opts *Li
 	}
 	return &result, nil
 }
+
+// RedeliverEvent queues a previous webhook delivery to be sent again and
+// returns the new delivery.
+func (w *WebhooksClient) RedeliverEvent(ctx context.Context, deliveryID string, reqOpts ...RequestOption) (*WebhookDeliveryResponse, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
+	var result WebhookDeliveryResponse
+	if err := w.client.request(ctx, http.MethodPost, "/api/v1/webhooks/deliveries/"+deliveryID+"/redeliver", nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// RotateSecretOutput contains the secrets returned by RotateSecret.
+type RotateSecretOutput struct {
+	// Secret is the new signing secret.
+	Secret string `json:"secret"`
+	// PreviousSecret remains valid until PreviousSecretExpiresAt, so
+	// receivers can accept both while they are updated.
+	PreviousSecret          string    `json:"previous_secret"`
+	PreviousSecretExpiresAt time.Time `json:"previous_secret_expires_at"`
+}
+
+// RotateSecret generates a new signing secret for a webhook. Deliveries are
+// signed with the new secret immediately, and the previous secret stays
+// valid for a grace period; pass both to VerifyWebhookSignature until it
+// expires.
+func (w *WebhooksClient) RotateSecret(ctx context.Context, id string, reqOpts ...RequestOption) (*RotateSecretOutput, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
+	var result RotateSecretOutput
+	if err := w.client.request(ctx, http.MethodPost, "/api/v1/webhooks/"+id+"/rotate-secret", nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
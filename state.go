@@ -0,0 +1,152 @@
+package refyne
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// StateStore persists the progress of long-running helpers, such as
+// Backfill and CrawlAndWait, so a worker process that crashes or is
+// restarted can resume where it stopped, without resubmitting jobs or
+// losing track of them. State is saved as JSON under a key chosen by the
+// caller. Implementations must be safe for concurrent use.
+type StateStore interface {
+	// Get returns the state saved under key, or false if there is none.
+	Get(key string) ([]byte, bool, error)
+	Put(key string, state []byte) error
+	Delete(key string) error
+}
+
+// MemoryStateStore is a StateStore that keeps state in memory, so helpers
+// can resume after an error but not after a restart.
+type MemoryStateStore struct {
+	mu     sync.Mutex
+	states map[string][]byte
+}
+
+// NewMemoryStateStore creates an empty MemoryStateStore.
+func NewMemoryStateStore() *MemoryStateStore {
+	return &MemoryStateStore{states: map[string][]byte{}}
+}
+
+// Get returns the state saved under key.
+func (m *MemoryStateStore) Get(key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	state, ok := m.states[key]
+	return state, ok, nil
+}
+
+// Put saves state under key, replacing any saved before.
+func (m *MemoryStateStore) Put(key string, state []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.states[key] = append([]byte(nil), state...)
+	return nil
+}
+
+// Delete removes the state saved under key.
+func (m *MemoryStateStore) Delete(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.states, key)
+	return nil
+}
+
+// FileStateStore is a durable StateStore that keeps each key's state in a
+// file in a directory.
+type FileStateStore struct {
+	dir string
+}
+
+// NewFileStateStore creates a FileStateStore in dir, creating the
+// directory if needed.
+func NewFileStateStore(dir string) (*FileStateStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+	return &FileStateStore{dir: dir}, nil
+}
+
+// path returns the file for key; keys are hashed as they may contain any
+// characters.
+func (f *FileStateStore) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(f.dir, hex.EncodeToString(sum[:])+".state")
+}
+
+// Get reads the state saved under key.
+func (f *FileStateStore) Get(key string) ([]byte, bool, error) {
+	state, err := os.ReadFile(f.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return state, true, nil
+}
+
+// Put writes state under key atomically and syncs it to disk.
+func (f *FileStateStore) Put(key string, state []byte) error {
+	return writeFileDurable(f.path(key), state)
+}
+
+// Delete removes the state saved under key.
+func (f *FileStateStore) Delete(key string) error {
+	if err := os.Remove(f.path(key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// loadState decodes the state saved under key into v, reporting whether
+// there was any.
+func loadState(store StateStore, key string, v any) (bool, error) {
+	data, ok, err := store.Get(key)
+	if err != nil || !ok {
+		return false, err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, fmt.Errorf("corrupt state %q: %w", key, err)
+	}
+	return true, nil
+}
+
+// saveState encodes v and saves it under key.
+func saveState(store StateStore, key string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return store.Put(key, data)
+}
+
+// writeFileDurable writes data to path through a temporary file that is
+// synced before it replaces path, so a crash leaves either the old or the
+// new content.
+func writeFileDurable(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
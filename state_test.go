@@ -0,0 +1,33 @@
+package refyne
+
+import "testing"
+
+func TestFileStateStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileStateStore(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok, err := store.Get("run/1"); ok || err != nil {
+		t.Fatalf("expected no state, got %v, %v", ok, err)
+	}
+	if err := store.Put("run/1", []byte(`{"job_id":"job-1"}`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// A new store over the same directory sees the state.
+	reopened, _ := NewFileStateStore(dir)
+	var state crawlState
+	if ok, err := loadState(reopened, "run/1", &state); !ok || err != nil || state.JobID != "job-1" {
+		t.Fatalf("expected the saved state, got %+v, %v, %v", state, ok, err)
+	}
+	if err := reopened.Delete("run/1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok, _ := store.Get("run/1"); ok {
+		t.Error("expected the state to be deleted")
+	}
+	if err := store.Delete("run/1"); err != nil {
+		t.Errorf("expected deleting missing state to succeed, got %v", err)
+	}
+}
@@ -0,0 +1,463 @@
+package refyne
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ResourcesSpec declares the schemas, sites, schedules and webhooks an
+// account should have. Resources are matched to existing ones by name, and
+// sites and schedules refer to other resources by name so a spec can be
+// written before any IDs exist.
+type ResourcesSpec struct {
+	Schemas   []CreateSchemaInput
+	Sites     []SiteSpec
+	Schedules []ScheduleSpec
+	Webhooks  []CreateWebhookInput
+}
+
+// SiteSpec declares a saved site.
+type SiteSpec struct {
+	Name          string
+	URL           string
+	DefaultSchema string // schema name
+	FetchMode     string
+	// AlertRules are not returned by the API, so a change to them alone
+	// does not update an existing site.
+	AlertRules []AlertRule
+}
+
+// ScheduleSpec declares a scheduled crawl.
+type ScheduleSpec struct {
+	Name     string
+	Site     string // site name
+	Schema   string // schema name, optional
+	Cron     string
+	IsActive bool
+}
+
+// SyncOptions controls how Sync applies a spec.
+type SyncOptions struct {
+	// DryRun computes the plan without changing anything.
+	DryRun bool
+	// Prune deletes resources that exist in the account but not in the spec.
+	// Platform schemas are never pruned.
+	Prune bool
+}
+
+// ResourceKind identifies the type of resource in a SyncAction.
+type ResourceKind string
+
+// Resource kinds managed by Sync.
+const (
+	ResourceSchema   ResourceKind = "schema"
+	ResourceSite     ResourceKind = "site"
+	ResourceSchedule ResourceKind = "schedule"
+	ResourceWebhook  ResourceKind = "webhook"
+)
+
+// SyncOp is the change Sync makes to a resource.
+type SyncOp string
+
+// Sync operations.
+const (
+	SyncCreate SyncOp = "create"
+	SyncUpdate SyncOp = "update"
+	SyncDelete SyncOp = "delete"
+)
+
+// SyncAction is a single change in a SyncPlan. ID is empty for resources
+// that have not been created yet.
+type SyncAction struct {
+	Kind ResourceKind
+	Op   SyncOp
+	Name string
+	ID   string
+}
+
+func (a SyncAction) String() string {
+	return fmt.Sprintf("%s %s %q", a.Op, a.Kind, a.Name)
+}
+
+// SyncPlan lists the changes Sync made, or would make in a dry run.
+type SyncPlan struct {
+	Actions []SyncAction
+}
+
+// Empty reports whether the account already matches the spec.
+func (p *SyncPlan) Empty() bool {
+	return len(p.Actions) == 0
+}
+
+// Sync reconciles the account's resources with desired. Schemas are applied
+// first, then sites, schedules and webhooks; deletions run in the reverse
+// order so nothing is removed while still referenced. On error the returned
+// plan contains the actions applied so far.
+//
+// Resources are compared by the fields the API reports back. Webhooks do
+// not report their channel type or email recipients, so webhooks that set
+// Type or Recipients, such as Slack and email channels, are updated on
+// every run and a plan is never empty while the spec has any.
+func Sync(ctx context.Context, client *Client, desired ResourcesSpec, opts *SyncOptions) (*SyncPlan, error) {
+	if opts == nil {
+		opts = &SyncOptions{}
+	}
+	s := &syncer{client: client, opts: opts, plan: &SyncPlan{}}
+	return s.plan, s.run(ctx, desired)
+}
+
+// run loads the account and applies desired to it.
+func (s *syncer) run(ctx context.Context, desired ResourcesSpec) error {
+	if err := s.load(ctx); err != nil {
+		return err
+	}
+
+	steps := []func(context.Context, ResourcesSpec) error{
+		s.syncSchemas,
+		s.syncSites,
+		s.syncSchedules,
+		s.syncWebhooks,
+	}
+	for _, step := range steps {
+		if err := step(ctx, desired); err != nil {
+			return err
+		}
+	}
+	if s.opts.Prune {
+		return s.prune(ctx, desired)
+	}
+	return nil
+}
+
+// syncer holds the state of a single Sync run.
+type syncer struct {
+	client *Client
+	opts   *SyncOptions
+	plan   *SyncPlan
+
+	schemas   map[string]SchemaOutput
+	sites     map[string]SavedSiteOutput
+	schedules map[string]Schedule
+	webhooks  map[string]WebhookResponse
+
+	// ids maps kind and name to the ID of resources created during this run.
+	ids map[ResourceKind]map[string]string
+}
+
+func (s *syncer) load(ctx context.Context) error {
+	s.ids = map[ResourceKind]map[string]string{ResourceSchema: {}, ResourceSite: {}, ResourceSchedule: {}, ResourceWebhook: {}}
+
+	schemas, err := s.client.Schemas.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list schemas: %w", err)
+	}
+	s.schemas = map[string]SchemaOutput{}
+	if schemas.Schemas != nil {
+		for _, schema := range *schemas.Schemas {
+			if !schema.IsPlatform {
+				s.schemas[schema.Name] = schema
+			}
+		}
+	}
+
+	sites, err := s.client.Sites.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list sites: %w", err)
+	}
+	s.sites = map[string]SavedSiteOutput{}
+	if sites.Sites != nil {
+		for _, site := range *sites.Sites {
+			if site.Name != nil {
+				s.sites[*site.Name] = site
+			}
+		}
+	}
+
+	schedules, err := s.client.Schedules.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list schedules: %w", err)
+	}
+	s.schedules = map[string]Schedule{}
+	for _, schedule := range schedules.Schedules {
+		s.schedules[schedule.Name] = schedule
+	}
+
+	webhooks, err := s.client.Webhooks.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	s.webhooks = map[string]WebhookResponse{}
+	if webhooks.Webhooks != nil {
+		for _, webhook := range *webhooks.Webhooks {
+			s.webhooks[webhook.Name] = webhook
+		}
+	}
+	return nil
+}
+
+// apply records action and, unless this is a dry run, performs it with fn.
+// fn returns the ID of created resources.
+func (s *syncer) apply(action SyncAction, fn func() (string, error)) error {
+	if !s.opts.DryRun {
+		id, err := fn()
+		if err != nil {
+			return fmt.Errorf("failed to %s: %w", action, err)
+		}
+		if action.ID == "" {
+			action.ID = id
+		}
+	}
+	if ids, ok := s.ids[action.Kind]; ok && action.Op != SyncDelete {
+		ids[action.Name] = action.ID
+	}
+	s.plan.Actions = append(s.plan.Actions, action)
+	return nil
+}
+
+// resolve returns the ID of the named resource. The second result is false
+// if the resource does not exist yet, which only happens in a dry run.
+func (s *syncer) resolve(kind ResourceKind, name string) (string, bool, error) {
+	if name == "" {
+		return "", true, nil
+	}
+	if id, ok := s.ids[kind][name]; ok {
+		return id, id != "", nil
+	}
+	switch kind {
+	case ResourceSchema:
+		if schema, ok := s.schemas[name]; ok {
+			return schema.Id, true, nil
+		}
+	case ResourceSite:
+		if site, ok := s.sites[name]; ok {
+			return site.Id, true, nil
+		}
+	case ResourceSchedule:
+		if schedule, ok := s.schedules[name]; ok {
+			return schedule.ID, true, nil
+		}
+	case ResourceWebhook:
+		if webhook, ok := s.webhooks[name]; ok {
+			return webhook.Id, true, nil
+		}
+	}
+	return "", false, fmt.Errorf("%s %q is not defined", kind, name)
+}
+
+func (s *syncer) syncSchemas(ctx context.Context, desired ResourcesSpec) error {
+	for _, input := range desired.Schemas {
+		existing, ok := s.schemas[input.Name]
+		var err error
+		switch {
+		case !ok:
+			err = s.apply(SyncAction{Kind: ResourceSchema, Op: SyncCreate, Name: input.Name}, func() (string, error) {
+				created, err := s.client.Schemas.Create(ctx, input)
+				if err != nil {
+					return "", err
+				}
+				return created.Id, nil
+			})
+		case strings.TrimSpace(existing.SchemaYaml) != strings.TrimSpace(input.SchemaYAML) ||
+			(input.Visibility != "" && existing.Visibility != input.Visibility):
+			err = s.apply(SyncAction{Kind: ResourceSchema, Op: SyncUpdate, Name: input.Name, ID: existing.Id}, func() (string, error) {
+				_, err := s.client.Schemas.Update(ctx, existing.Id, input)
+				return existing.Id, err
+			})
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *syncer) syncSites(ctx context.Context, desired ResourcesSpec) error {
+	for _, spec := range desired.Sites {
+		schemaID, resolved, err := s.resolve(ResourceSchema, spec.DefaultSchema)
+		if err != nil {
+			return fmt.Errorf("site %q: %w", spec.Name, err)
+		}
+		input := CreateSiteInput{Name: spec.Name, URL: spec.URL, DefaultSchemaID: schemaID, FetchMode: spec.FetchMode, AlertRules: spec.AlertRules}
+
+		existing, ok := s.sites[spec.Name]
+		switch {
+		case !ok:
+			err = s.apply(SyncAction{Kind: ResourceSite, Op: SyncCreate, Name: spec.Name}, func() (string, error) {
+				created, err := s.client.Sites.Create(ctx, input)
+				if err != nil {
+					return "", err
+				}
+				return created.Id, nil
+			})
+		case !resolved || siteChanged(existing, input):
+			err = s.apply(SyncAction{Kind: ResourceSite, Op: SyncUpdate, Name: spec.Name, ID: existing.Id}, func() (string, error) {
+				_, err := s.client.Sites.Update(ctx, existing.Id, input)
+				return existing.Id, err
+			})
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func siteChanged(existing SavedSiteOutput, input CreateSiteInput) bool {
+	schemaID := ""
+	if existing.DefaultSchemaId != nil {
+		schemaID = *existing.DefaultSchemaId
+	}
+	return existing.Url != input.URL ||
+		schemaID != input.DefaultSchemaID ||
+		(input.FetchMode != "" && existing.FetchMode != input.FetchMode)
+}
+
+func (s *syncer) syncSchedules(ctx context.Context, desired ResourcesSpec) error {
+	for _, spec := range desired.Schedules {
+		siteID, siteResolved, err := s.resolve(ResourceSite, spec.Site)
+		if err != nil {
+			return fmt.Errorf("schedule %q: %w", spec.Name, err)
+		}
+		schemaID, schemaResolved, err := s.resolve(ResourceSchema, spec.Schema)
+		if err != nil {
+			return fmt.Errorf("schedule %q: %w", spec.Name, err)
+		}
+		input := CreateScheduleInput{Name: spec.Name, SiteID: siteID, SchemaID: schemaID, Cron: spec.Cron, IsActive: spec.IsActive}
+
+		existing, ok := s.schedules[spec.Name]
+		changed := !siteResolved || !schemaResolved ||
+			existing.SiteID != input.SiteID || existing.SchemaID != input.SchemaID ||
+			existing.Cron != input.Cron || existing.IsActive != input.IsActive
+		switch {
+		case !ok:
+			err = s.apply(SyncAction{Kind: ResourceSchedule, Op: SyncCreate, Name: spec.Name}, func() (string, error) {
+				created, err := s.client.Schedules.Create(ctx, input)
+				if err != nil {
+					return "", err
+				}
+				return created.ID, nil
+			})
+		case changed:
+			err = s.apply(SyncAction{Kind: ResourceSchedule, Op: SyncUpdate, Name: spec.Name, ID: existing.ID}, func() (string, error) {
+				_, err := s.client.Schedules.Update(ctx, existing.ID, input)
+				return existing.ID, err
+			})
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *syncer) syncWebhooks(ctx context.Context, desired ResourcesSpec) error {
+	for _, input := range desired.Webhooks {
+		existing, ok := s.webhooks[input.Name]
+		var err error
+		switch {
+		case !ok:
+			err = s.apply(SyncAction{Kind: ResourceWebhook, Op: SyncCreate, Name: input.Name}, func() (string, error) {
+				created, err := s.client.Webhooks.Create(ctx, input)
+				if err != nil {
+					return "", err
+				}
+				return created.Id, nil
+			})
+		case webhookChanged(existing, input):
+			err = s.apply(SyncAction{Kind: ResourceWebhook, Op: SyncUpdate, Name: input.Name, ID: existing.Id}, func() (string, error) {
+				_, err := s.client.Webhooks.Update(ctx, existing.Id, input)
+				return existing.Id, err
+			})
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// webhookChanged compares the fields a webhook reports back. Secrets are
+// write-only, so a changed secret alone is not detected. The channel type
+// and recipients are not reported, so a channel that sets them is always
+// updated.
+func webhookChanged(existing WebhookResponse, input CreateWebhookInput) bool {
+	if input.Type != "" || len(input.Recipients) > 0 {
+		return true
+	}
+	var events []string
+	if existing.Events != nil {
+		events = *existing.Events
+	}
+	return existing.Url != input.URL ||
+		existing.IsActive != input.IsActive ||
+		!slices.Equal(sortedCopy(events), sortedCopy(input.Events)) ||
+		(input.Secret != "" && !existing.HasSecret)
+}
+
+func sortedCopy(values []string) []string {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	return sorted
+}
+
+func (s *syncer) prune(ctx context.Context, desired ResourcesSpec) error {
+	wantSchedules := nameSet(desired.Schedules, func(s ScheduleSpec) string { return s.Name })
+	wantSites := nameSet(desired.Sites, func(s SiteSpec) string { return s.Name })
+	wantSchemas := nameSet(desired.Schemas, func(s CreateSchemaInput) string { return s.Name })
+	wantWebhooks := nameSet(desired.Webhooks, func(w CreateWebhookInput) string { return w.Name })
+
+	for _, name := range sortedKeys(s.schedules) {
+		if id := s.schedules[name].ID; !wantSchedules[name] {
+			if err := s.delete(ResourceSchedule, name, id, func() error { return s.client.Schedules.Delete(ctx, id) }); err != nil {
+				return err
+			}
+		}
+	}
+	for _, name := range sortedKeys(s.sites) {
+		if id := s.sites[name].Id; !wantSites[name] {
+			if err := s.delete(ResourceSite, name, id, func() error { return s.client.Sites.Delete(ctx, id) }); err != nil {
+				return err
+			}
+		}
+	}
+	for _, name := range sortedKeys(s.schemas) {
+		if id := s.schemas[name].Id; !wantSchemas[name] {
+			if err := s.delete(ResourceSchema, name, id, func() error { return s.client.Schemas.Delete(ctx, id) }); err != nil {
+				return err
+			}
+		}
+	}
+	for _, name := range sortedKeys(s.webhooks) {
+		if id := s.webhooks[name].Id; !wantWebhooks[name] {
+			if err := s.delete(ResourceWebhook, name, id, func() error { return s.client.Webhooks.Delete(ctx, id) }); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *syncer) delete(kind ResourceKind, name, id string, fn func() error) error {
+	return s.apply(SyncAction{Kind: kind, Op: SyncDelete, Name: name, ID: id}, func() (string, error) {
+		return id, fn()
+	})
+}
+
+func nameSet[T any](items []T, name func(T) string) map[string]bool {
+	set := make(map[string]bool, len(items))
+	for _, item := range items {
+		set[name(item)] = true
+	}
+	return set
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,181 @@
+package refyne
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// syncServer serves a fixed account and records mutating requests.
+func syncServer(t *testing.T) (*httptest.Server, *[]string) {
+	var mu sync.Mutex
+	var calls []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			mu.Lock()
+			calls = append(calls, r.Method+" "+r.URL.Path)
+			mu.Unlock()
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/schemas":
+			_ = json.NewEncoder(w).Encode(map[string]any{"schemas": []any{
+				map[string]any{"id": "schema-1", "name": "products", "schema_yaml": "name: string\n", "visibility": "private"},
+				map[string]any{"id": "schema-2", "name": "old", "schema_yaml": "x: string\n", "visibility": "private"},
+				map[string]any{"id": "schema-p", "name": "platform", "is_platform": true},
+			}})
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/sites":
+			_ = json.NewEncoder(w).Encode(map[string]any{"sites": []any{
+				map[string]any{"id": "site-1", "name": "shop", "url": "https://shop.example.com", "default_schema_id": "schema-1", "fetch_mode": "auto"},
+			}})
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/schedules":
+			_ = json.NewEncoder(w).Encode(map[string]any{"schedules": []any{}})
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/webhooks":
+			_ = json.NewEncoder(w).Encode(map[string]any{"webhooks": []any{}})
+		case r.Method == http.MethodPost:
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "new-" + r.URL.Path[len("/api/v1/"):]})
+		default:
+			_ = json.NewEncoder(w).Encode(map[string]any{})
+		}
+	}))
+	return server, &calls
+}
+
+func TestSync(t *testing.T) {
+	server, calls := syncServer(t)
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL), WithCacheEnabled(false))
+	spec := ResourcesSpec{
+		Schemas: []CreateSchemaInput{
+			{Name: "products", SchemaYAML: "name: string\nprice: number\n"},
+			{Name: "articles", SchemaYAML: "title: string\n"},
+		},
+		Sites: []SiteSpec{
+			{Name: "shop", URL: "https://shop.example.com", DefaultSchema: "products"},
+			{Name: "blog", URL: "https://blog.example.com", DefaultSchema: "articles"},
+		},
+		Schedules: []ScheduleSpec{
+			{Name: "nightly", Site: "shop", Cron: "0 2 * * *", IsActive: true},
+		},
+	}
+
+	plan, err := Sync(context.Background(), client, spec, &SyncOptions{DryRun: true, Prune: true})
+	if err != nil {
+		t.Fatalf("dry run failed: %v", err)
+	}
+	if len(*calls) != 0 {
+		t.Errorf("expected dry run to make no changes, got %v", *calls)
+	}
+
+	want := []string{
+		`update schema "products"`,
+		`create schema "articles"`,
+		`create site "blog"`,
+		`create schedule "nightly"`,
+		`delete schema "old"`,
+	}
+	if len(plan.Actions) != len(want) {
+		t.Fatalf("expected plan %v, got %v", want, plan.Actions)
+	}
+	for i := range want {
+		if got := plan.Actions[i].String(); got != want[i] {
+			t.Errorf("action %d: expected %s, got %s", i, want[i], got)
+		}
+	}
+
+	if _, err := Sync(context.Background(), client, spec, &SyncOptions{Prune: true}); err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+	wantCalls := []string{
+		"PUT /api/v1/schemas/schema-1",
+		"POST /api/v1/schemas",
+		"POST /api/v1/sites",
+		"POST /api/v1/schedules",
+		"DELETE /api/v1/schemas/schema-2",
+	}
+	if len(*calls) != len(wantCalls) {
+		t.Fatalf("expected calls %v, got %v", wantCalls, *calls)
+	}
+	for i := range wantCalls {
+		if (*calls)[i] != wantCalls[i] {
+			t.Errorf("call %d: expected %s, got %s", i, wantCalls[i], (*calls)[i])
+		}
+	}
+}
+
+func TestSyncUndefinedReference(t *testing.T) {
+	server, _ := syncServer(t)
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL))
+	_, err := Sync(context.Background(), client, ResourcesSpec{
+		Sites: []SiteSpec{{Name: "shop", URL: "https://shop.example.com", DefaultSchema: "missing"}},
+	}, nil)
+	if err == nil {
+		t.Fatal("expected error for undefined schema reference")
+	}
+}
+
+func TestCollectionApply(t *testing.T) {
+	server, calls := syncServer(t)
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL), WithCacheEnabled(false))
+	col := client.Collections.Define(Collection{
+		Name:       "deals",
+		URL:        "https://deals.example.com",
+		SchemaYAML: "title: string\n",
+		Cron:       "0 * * * *",
+		Delivery:   &CreateWebhookInput{URL: "https://hooks.example.com", IsActive: true},
+		AlertRules: []AlertRule{{Name: "no titles", Field: "title", Condition: AlertNullRate, Threshold: 0.5, Action: AlertActionWarn}},
+	})
+	plan, err := col.Apply(context.Background())
+	if err != nil {
+		t.Fatalf("apply failed: %v", err)
+	}
+	if len(plan.Actions) != 4 {
+		t.Errorf("expected 4 actions, got %v", plan.Actions)
+	}
+	wantCalls := []string{
+		"POST /api/v1/schemas",
+		"POST /api/v1/sites",
+		"POST /api/v1/schedules",
+		"POST /api/v1/webhooks",
+	}
+	if len(*calls) != len(wantCalls) {
+		t.Fatalf("expected calls %v, got %v", wantCalls, *calls)
+	}
+	if col.SchemaID != "new-schemas" || col.SiteID != "new-sites" || col.ScheduleID != "new-schedules" || col.DeliveryID != "new-webhooks" {
+		t.Errorf("unexpected IDs: %q %q %q %q", col.SchemaID, col.SiteID, col.ScheduleID, col.DeliveryID)
+	}
+
+	spec := col.Spec()
+	if spec.Webhooks[0].Name != "deals" || len(spec.Sites[0].AlertRules) != 1 || spec.Sites[0].DefaultSchema != "deals" {
+		t.Errorf("unexpected spec: %+v", spec)
+	}
+
+	if _, err := (&Collection{Name: "x"}).Apply(context.Background()); err == nil {
+		t.Error("expected an error applying an undefined collection")
+	}
+}
+
+func TestWebhookChangedChannels(t *testing.T) {
+	events := []string{"job.completed"}
+	existing := WebhookResponse{Name: "alerts", Url: "https://hooks.example/x", IsActive: true, Events: &events}
+
+	plain := CreateWebhookInput{Name: "alerts", URL: "https://hooks.example/x", IsActive: true, Events: events}
+	if webhookChanged(existing, plain) {
+		t.Error("expected an identical webhook to be unchanged")
+	}
+	// The API does not report type or recipients, so these always update.
+	if !webhookChanged(existing, SlackChannel("alerts", "https://hooks.example/x", events...)) {
+		t.Error("expected a Slack channel to be updated")
+	}
+	if !webhookChanged(existing, EmailChannel("alerts", []string{"ops@example.com"}, events...)) {
+		t.Error("expected an email channel to be updated")
+	}
+}
@@ -0,0 +1,191 @@
+package refyne
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// TemplatesClient handles request template operations.
+type TemplatesClient struct {
+	client *Client
+}
+
+// TemplateKind is the kind of request a template instantiates.
+type TemplateKind string
+
+// Template kinds.
+const (
+	TemplateExtract TemplateKind = "extract"
+	TemplateCrawl   TemplateKind = "crawl"
+)
+
+// Template is a stored, parameterized Extract or Crawl request. Its URL may
+// contain {name} placeholders, e.g. "https://shop.example/p/{sku}", which
+// are filled in with variables when the template is instantiated, so many
+// near-identical requests share one definition.
+type Template struct {
+	ID           string          `json:"id"`
+	Name         string          `json:"name"`
+	Kind         TemplateKind    `json:"kind"`
+	URL          string          `json:"url"`
+	Schema       any             `json:"schema"`
+	FetchOptions *FetchOptions   `json:"fetch_options,omitempty"`
+	Options      *CrawlOptions   `json:"options,omitempty"`
+	LLMConfig    *LLMConfigInput `json:"llm_config,omitempty"`
+	CreatedAt    string          `json:"created_at,omitempty"`
+	UpdatedAt    string          `json:"updated_at,omitempty"`
+}
+
+// templateVariable matches a {name} placeholder.
+var templateVariable = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// Variables returns the names of the placeholders in the template's URL.
+func (t *Template) Variables() []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, m := range templateVariable.FindAllStringSubmatch(t.URL, -1) {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			names = append(names, m[1])
+		}
+	}
+	return names
+}
+
+// expandURL fills in the URL's placeholders with path-escaped variables.
+func (t *Template) expandURL(vars map[string]string) (string, error) {
+	var missing []string
+	expanded := templateVariable.ReplaceAllStringFunc(t.URL, func(m string) string {
+		name := m[1 : len(m)-1]
+		value, ok := vars[name]
+		if !ok {
+			missing = append(missing, name)
+			return m
+		}
+		return url.PathEscape(value)
+	})
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return "", fmt.Errorf("template %s: missing variables: %s", t.Name, strings.Join(missing, ", "))
+	}
+	return expanded, nil
+}
+
+// ExtractInput instantiates an extract template with vars.
+func (t *Template) ExtractInput(vars map[string]string) (ExtractInput, error) {
+	if t.Kind != TemplateExtract {
+		return ExtractInput{}, fmt.Errorf("template %s is a %s template", t.Name, t.Kind)
+	}
+	u, err := t.expandURL(vars)
+	if err != nil {
+		return ExtractInput{}, err
+	}
+	return ExtractInput{URL: u, Schema: t.Schema, FetchOptions: t.FetchOptions, LLMConfig: t.LLMConfig}, nil
+}
+
+// CrawlInput instantiates a crawl template with vars.
+func (t *Template) CrawlInput(vars map[string]string) (CrawlInput, error) {
+	if t.Kind != TemplateCrawl {
+		return CrawlInput{}, fmt.Errorf("template %s is a %s template", t.Name, t.Kind)
+	}
+	u, err := t.expandURL(vars)
+	if err != nil {
+		return CrawlInput{}, err
+	}
+	return CrawlInput{URL: u, Schema: t.Schema, Options: t.Options, LLMConfig: t.LLMConfig}, nil
+}
+
+// CreateTemplateInput contains parameters for creating a template.
+type CreateTemplateInput struct {
+	Name         string          `json:"name"`
+	Kind         TemplateKind    `json:"kind"`
+	URL          string          `json:"url"`
+	Schema       any             `json:"schema"`
+	FetchOptions *FetchOptions   `json:"fetch_options,omitempty"`
+	Options      *CrawlOptions   `json:"options,omitempty"`
+	LLMConfig    *LLMConfigInput `json:"llm_config,omitempty"`
+}
+
+// ListTemplatesOutput contains the templates returned by List.
+type ListTemplatesOutput struct {
+	Templates []Template `json:"templates"`
+}
+
+// List returns all templates.
+func (t *TemplatesClient) List(ctx context.Context, reqOpts ...RequestOption) (*ListTemplatesOutput, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
+	var result ListTemplatesOutput
+	if err := t.client.request(ctx, http.MethodGet, "/api/v1/templates", nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Get returns a template by ID.
+func (t *TemplatesClient) Get(ctx context.Context, id string, reqOpts ...RequestOption) (*Template, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
+	var result Template
+	if err := t.client.request(ctx, http.MethodGet, "/api/v1/templates/"+id, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Create creates a new template.
+func (t *TemplatesClient) Create(ctx context.Context, input CreateTemplateInput, reqOpts ...RequestOption) (*Template, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
+	var result Template
+	if err := t.client.request(ctx, http.MethodPost, "/api/v1/templates", input, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Update updates a template.
+func (t *TemplatesClient) Update(ctx context.Context, id string, input CreateTemplateInput, reqOpts ...RequestOption) (*Template, error) {
+	ctx = withRequestOptions(ctx, reqOpts)
+	var result Template
+	if err := t.client.request(ctx, http.MethodPut, "/api/v1/templates/"+id, input, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Delete deletes a template.
+func (t *TemplatesClient) Delete(ctx context.Context, id string, reqOpts ...RequestOption) error {
+	ctx = withRequestOptions(ctx, reqOpts)
+	return t.client.request(ctx, http.MethodDelete, "/api/v1/templates/"+id, nil, nil)
+}
+
+// Extract instantiates the extract template id with vars and runs it. The
+// template is fetched through the response cache, so repeated calls only
+// refetch it when the API's caching headers say so.
+func (t *TemplatesClient) Extract(ctx context.Context, id string, vars map[string]string, reqOpts ...RequestOption) (*ExtractOutput, error) {
+	tmpl, err := t.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	input, err := tmpl.ExtractInput(vars)
+	if err != nil {
+		return nil, err
+	}
+	return t.client.Extract(ctx, input, reqOpts...)
+}
+
+// Crawl instantiates the crawl template id with vars and starts the crawl.
+func (t *TemplatesClient) Crawl(ctx context.Context, id string, vars map[string]string, reqOpts ...RequestOption) (*CrawlJobResponseBody, error) {
+	tmpl, err := t.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	input, err := tmpl.CrawlInput(vars)
+	if err != nil {
+		return nil, err
+	}
+	return t.client.Crawl(ctx, input, reqOpts...)
+}
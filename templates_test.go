@@ -0,0 +1,69 @@
+package refyne
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTemplates(t *testing.T) {
+	templateFetches := 0
+	var extracted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/templates/tmpl-1":
+			templateFetches++
+			w.Header().Set("Cache-Control", "max-age=60")
+			_ = json.NewEncoder(w).Encode(Template{
+				ID:     "tmpl-1",
+				Name:   "product",
+				Kind:   TemplateExtract,
+				URL:    "https://shop.example/{region}/p/{sku}",
+				Schema: map[string]any{"price": "number"},
+			})
+		case "/api/v1/extract":
+			var input ExtractInput
+			_ = json.NewDecoder(r.Body).Decode(&input)
+			if !reflect.DeepEqual(input.Schema, map[string]any{"price": "number"}) {
+				t.Errorf("expected the template schema, got %v", input.Schema)
+			}
+			extracted = append(extracted, input.URL)
+			_ = json.NewEncoder(w).Encode(map[string]any{"job_id": "job-1", "url": input.URL})
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL))
+	for _, sku := range []string{"A-1", "B 2"} {
+		if _, err := client.Templates.Extract(context.Background(), "tmpl-1", map[string]string{"region": "uk", "sku": sku}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if !reflect.DeepEqual(extracted, []string{"https://shop.example/uk/p/A-1", "https://shop.example/uk/p/B%202"}) {
+		t.Errorf("unexpected URLs: %v", extracted)
+	}
+	if templateFetches != 1 {
+		t.Errorf("expected the template to be cached, fetched %d times", templateFetches)
+	}
+
+	_, err := client.Templates.Extract(context.Background(), "tmpl-1", map[string]string{"sku": "A-1"})
+	if err == nil || !strings.Contains(err.Error(), "missing variables: region") {
+		t.Errorf("expected a missing variable error, got %v", err)
+	}
+	if _, err := client.Templates.Crawl(context.Background(), "tmpl-1", nil); err == nil {
+		t.Error("expected an error instantiating an extract template as a crawl")
+	}
+}
+
+func TestTemplateVariables(t *testing.T) {
+	tmpl := Template{URL: "https://example.com/{a}/{b}?q={a}"}
+	if got := tmpl.Variables(); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("expected [a b], got %v", got)
+	}
+}
@@ -0,0 +1,153 @@
+package refyne
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// RequestTiming is the latency breakdown of a single HTTP attempt. DNS,
+// Connect and TLS are zero when an idle connection was reused. Compare
+// Total with the fetch and extract durations in ExtractionMetadata to see
+// how much time was spent on the target site.
+type RequestTiming struct {
+	Method    string
+	Path      string
+	RequestID string
+	Attempt   int
+	// Status is zero if no response was received.
+	Status int
+
+	DNS        time.Duration
+	Connect    time.Duration
+	TLS        time.Duration
+	ReusedConn bool
+	// TTFB is the time from sending the request to the first response byte.
+	TTFB time.Duration
+	// Total includes reading the response body.
+	Total time.Duration
+}
+
+// requestTracer collects a RequestTiming through httptrace callbacks, which
+// may run on other goroutines.
+type requestTracer struct {
+	mu     sync.Mutex
+	start  time.Time
+	dns    time.Time
+	conn   time.Time
+	tls    time.Time
+	timing RequestTiming
+}
+
+func newRequestTracer(method, path, requestID string, attempt int) *requestTracer {
+	return &requestTracer{
+		start:  time.Now(),
+		timing: RequestTiming{Method: method, Path: path, RequestID: requestID, Attempt: attempt},
+	}
+}
+
+func (t *requestTracer) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		GetConn: func(string) {
+			t.mu.Lock()
+			t.start = time.Now()
+			t.mu.Unlock()
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			t.timing.ReusedConn = info.Reused
+			t.mu.Unlock()
+		},
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.mu.Lock()
+			t.dns = time.Now()
+			t.mu.Unlock()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.mu.Lock()
+			t.timing.DNS = time.Since(t.dns)
+			t.mu.Unlock()
+		},
+		ConnectStart: func(string, string) {
+			t.mu.Lock()
+			t.conn = time.Now()
+			t.mu.Unlock()
+		},
+		ConnectDone: func(string, string, error) {
+			t.mu.Lock()
+			t.timing.Connect = time.Since(t.conn)
+			t.mu.Unlock()
+		},
+		TLSHandshakeStart: func() {
+			t.mu.Lock()
+			t.tls = time.Now()
+			t.mu.Unlock()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.mu.Lock()
+			t.timing.TLS = time.Since(t.tls)
+			t.mu.Unlock()
+		},
+		GotFirstResponseByte: func() {
+			t.mu.Lock()
+			t.timing.TTFB = time.Since(t.start)
+			t.mu.Unlock()
+		},
+	}
+}
+
+// done returns the collected timing for a finished attempt.
+func (t *requestTracer) done(status int) RequestTiming {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.timing.Status = status
+	t.timing.Total = time.Since(t.start)
+	return t.timing
+}
+
+// recordTiming logs a request timing and reports it to the metrics recorder.
+func (c *Client) recordTiming(timing RequestTiming) {
+	if timing.Status > 0 {
+		c.latency.observe(timing.Total)
+	}
+	c.logger.Debug("Request timing", map[string]any{
+		"method":      timing.Method,
+		"path":        timing.Path,
+		"request_id":  timing.RequestID,
+		"attempt":     timing.Attempt,
+		"status":      timing.Status,
+		"dns":         timing.DNS,
+		"connect":     timing.Connect,
+		"tls":         timing.TLS,
+		"ttfb":        timing.TTFB,
+		"total":       timing.Total,
+		"reused_conn": timing.ReusedConn,
+	})
+	c.metrics.RecordRequestTiming(timing)
+}
+
+// latencyEstimate is a moving average of how long requests take, shared by
+// clients derived with With.
+type latencyEstimate struct {
+	mu  sync.Mutex
+	avg time.Duration
+}
+
+func (l *latencyEstimate) observe(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.avg == 0 {
+		l.avg = d
+		return
+	}
+	l.avg = (4*l.avg + d) / 5
+}
+
+// typical returns the average request duration, or zero before any
+// request has completed.
+func (l *latencyEstimate) typical() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.avg
+}
@@ -0,0 +1,96 @@
+package refyne
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+)
+
+// WithHostOverride connects to addr whenever the client dials host, like an
+// /etc/hosts entry scoped to this client. addr may be an IP or a host, with
+// or without a port; without one the original port is kept. TLS still
+// verifies the certificate against host. Overrides apply to the default
+// transport and are ignored if WithHTTPClient supplies its own Transport.
+func WithHostOverride(host, addr string) ClientOption {
+	return func(c *Client) {
+		overrides := make(map[string]string, len(c.hostOverrides)+1)
+		for k, v := range c.hostOverrides {
+			overrides[k] = v
+		}
+		overrides[host] = addr
+		c.hostOverrides = overrides
+		c.redial = true
+	}
+}
+
+// WithResolver sets the DNS resolver used by the default transport, for
+// split-horizon environments where the API gateway is only resolvable by an
+// internal DNS server. It is ignored if WithHTTPClient supplies its own
+// Transport.
+func WithResolver(resolver *net.Resolver) ClientOption {
+	return func(c *Client) {
+		c.resolver = resolver
+		c.redial = true
+	}
+}
+
+// WithUnixSocket sends every request over the Unix domain socket at path,
+// e.g. to a local sidecar proxy. The base URL still supplies the scheme,
+// Host header and path prefix. It is ignored if WithHTTPClient supplies its
+// own Transport.
+func WithUnixSocket(path string) ClientOption {
+	return func(c *Client) {
+		c.unixSocket = path
+		c.redial = true
+	}
+}
+
+// configureTransport installs a dialing transport on the HTTP client when
+// host overrides, a resolver or a Unix socket are configured and the client
+// has no Transport of its own. It only acts when those options or the HTTP
+// client changed, so derived clients otherwise share their parent's
+// transport and connection pool.
+func (c *Client) configureTransport() {
+	if !c.redial {
+		return
+	}
+	c.redial = false
+	if len(c.hostOverrides) == 0 && c.resolver == nil && c.unixSocket == "" {
+		return
+	}
+	if c.httpClient.Transport != nil && !c.ownTransport {
+		return
+	}
+	hc := *c.httpClient
+	hc.Transport = c.dialTransport()
+	c.httpClient = &hc
+	c.ownTransport = true
+}
+
+// dialTransport returns a clone of http.DefaultTransport whose dialer
+// applies the Unix socket, host overrides and resolver.
+func (c *Client) dialTransport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Resolver:  c.resolver,
+	}
+	overrides, socket := c.hostOverrides, c.unixSocket
+	t.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if socket != "" {
+			return dialer.DialContext(ctx, "unix", socket)
+		}
+		if host, port, err := net.SplitHostPort(addr); err == nil {
+			if target, ok := overrides[host]; ok {
+				addr = target
+				if _, _, err := net.SplitHostPort(target); err != nil {
+					addr = net.JoinHostPort(target, port)
+				}
+			}
+		}
+		return dialer.DialContext(ctx, network, addr)
+	}
+	return t
+}
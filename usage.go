@@ -0,0 +1,173 @@
+package refyne
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// UsageTotals is aggregated token usage and cost.
+type UsageTotals struct {
+	// Requests is the number of extractions or jobs added.
+	Requests int64
+	// ByokRequests counts those run with your own LLM provider keys.
+	ByokRequests int64
+	InputTokens  int64
+	OutputTokens int64
+	// CostUsd is the USD charged by Refyne.
+	CostUsd float64
+	// LlmCostUsd is the USD cost reported by the LLM providers.
+	LlmCostUsd float64
+}
+
+// TotalTokens returns the sum of input and output tokens.
+func (t UsageTotals) TotalTokens() int64 {
+	return t.InputTokens + t.OutputTokens
+}
+
+func (t *UsageTotals) add(u UsageTotals) {
+	t.Requests += u.Requests
+	t.ByokRequests += u.ByokRequests
+	t.InputTokens += u.InputTokens
+	t.OutputTokens += u.OutputTokens
+	t.CostUsd += u.CostUsd
+	t.LlmCostUsd += u.LlmCostUsd
+}
+
+// UsageAccumulator aggregates the usage of extractions and jobs, overall
+// and per model, for billing reports. The zero value is ready to use and it
+// is safe for concurrent use.
+type UsageAccumulator struct {
+	mu      sync.Mutex
+	total   UsageTotals
+	byModel map[string]*UsageTotals
+}
+
+// Add records usage for model, which is conventionally "provider/model".
+func (a *UsageAccumulator) Add(model string, usage UsageResponse) {
+	totals := UsageTotals{
+		Requests:     1,
+		InputTokens:  usage.InputTokens,
+		OutputTokens: usage.OutputTokens,
+		CostUsd:      usage.CostUsd,
+		LlmCostUsd:   usage.LlmCostUsd,
+	}
+	if usage.IsByok {
+		totals.ByokRequests = 1
+	}
+	a.add(model, totals)
+}
+
+// AddExtract records the usage of an Extract result under its provider and
+// model.
+func (a *UsageAccumulator) AddExtract(out *ExtractOutput) {
+	a.Add(modelKey(out.Metadata.Provider, out.Metadata.Model), out.Usage)
+}
+
+// AddJob records the usage of a job. Jobs do not report which model they
+// used, so they are counted under the model "".
+func (a *UsageAccumulator) AddJob(job *Job) {
+	a.add("", UsageTotals{
+		Requests:     1,
+		InputTokens:  job.TokenUsageInput,
+		OutputTokens: job.TokenUsageOutput,
+		CostUsd:      job.CostUsd,
+	})
+}
+
+func (a *UsageAccumulator) add(model string, totals UsageTotals) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.total.add(totals)
+	if a.byModel == nil {
+		a.byModel = make(map[string]*UsageTotals)
+	}
+	m, ok := a.byModel[model]
+	if !ok {
+		m = &UsageTotals{}
+		a.byModel[model] = m
+	}
+	m.add(totals)
+}
+
+// Total returns the usage accumulated so far.
+func (a *UsageAccumulator) Total() UsageTotals {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.total
+}
+
+// ByModel returns the usage accumulated so far for each model.
+func (a *UsageAccumulator) ByModel() map[string]UsageTotals {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	out := make(map[string]UsageTotals, len(a.byModel))
+	for model, totals := range a.byModel {
+		out[model] = *totals
+	}
+	return out
+}
+
+// Models returns the models with recorded usage, most expensive first.
+func (a *UsageAccumulator) Models() []string {
+	byModel := a.ByModel()
+	models := make([]string, 0, len(byModel))
+	for model := range byModel {
+		models = append(models, model)
+	}
+	sort.Slice(models, func(i, j int) bool {
+		ci, cj := byModel[models[i]].CostUsd, byModel[models[j]].CostUsd
+		if ci != cj {
+			return ci > cj
+		}
+		return models[i] < models[j]
+	})
+	return models
+}
+
+// Reset clears the accumulated usage.
+func (a *UsageAccumulator) Reset() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.total = UsageTotals{}
+	a.byModel = nil
+}
+
+// modelKey returns "provider/model", or whichever of the two is set.
+func modelKey(provider, model string) string {
+	switch {
+	case provider == "":
+		return model
+	case model == "":
+		return provider
+	}
+	return provider + "/" + model
+}
+
+// FormatUSD formats a USD amount with a dollar sign and thousands
+// separators, e.g. "$1,234.50". Amounts below one cent keep four decimal
+// places so per-request costs do not round to zero.
+func FormatUSD(usd float64) string {
+	sign := ""
+	if usd < 0 {
+		sign, usd = "-", -usd
+	}
+	decimals := 2
+	if usd > 0 && usd < 0.01 {
+		decimals = 4
+	}
+	s := strconv.FormatFloat(usd, 'f', decimals, 64)
+	whole, frac, _ := strings.Cut(s, ".")
+	if len(whole) > 3 {
+		var b strings.Builder
+		for i, r := range whole {
+			if i > 0 && (len(whole)-i)%3 == 0 {
+				b.WriteByte(',')
+			}
+			b.WriteRune(r)
+		}
+		whole = b.String()
+	}
+	return sign + "$" + whole + "." + frac
+}
@@ -0,0 +1,61 @@
+package refyne
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestUsageAccumulator(t *testing.T) {
+	var acc UsageAccumulator
+	extract := &ExtractOutput{}
+	extract.Metadata.Provider, extract.Metadata.Model = "openai", "gpt-4o-mini"
+	extract.Usage = UsageResponse{InputTokens: 1000, OutputTokens: 200, CostUsd: 0.002, LlmCostUsd: 0.001}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			acc.AddExtract(extract)
+		}()
+	}
+	wg.Wait()
+	acc.Add("anthropic/claude-sonnet", UsageResponse{InputTokens: 500, OutputTokens: 100, IsByok: true, LlmCostUsd: 0.05})
+	acc.AddJob(&Job{JobResponse: JobResponse{TokenUsageInput: 10, TokenUsageOutput: 5, CostUsd: 1.5}})
+
+	total := acc.Total()
+	if total.Requests != 12 || total.ByokRequests != 1 || total.TotalTokens() != 12615 {
+		t.Errorf("unexpected totals: %+v", total)
+	}
+	if got := FormatUSD(total.CostUsd); got != "$1.52" {
+		t.Errorf("expected $1.52, got %s", got)
+	}
+
+	byModel := acc.ByModel()
+	if m := byModel["openai/gpt-4o-mini"]; m.Requests != 10 || m.InputTokens != 10000 {
+		t.Errorf("unexpected openai usage: %+v", m)
+	}
+	if models := acc.Models(); len(models) != 3 || models[0] != "" || models[1] != "openai/gpt-4o-mini" {
+		t.Errorf("expected models by cost, got %q", models)
+	}
+
+	acc.Reset()
+	if acc.Total() != (UsageTotals{}) || len(acc.ByModel()) != 0 {
+		t.Error("expected Reset to clear usage")
+	}
+}
+
+func TestFormatUSD(t *testing.T) {
+	tests := map[float64]string{
+		0:          "$0.00",
+		0.0042:     "$0.0042",
+		12.5:       "$12.50",
+		1234.567:   "$1,234.57",
+		-2500000.1: "-$2,500,000.10",
+	}
+	for usd, want := range tests {
+		if got := FormatUSD(usd); got != want {
+			t.Errorf("FormatUSD(%v) = %q, want %q", usd, got, want)
+		}
+	}
+}
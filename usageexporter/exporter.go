@@ -0,0 +1,175 @@
+// Package usageexporter publishes Refyne account usage as Prometheus metrics.
+//
+// The exporter periodically calls GetUsage and Jobs.Stats and serves the
+// latest values in the Prometheus text exposition format, so it can be
+// mounted on an existing metrics endpoint without a Prometheus client
+// library:
+//
+//	exporter := usageexporter.New(client, usageexporter.WithInterval(time.Minute))
+//	go exporter.Run(ctx)
+//	http.Handle("/metrics/refyne", exporter)
+//
+// Usage for the current billing period is published as the gauges
+// refyne_period_jobs, refyne_period_byok_jobs and refyne_period_spend_usd,
+// which reset when the period does, and jobs by status as refyne_jobs.
+// Credits used and remaining are not published: neither GetUsage nor any
+// other API endpoint reports them yet, only the plan's monthly allocation.
+package usageexporter
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	refyne "github.com/jmylchreest/refyne-sdk-go"
+)
+
+// DefaultInterval is how often usage is refreshed by Run.
+const DefaultInterval = 5 * time.Minute
+
+// Exporter collects account usage and serves it as Prometheus metrics.
+type Exporter struct {
+	client   *refyne.Client
+	interval time.Duration
+	now      func() time.Time
+
+	mu          sync.RWMutex
+	usage       *refyne.GetUsageOutputBody
+	stats       *refyne.JobStats
+	lastSuccess time.Time
+	lastErr     error
+}
+
+// Option configures an Exporter.
+type Option func(*Exporter)
+
+// WithInterval sets how often Run refreshes usage. Zero or negative
+// durations are ignored, keeping DefaultInterval.
+func WithInterval(d time.Duration) Option {
+	return func(e *Exporter) {
+		if d > 0 {
+			e.interval = d
+		}
+	}
+}
+
+// New creates an Exporter for client.
+func New(client *refyne.Client, opts ...Option) *Exporter {
+	e := &Exporter{
+		client:   client,
+		interval: DefaultInterval,
+		now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Run refreshes usage immediately and then every interval until ctx is
+// cancelled. Refresh errors are kept and reported through the
+// refyne_exporter_up metric rather than stopping the loop.
+func (e *Exporter) Run(ctx context.Context) error {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		_ = e.Refresh(ctx)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh fetches the latest usage and job statistics.
+func (e *Exporter) Refresh(ctx context.Context) error {
+	usage, err := e.client.GetUsage(ctx)
+	if err == nil {
+		var stats *refyne.JobStats
+		if stats, err = e.client.Jobs.Stats(ctx); err == nil {
+			e.mu.Lock()
+			e.usage, e.stats, e.lastSuccess, e.lastErr = usage, stats, e.now(), nil
+			e.mu.Unlock()
+			return nil
+		}
+	}
+
+	e.mu.Lock()
+	e.lastErr = err
+	e.mu.Unlock()
+	return err
+}
+
+// ServeHTTP writes the latest metrics in the Prometheus text format.
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = e.WriteMetrics(w)
+}
+
+// WriteMetrics writes the latest metrics in the Prometheus text format.
+func (e *Exporter) WriteMetrics(w io.Writer) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	mw := &metricWriter{w: w}
+	up := 0.0
+	if e.lastErr == nil && !e.lastSuccess.IsZero() {
+		up = 1
+	}
+	mw.gauge("refyne_exporter_up", "Whether the last refresh of Refyne usage succeeded.", up)
+	if !e.lastSuccess.IsZero() {
+		mw.gauge("refyne_exporter_last_success_timestamp_seconds", "Unix time of the last successful refresh.", float64(e.lastSuccess.Unix()))
+	}
+
+	if e.usage != nil {
+		mw.gauge("refyne_period_jobs", "Jobs run in the current billing period.", float64(e.usage.TotalJobs))
+		mw.gauge("refyne_period_byok_jobs", "Jobs run with your own LLM provider keys in the current billing period.", float64(e.usage.ByokJobs))
+		mw.gauge("refyne_period_spend_usd", "USD charged in the current billing period.", e.usage.TotalChargedUsd)
+	}
+
+	if e.stats != nil {
+		statuses := make([]string, 0, len(e.stats.ByStatus))
+		for status := range e.stats.ByStatus {
+			statuses = append(statuses, status)
+		}
+		sort.Strings(statuses)
+
+		mw.header("refyne_jobs", "Jobs by status.", "gauge")
+		for _, status := range statuses {
+			mw.sample(fmt.Sprintf("refyne_jobs{status=%q}", status), float64(e.stats.ByStatus[status]))
+		}
+	}
+
+	return mw.err
+}
+
+// metricWriter writes Prometheus text exposition lines, keeping the first error.
+type metricWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (m *metricWriter) header(name, help, kind string) {
+	m.printf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func (m *metricWriter) sample(series string, value float64) {
+	m.printf("%s %g\n", series, value)
+}
+
+func (m *metricWriter) gauge(name, help string, value float64) {
+	m.header(name, help, "gauge")
+	m.sample(name, value)
+}
+
+func (m *metricWriter) printf(format string, args ...any) {
+	if m.err == nil {
+		_, m.err = fmt.Fprintf(m.w, format, args...)
+	}
+}
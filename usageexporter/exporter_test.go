@@ -0,0 +1,96 @@
+package usageexporter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	refyne "github.com/jmylchreest/refyne-sdk-go"
+)
+
+func TestExporter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/usage":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"total_jobs":        12,
+				"byok_jobs":         2,
+				"total_charged_usd": 1.5,
+			})
+		case "/api/v1/jobs/stats":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"total":     12,
+				"by_status": map[string]int{"running": 1, "completed": 11},
+			})
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	exporter := New(refyne.NewClient("test-key", refyne.WithBaseURL(server.URL)))
+	if err := exporter.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	exporter.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+
+	for _, want := range []string{
+		"refyne_exporter_up 1\n",
+		"refyne_period_jobs 12\n",
+		"refyne_period_byok_jobs 2\n",
+		"refyne_period_spend_usd 1.5\n",
+		"refyne_jobs{status=\"completed\"} 11\n",
+		"refyne_jobs{status=\"running\"} 1\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected metrics to contain %q, got:\n%s", want, body)
+		}
+	}
+}
+
+func TestExporterDown(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	exporter := New(refyne.NewClient("test-key", refyne.WithBaseURL(server.URL)))
+	if err := exporter.Refresh(context.Background()); err == nil {
+		t.Fatal("expected Refresh to fail")
+	}
+
+	var sb strings.Builder
+	if err := exporter.WriteMetrics(&sb); err != nil {
+		t.Fatalf("WriteMetrics failed: %v", err)
+	}
+	if !strings.Contains(sb.String(), "refyne_exporter_up 0\n") {
+		t.Errorf("expected exporter to report down, got:\n%s", sb.String())
+	}
+}
+
+func TestWithIntervalZero(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := refyne.NewClient("test-key", refyne.WithBaseURL(server.URL))
+	for _, d := range []time.Duration{0, -time.Second} {
+		exporter := New(client, WithInterval(d))
+		if exporter.interval != DefaultInterval {
+			t.Errorf("expected WithInterval(%v) to keep the default, got %v", d, exporter.interval)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		if err := exporter.Run(ctx); err != context.DeadlineExceeded {
+			t.Errorf("expected Run to stop with the context, got %v", err)
+		}
+		cancel()
+	}
+}
@@ -0,0 +1,77 @@
+package refyne
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// varyNames returns the canonical names of the request headers listed in
+// h's Vary header, and whether it lists "*", meaning the response varies
+// on more than the request headers.
+func varyNames(h http.Header) (names []string, all bool) {
+	for _, value := range h.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			switch {
+			case name == "":
+			case name == "*":
+				return nil, true
+			default:
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	return names, false
+}
+
+// varyRequestHeader returns the values in req of the headers named. The
+// credentials are already part of the cache key, so Authorization is left
+// out and tokens are never written to the cache.
+func varyRequestHeader(names []string, req http.Header) http.Header {
+	if len(names) == 0 {
+		return nil
+	}
+	h := make(http.Header, len(names))
+	for _, name := range names {
+		if name == "Authorization" {
+			continue
+		}
+		h[name] = append([]string{}, req.Values(name)...)
+	}
+	return h
+}
+
+// varyMatches reports whether entry may be served for a request with
+// headers req: its response either has no Vary header or was stored for a
+// request with the same values of the headers it names.
+func varyMatches(entry *CacheEntry, req http.Header) bool {
+	names, all := varyNames(entry.Header)
+	if all {
+		return false
+	}
+	for _, name := range names {
+		if name == "Authorization" {
+			continue
+		}
+		if strings.Join(entry.RequestHeader.Values(name), ",") != strings.Join(req.Values(name), ",") {
+			return false
+		}
+	}
+	return true
+}
+
+// cacheRequestHeader returns the headers, other than Authorization, that
+// newRequest would send for a request made with ctx, for matching against
+// the Vary header of cached responses.
+func (c *Client) cacheRequestHeader(ctx context.Context) http.Header {
+	req := &http.Request{Header: http.Header{}}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", c.accept(ctx))
+	req.Header.Set("User-Agent", c.userAgent)
+	if c.region != "" {
+		req.Header.Set(RegionHeader, string(c.region))
+	}
+	applyContextHeaders(ctx, req)
+	return req.Header
+}
@@ -0,0 +1,173 @@
+package refyne
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultWaitPoll is how long each long poll made by Wait is held open.
+const DefaultWaitPoll = 30 * time.Second
+
+// partialFetchTimeout bounds the request Wait makes for a job's state after
+// its context is done.
+const partialFetchTimeout = 10 * time.Second
+
+// WaitOptions controls Wait.
+type WaitOptions struct {
+	// Poll is how long each long poll is held open. Zero uses
+	// DefaultWaitPoll.
+	Poll time.Duration
+	// ReturnPartial makes Wait return the job's current state with Partial
+	// set, rather than an error, when ctx is cancelled or times out. The
+	// caller can then consume the results so far or cancel the job.
+	ReturnPartial bool
+}
+
+// WaitResult is the job returned by Wait.
+type WaitResult struct {
+	Job *Job
+	// Partial reports that waiting stopped before the job finished.
+	Partial bool
+}
+
+// Done reports whether the job has finished, successfully or not.
+func (j *Job) Done() bool {
+	return j.Status != "" && !unfinishedJobStatuses[j.Status]
+}
+
+// Wait long-polls a job until it finishes and returns it. A failed job is
+// returned without error; check its Status. If ctx is done first, Wait
+// returns ctx's error unless opts.ReturnPartial is set. Pages processed are
+// reported to a ProgressReporter passed with WithProgress.
+func (j *JobsClient) Wait(ctx context.Context, id string, opts *WaitOptions, reqOpts ...RequestOption) (result *WaitResult, err error) {
+	ctx = withRequestOptions(ctx, reqOpts)
+	progress := requestOptionsFromContext(ctx).progress
+	if progress != nil {
+		defer func() {
+			doneErr := err
+			if err == nil && result.Partial {
+				doneErr = ctx.Err()
+			}
+			progress.Done(doneErr)
+		}()
+	}
+	if opts == nil {
+		opts = &WaitOptions{}
+	}
+	poll := opts.Poll
+	if poll <= 0 {
+		poll = DefaultWaitPoll
+	}
+
+	var last *Job
+	stopped := func(err error) (*WaitResult, error) {
+		if ctx.Err() == nil || !opts.ReturnPartial {
+			return nil, err
+		}
+		return j.partial(ctx, id, last, err)
+	}
+	for {
+		start := time.Now()
+		job, err := j.GetWait(ctx, id, poll)
+		if err != nil {
+			return stopped(err)
+		}
+		if progress != nil {
+			progress.Progress(Progress{Current: job.PageCount, Unit: ProgressPages, Status: job.Status})
+		}
+		if job.Done() {
+			return &WaitResult{Job: job}, nil
+		}
+		last = job
+		// Keep polls at least a second apart should the server answer
+		// without waiting.
+		if d := time.Second - time.Since(start); d > 0 {
+			if err := j.client.sleepWithContext(ctx, d); err != nil {
+				return stopped(&NetworkError{Err: err})
+			}
+		}
+	}
+}
+
+// partial returns the job's state once ctx is done, fetched afresh if
+// possible and otherwise the last state seen, or err if there is none.
+func (j *JobsClient) partial(ctx context.Context, id string, last *Job, err error) (*WaitResult, error) {
+	fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), partialFetchTimeout)
+	defer cancel()
+	if job, fetchErr := j.Get(fetchCtx, id, WithoutCache()); fetchErr == nil {
+		last = job
+	}
+	if last == nil {
+		return nil, err
+	}
+	return &WaitResult{Job: last, Partial: !last.Done()}, nil
+}
+
+// CrawlAndWaitOptions controls CrawlAndWait.
+type CrawlAndWaitOptions struct {
+	Wait *WaitOptions
+	// State makes the call resumable. An Idempotency-Key is saved under
+	// StateKey before the crawl is submitted and the job ID once it is
+	// accepted, so calling CrawlAndWait again with the same key after a
+	// crash or error waits for the same job rather than starting another.
+	// The state is deleted once the job finishes.
+	State    StateStore
+	StateKey string
+}
+
+// crawlState is the progress of a CrawlAndWait saved to its StateStore.
+type crawlState struct {
+	IdempotencyKey string `json:"idempotency_key"`
+	JobID          string `json:"job_id,omitempty"`
+}
+
+// CrawlAndWait starts a crawl and waits for it to finish, like Crawl
+// followed by Jobs.Wait.
+func (c *Client) CrawlAndWait(ctx context.Context, input CrawlInput, opts *CrawlAndWaitOptions, reqOpts ...RequestOption) (*WaitResult, error) {
+	if opts == nil {
+		opts = &CrawlAndWaitOptions{}
+	}
+	var state crawlState
+	if opts.State != nil {
+		if opts.StateKey == "" {
+			return nil, errors.New("crawl: State requires a StateKey")
+		}
+		if _, err := loadState(opts.State, opts.StateKey, &state); err != nil {
+			return nil, fmt.Errorf("crawl: %w", err)
+		}
+	}
+
+	if state.JobID == "" {
+		crawlOpts := reqOpts
+		if opts.State != nil {
+			if state.IdempotencyKey == "" {
+				state.IdempotencyKey = newUUID()
+				if err := saveState(opts.State, opts.StateKey, state); err != nil {
+					return nil, fmt.Errorf("crawl: save state: %w", err)
+				}
+			}
+			crawlOpts = append(reqOpts[:len(reqOpts):len(reqOpts)], WithIdempotencyKey(state.IdempotencyKey))
+		}
+		job, err := c.Crawl(ctx, input, crawlOpts...)
+		if err != nil {
+			return nil, err
+		}
+		state.JobID = job.JobId
+		if opts.State != nil {
+			if err := saveState(opts.State, opts.StateKey, state); err != nil {
+				return nil, fmt.Errorf("crawl: save state for job %s: %w", state.JobID, err)
+			}
+		}
+	}
+
+	result, err := c.Jobs.Wait(ctx, state.JobID, opts.Wait, reqOpts...)
+	if err != nil || result.Partial || opts.State == nil {
+		return result, err
+	}
+	if err := opts.State.Delete(opts.StateKey); err != nil {
+		return result, fmt.Errorf("crawl: delete state: %w", err)
+	}
+	return result, nil
+}
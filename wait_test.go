@@ -0,0 +1,112 @@
+package refyne
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWait(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := "running"
+		switch {
+		case r.URL.Path == "/api/v1/jobs/done":
+			status = "completed"
+		case r.URL.Query().Get("wait") != "":
+			// Hold the long poll open until the client gives up.
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(2 * time.Second):
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "job-1", "status": status, "page_count": 5})
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL), WithMaxRetries(0))
+
+	result, err := client.Jobs.Wait(context.Background(), "done", nil)
+	if err != nil || result.Partial || !result.Job.Done() {
+		t.Fatalf("expected a finished job, got %+v, %v", result, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := client.Jobs.Wait(ctx, "job-1", &WaitOptions{Poll: time.Second}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a deadline error, got %v", err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	result, err = client.Jobs.Wait(ctx, "job-1", &WaitOptions{Poll: time.Second, ReturnPartial: true})
+	if err != nil {
+		t.Fatalf("expected partial state, got %v", err)
+	}
+	if !result.Partial || result.Job.Status != "running" || result.Job.PageCount != 5 {
+		t.Errorf("unexpected partial result: %+v", result)
+	}
+}
+
+func TestCrawlAndWaitResume(t *testing.T) {
+	var mu sync.Mutex
+	var submissions []string
+	var finished bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPost {
+			mu.Lock()
+			submissions = append(submissions, r.Header.Get(IdempotencyKeyHeader))
+			mu.Unlock()
+			_ = json.NewEncoder(w).Encode(map[string]any{"job_id": "job-1", "status": "pending"})
+			return
+		}
+		mu.Lock()
+		status := "running"
+		if finished {
+			status = "completed"
+		}
+		mu.Unlock()
+		if status == "running" {
+			<-r.Context().Done()
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "job-1", "status": status})
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", WithBaseURL(server.URL), WithMaxRetries(0))
+	store := NewMemoryStateStore()
+	opts := &CrawlAndWaitOptions{Wait: &WaitOptions{Poll: time.Second}, State: store, StateKey: "nightly"}
+
+	// The worker stops while the job runs.
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := client.CrawlAndWait(ctx, CrawlInput{URL: "https://example.com"}, opts); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a deadline error, got %v", err)
+	}
+	if _, saved, _ := store.Get("nightly"); !saved {
+		t.Fatal("expected the job to be saved")
+	}
+
+	// A restarted worker waits for the same job.
+	mu.Lock()
+	finished = true
+	mu.Unlock()
+	result, err := client.CrawlAndWait(context.Background(), CrawlInput{URL: "https://example.com"}, opts)
+	if err != nil || result.Job.Id != "job-1" || !result.Job.Done() {
+		t.Fatalf("expected the saved job to finish, got %+v, %v", result, err)
+	}
+	if len(submissions) != 1 || submissions[0] == "" {
+		t.Errorf("expected one submission with an Idempotency-Key, got %q", submissions)
+	}
+	if _, saved, _ := store.Get("nightly"); saved {
+		t.Error("expected the state to be deleted once the job finished")
+	}
+}
@@ -0,0 +1,152 @@
+package refyne
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// errStopWatch ends a watch once the job has finished.
+var errStopWatch = errors.New("stop watch")
+
+// Watch streams events for a job over server-sent events, calling fn for
+// each one. It returns nil once the job completes or fails, ctx's error if
+// ctx is cancelled, or the first error returned by fn. A stream that ends
+// before the job completes or fails returns a NetworkError wrapping
+// io.ErrUnexpectedEOF; the job may still be running, so callers can Watch
+// again or check it with Get. With
+// WithProviderWarnings, results from degraded providers are reported.
+func (j *JobsClient) Watch(ctx context.Context, id string, fn func(Event) error, reqOpts ...RequestOption) error {
+	ctx = withCallRequestID(withRequestOptions(ctx, reqOpts))
+	requestID, _ := RequestIDFromContext(ctx)
+	if err := j.client.requireFeature(ctx, FeatureSSE); err != nil {
+		return err
+	}
+	streamCtx := withRequestOptions(ctx, []RequestOption{withAccept("text/event-stream")})
+	req, err := j.client.newRequest(streamCtx, http.MethodGet, "/api/v1/jobs/"+id+"/stream", nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := j.client.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return &NetworkError{Err: ctx.Err(), RequestID: requestID}
+		}
+		return &NetworkError{Err: err, RequestID: requestID}
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(resp.Body)
+		return j.client.responseError(resp, body)
+	}
+
+	var providers *providerWatcher
+	if j.client.providerWarn != nil {
+		providers = &providerWatcher{client: j.client, jobID: id, warned: map[string]bool{}}
+	}
+	err = readServerSentEvents(resp.Body, func(name string, data []byte) error {
+		event, err := jobStreamEvent(id, name, data)
+		if err != nil || event == nil {
+			return err
+		}
+		if result, ok := event.(*JobResultEvent); ok && providers != nil {
+			providers.check(ctx, result)
+		}
+		if err := fn(event); err != nil {
+			return err
+		}
+		if event.EventType() == EventJobCompleted || event.EventType() == EventJobFailed {
+			return errStopWatch
+		}
+		return nil
+	})
+	switch {
+	case errors.Is(err, errStopWatch):
+		return nil
+	case ctx.Err() != nil:
+		return &NetworkError{Err: ctx.Err(), RequestID: requestID}
+	case err != nil:
+		return err
+	default:
+		return &NetworkError{Err: io.ErrUnexpectedEOF, RequestID: requestID}
+	}
+}
+
+// jobStreamEvent converts a server-sent event from the job stream into an
+// Event. Unrecognised event names are skipped.
+func jobStreamEvent(jobID, name string, data []byte) (Event, error) {
+	meta := EventMeta{JobID: jobID}
+	switch name {
+	case "status":
+		event := &JobStatusEvent{}
+		if err := json.Unmarshal(data, &event.Data); err != nil {
+			return nil, fmt.Errorf("failed to parse status event: %w", err)
+		}
+		meta.Type = EventJobProgress
+		event.EventMeta = meta
+		return event, nil
+	case "result":
+		event := &JobResultEvent{}
+		if err := json.Unmarshal(data, &event.Data); err != nil {
+			return nil, fmt.Errorf("failed to parse result event: %w", err)
+		}
+		meta.Type = EventJobResult
+		event.EventMeta = meta
+		return event, nil
+	case "complete":
+		event := &JobCompletedEvent{}
+		if err := json.Unmarshal(data, &event.Data); err != nil {
+			return nil, fmt.Errorf("failed to parse complete event: %w", err)
+		}
+		meta.Type = EventJobCompleted
+		if event.Data.Status == "failed" {
+			meta.Type = EventJobFailed
+		}
+		event.EventMeta = meta
+		return event, nil
+	case "error":
+		var event SSEErrorEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			return nil, fmt.Errorf("failed to parse error event: %w", err)
+		}
+		return nil, &APIError{Message: event.Message}
+	default:
+		return nil, nil
+	}
+}
+
+// readServerSentEvents reads a text/event-stream body, calling fn with the
+// name and data of each event until the stream ends or fn returns an error.
+func readServerSentEvents(r io.Reader, fn func(name string, data []byte) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+
+	var name string
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if len(data) > 0 {
+				if err := fn(name, []byte(strings.Join(data, "\n"))); err != nil {
+					return err
+				}
+			}
+			name, data = "", nil
+		case strings.HasPrefix(line, ":"):
+			// Comment or keep-alive.
+		case strings.HasPrefix(line, "event:"):
+			name = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,40 @@
+package refyne
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// WebhookSignatureHeader is the header carrying the HMAC-SHA256 signature of
+// a webhook payload.
+const WebhookSignatureHeader = "X-Refyne-Signature"
+
+// ErrInvalidWebhookSignature is returned when a webhook signature does not
+// match any of the given secrets.
+var ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+
+// VerifyWebhookSignature checks that signature is the HMAC-SHA256 of payload
+// under one of secrets. The signature may be hex encoded with or without a
+// "sha256=" prefix. Passing both the current and previous secret allows
+// receivers to keep accepting deliveries while a secret is being rotated.
+func VerifyWebhookSignature(payload []byte, signature string, secrets ...string) error {
+	sig, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(signature), "sha256="))
+	if err != nil || len(sig) == 0 {
+		return ErrInvalidWebhookSignature
+	}
+
+	for _, secret := range secrets {
+		if secret == "" {
+			continue
+		}
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(payload)
+		if hmac.Equal(sig, mac.Sum(nil)) {
+			return nil
+		}
+	}
+	return ErrInvalidWebhookSignature
+}
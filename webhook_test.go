@@ -0,0 +1,77 @@
+package refyne
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func sign(payload []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	payload := []byte(`{"event":"job.completed"}`)
+
+	tests := []struct {
+		name      string
+		signature string
+		secrets   []string
+		wantErr   bool
+	}{
+		{"current secret", sign(payload, "new"), []string{"new", "old"}, false},
+		{"previous secret", sign(payload, "old"), []string{"new", "old"}, false},
+		{"no prefix", sign(payload, "new")[len("sha256="):], []string{"new"}, false},
+		{"unknown secret", sign(payload, "other"), []string{"new", "old"}, true},
+		{"malformed", "sha256=zz", []string{"new"}, true},
+		{"no secrets", sign(payload, "new"), nil, true},
+	}
+
+	for _, tt := range tests {
+		err := VerifyWebhookSignature(payload, tt.signature, tt.secrets...)
+		if tt.wantErr && !errors.Is(err, ErrInvalidWebhookSignature) {
+			t.Errorf("%s: expected ErrInvalidWebhookSignature, got %v", tt.name, err)
+		}
+		if !tt.wantErr && err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		}
+	}
+}
+
+func TestParseEvent(t *testing.T) {
+	event, err := ParseEvent([]byte(`{"event":"job.completed","job_id":"job-1","data":{"job_id":"job-1","status":"completed","page_count":3}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	completed, ok := event.(*JobCompletedEvent)
+	if !ok {
+		t.Fatalf("expected *JobCompletedEvent, got %T", event)
+	}
+	if completed.JobID != "job-1" || completed.Data.PageCount != 3 {
+		t.Errorf("unexpected event contents: %+v", completed)
+	}
+
+	payload := []byte(`{"event":"job.archived","job_id":"job-1"}`)
+	event, err = ParseEvent(payload)
+	if err != nil {
+		t.Fatalf("unexpected error for unknown event: %v", err)
+	}
+	unknown, ok := event.(*UnknownEvent)
+	if !ok {
+		t.Fatalf("expected *UnknownEvent, got %T", event)
+	}
+	if unknown.Type != "job.archived" || string(unknown.Raw) != string(payload) {
+		t.Errorf("unexpected unknown event: %+v", unknown)
+	}
+	if unknown.Type.Valid() {
+		t.Error("expected job.archived to be reported as not valid")
+	}
+
+	if _, err := ParseEvent([]byte(`not json`)); err == nil {
+		t.Error("expected error for malformed payload")
+	}
+}
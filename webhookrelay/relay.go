@@ -0,0 +1,177 @@
+// Package webhookrelay delivers a webhook's events to a local Go channel,
+// so webhook-driven code can be developed on a laptop without exposing a
+// public URL.
+//
+// The API has no relay endpoint, so the package polls the webhook's
+// delivery log. The log records which event was delivered for which job
+// but not the payload; fetch the job with Jobs.Get, or stream its full
+// events with Jobs.Watch, when more is needed:
+//
+//	l := webhookrelay.Listen(ctx, client, webhookID, nil)
+//	for event := range l.C {
+//	    fmt.Println(event.Type, event.JobID)
+//	}
+//	if err := l.Err(); err != nil && !errors.Is(err, context.Canceled) {
+//	    log.Fatal(err)
+//	}
+package webhookrelay
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	refyne "github.com/jmylchreest/refyne-sdk-go"
+)
+
+// DefaultPoll is how often the delivery log is polled by default.
+const DefaultPoll = 5 * time.Second
+
+// pageSize is the number of deliveries fetched per request.
+const pageSize = 100
+
+// Event is a webhook event found in the delivery log.
+type Event struct {
+	Type  refyne.EventType
+	JobID string
+	// At is when the delivery was created.
+	At time.Time
+	// Delivery is the log entry the event was found in.
+	Delivery refyne.WebhookDeliveryResponse
+}
+
+// Options configures Listen.
+type Options struct {
+	// Poll is the interval between polls. Zero uses DefaultPoll.
+	Poll time.Duration
+	// Since is the time of the oldest event to relay. Zero starts from the
+	// time Listen is called.
+	Since time.Time
+	// EventType only relays events of this type.
+	EventType refyne.EventType
+}
+
+// Listener relays the events of a webhook.
+type Listener struct {
+	// C receives each event once, oldest first. It is closed when the
+	// context is done or polling fails.
+	C <-chan Event
+
+	done chan struct{}
+	err  error
+}
+
+// Listen polls the delivery log of webhook id until ctx is done and sends
+// its events on the returned Listener's channel. Each delivery is relayed
+// once, however often it is retried.
+func Listen(ctx context.Context, client *refyne.Client, id string, opts *Options) *Listener {
+	if opts == nil {
+		opts = &Options{}
+	}
+	poll := opts.Poll
+	if poll <= 0 {
+		poll = DefaultPoll
+	}
+	since := opts.Since
+	if since.IsZero() {
+		// The log has one-second resolution.
+		since = time.Now().Truncate(time.Second)
+	}
+
+	ch := make(chan Event)
+	l := &Listener{C: ch, done: make(chan struct{})}
+	p := &poller{client: client, id: id, eventType: opts.EventType, since: since, seen: map[string]time.Time{}}
+	go func() {
+		defer close(l.done)
+		defer close(ch)
+		l.err = p.run(ctx, poll, ch)
+	}()
+	return l
+}
+
+// Err returns why the listener stopped: ctx's error or the error that
+// ended polling. It returns nil while the listener is running.
+func (l *Listener) Err() error {
+	select {
+	case <-l.done:
+		return l.err
+	default:
+		return nil
+	}
+}
+
+// poller tracks which deliveries have been relayed.
+type poller struct {
+	client    *refyne.Client
+	id        string
+	eventType refyne.EventType
+	since     time.Time
+	// seen holds the IDs of the deliveries relayed at or after since.
+	seen map[string]time.Time
+}
+
+func (p *poller) run(ctx context.Context, poll time.Duration, ch chan<- Event) error {
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+	for {
+		events, err := p.poll(ctx)
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			select {
+			case ch <- event:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// poll returns the events delivered since the last poll, oldest first.
+func (p *poller) poll(ctx context.Context) ([]Event, error) {
+	var events []Event
+	for offset := 0; ; offset += pageSize {
+		out, err := p.client.Webhooks.ListDeliveries(ctx, p.id, &refyne.ListDeliveriesOptions{
+			Limit:     pageSize,
+			Offset:    offset,
+			EventType: string(p.eventType),
+			Since:     p.since,
+		}, refyne.WithoutCache())
+		if err != nil {
+			return nil, err
+		}
+		if out.Deliveries == nil {
+			break
+		}
+		for _, d := range *out.Deliveries {
+			at, _ := time.Parse(time.RFC3339, d.CreatedAt)
+			if _, ok := p.seen[d.Id]; ok || at.Before(p.since) {
+				continue
+			}
+			p.seen[d.Id] = at
+			events = append(events, Event{Type: refyne.EventType(d.EventType), JobID: d.JobId, At: at, Delivery: d})
+		}
+		if len(*out.Deliveries) < pageSize {
+			break
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].At.Before(events[j].At) })
+
+	// Later polls only list deliveries from the newest one on, so older
+	// IDs can be forgotten.
+	if n := len(events); n > 0 && events[n-1].At.After(p.since) {
+		p.since = events[n-1].At
+		for id, at := range p.seen {
+			if at.Before(p.since) {
+				delete(p.seen, id)
+			}
+		}
+	}
+	return events, nil
+}
@@ -0,0 +1,68 @@
+package webhookrelay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	refyne "github.com/jmylchreest/refyne-sdk-go"
+)
+
+func TestListen(t *testing.T) {
+	var polls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/webhooks/wh-1/deliveries" {
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+		deliveries := []map[string]any{
+			{"id": "d-1", "event_type": "job.started", "job_id": "job-1", "created_at": "2026-01-01T10:00:00Z", "status": "failed", "attempt_number": polls.Load() + 1},
+		}
+		if polls.Add(1) > 1 {
+			deliveries = append(deliveries, map[string]any{"id": "d-2", "event_type": "job.completed", "job_id": "job-1", "created_at": "2026-01-01T10:01:00Z", "status": "success"})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"deliveries": deliveries})
+	}))
+	defer server.Close()
+
+	client := refyne.NewClient("test-key", refyne.WithBaseURL(server.URL))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	l := Listen(ctx, client, "wh-1", &Options{Poll: 10 * time.Millisecond, Since: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)})
+
+	var got []Event
+	for event := range l.C {
+		got = append(got, event)
+		if len(got) == 2 {
+			cancel()
+		}
+	}
+	if len(got) != 2 || got[0].Type != refyne.EventJobStarted || got[1].Type != refyne.EventJobCompleted || got[1].JobID != "job-1" {
+		t.Errorf("expected each delivery once in order, got %+v", got)
+	}
+	if err := l.Err(); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestListenError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":"webhook not found"}`))
+	}))
+	defer server.Close()
+
+	client := refyne.NewClient("test-key", refyne.WithBaseURL(server.URL))
+	l := Listen(context.Background(), client, "missing", nil)
+	for range l.C {
+		t.Error("expected no events")
+	}
+	var notFound *refyne.NotFoundError
+	if !errors.As(l.Err(), &notFound) {
+		t.Errorf("expected NotFoundError, got %v", l.Err())
+	}
+}
@@ -0,0 +1,105 @@
+package refyne
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+)
+
+// WireFormat is a response encoding the client can negotiate instead of
+// JSON, such as MessagePack or CBOR, for workers where JSON decoding of
+// large results dominates CPU. Implementations decode into the SDK's types,
+// which carry json struct tags.
+type WireFormat interface {
+	// ContentType is the media type sent in Accept, e.g. "application/msgpack".
+	ContentType() string
+	// Unmarshal decodes data into v.
+	Unmarshal(data []byte, v any) error
+}
+
+// NewWireFormat returns a WireFormat from a media type and a decoder, to
+// adapt a MessagePack or CBOR library without the SDK depending on it:
+//
+//	refyne.NewWireFormat("application/msgpack", func(data []byte, v any) error {
+//	    dec := msgpack.NewDecoder(bytes.NewReader(data))
+//	    dec.SetCustomStructTag("json")
+//	    return dec.Decode(v)
+//	})
+//
+//	refyne.NewWireFormat("application/cbor", cbor.Unmarshal)
+func NewWireFormat(contentType string, unmarshal func(data []byte, v any) error) WireFormat {
+	return wireFormat{contentType: contentType, unmarshal: unmarshal}
+}
+
+type wireFormat struct {
+	contentType string
+	unmarshal   func(data []byte, v any) error
+}
+
+func (f wireFormat) ContentType() string {
+	return f.contentType
+}
+
+func (f wireFormat) Unmarshal(data []byte, v any) error {
+	return f.unmarshal(data, v)
+}
+
+// WithWireFormat asks the API for responses in format, with JSON as a
+// fallback. Responses are decoded according to their Content-Type, so
+// servers or endpoints that only speak JSON keep working. Raw JSON results,
+// such as those of Jobs.GetResults, are converted back to JSON.
+func WithWireFormat(format WireFormat) ClientOption {
+	return func(c *Client) {
+		c.wireFormat = format
+	}
+}
+
+// acceptHeader returns the Accept header for API requests.
+func (c *Client) acceptHeader() string {
+	if c.wireFormat == nil {
+		return "application/json"
+	}
+	return c.wireFormat.ContentType() + ", application/json;q=0.9"
+}
+
+// decode unmarshals a response body into result according to its
+// Content-Type.
+func (c *Client) decode(resp *response, result any) error {
+	if !c.usesWireFormat(resp.header) {
+		if err := c.decodeJSON(bytes.NewReader(resp.body), result); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+		return nil
+	}
+
+	if raw, ok := result.(*json.RawMessage); ok {
+		var v any
+		if err := c.wireFormat.Unmarshal(resp.body, &v); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("failed to convert response to JSON: %w", err)
+		}
+		*raw = data
+		return nil
+	}
+	if err := c.wireFormat.Unmarshal(resp.body, result); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+// usesWireFormat reports whether a response is encoded in the client's wire
+// format rather than JSON.
+func (c *Client) usesWireFormat(header http.Header) bool {
+	return c.wireFormat != nil && sameMediaType(header.Get("Content-Type"), c.wireFormat.ContentType())
+}
+
+// sameMediaType reports whether a Content-Type header has media type want.
+func sameMediaType(header, want string) bool {
+	mediaType, _, err := mime.ParseMediaType(header)
+	return err == nil && mediaType == want
+}